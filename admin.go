@@ -0,0 +1,114 @@
+package circuitbreaker
+
+import (
+	_ "embed" // for dashboard
+	"encoding/json"
+	"net/http"
+)
+
+//go:embed dashboard.html
+var dashboard []byte
+
+// AdminHandler returns a handler for inspecting and controlling the Breakers in
+// a Registry. It is intended to be mounted under an internal mux, for example:
+//
+//	mux.Handle("/debug/breakers/", http.StripPrefix("/debug/breakers", AdminHandler(reg)))
+//
+// The handler serves:
+//
+//	GET  /                         HTML dashboard
+//	GET  /breakers                 status of all breakers
+//	GET  /breakers/{name}          status of a breaker
+//	GET  /breakers/{name}/history  recent transitions of a breaker
+//	POST /breakers/{name}/{action} trip, reset, force-open, or force-close a breaker
+//	GET  /events                   Server-Sent Events, see EventsHandler
+func AdminHandler(reg *Registry) http.Handler {
+	a := &admin{
+		registry: reg,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", a.dashboard)
+	mux.HandleFunc("GET /breakers", a.list)
+	mux.HandleFunc("GET /breakers/{name}", a.status)
+	mux.HandleFunc("GET /breakers/{name}/history", a.history)
+	mux.Handle("GET /events", EventsHandler(reg))
+	mux.HandleFunc("POST /breakers/{name}/{action}", a.action)
+
+	return mux
+}
+
+type admin struct {
+	registry *Registry
+}
+
+func (a *admin) list(w http.ResponseWriter, r *http.Request) {
+	breakers := a.registry.Breakers()
+
+	statuses := make([]Status, 0, len(breakers))
+	for _, b := range breakers {
+		statuses = append(statuses, b.Status())
+	}
+
+	writeJSON(w, http.StatusOK, statuses)
+}
+
+func (a *admin) status(w http.ResponseWriter, r *http.Request) {
+	b, ok := a.registry.Get(r.PathValue("name"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "circuit breaker not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, b.Status())
+}
+
+func (a *admin) history(w http.ResponseWriter, r *http.Request) {
+	b, ok := a.registry.Get(r.PathValue("name"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "circuit breaker not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, b.History())
+}
+
+func (a *admin) dashboard(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(dashboard)
+}
+
+func (a *admin) action(w http.ResponseWriter, r *http.Request) {
+	b, ok := a.registry.Get(r.PathValue("name"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "circuit breaker not found")
+		return
+	}
+
+	switch r.PathValue("action") {
+	case "trip":
+		b.Trip()
+	case "reset":
+		b.Reset()
+	case "force-open":
+		b.ForceOpen()
+	case "force-close":
+		b.ForceClose()
+	default:
+		writeError(w, http.StatusNotFound, "unknown action")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, b.Status())
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, msg string) {
+	writeJSON(w, code, map[string]string{"error": msg})
+}
@@ -0,0 +1,93 @@
+package circuitbreaker
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminHandler(t *testing.T) {
+	reg := NewRegistry()
+
+	b, err := New(WithName("test"))
+	require.NoError(t, err)
+	require.NoError(t, reg.Register(b))
+
+	h := AdminHandler(reg)
+
+	do := func(method string, path string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+
+		var body map[string]interface{}
+		if w.Code == http.StatusOK && path != "/breakers" {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		}
+
+		return w, body
+	}
+
+	w, _ := do(http.MethodGet, "/breakers")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var list []map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list, 1)
+	require.Equal(t, "test", list[0]["name"])
+
+	w, body := do(http.MethodGet, "/breakers/test")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "closed", body["state"])
+
+	w, _ = do(http.MethodGet, "/breakers/missing")
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	w, body = do(http.MethodPost, "/breakers/test/force-open")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "open", body["state"])
+	require.Equal(t, true, body["forced"])
+	require.Equal(t, StateOpen, b.State())
+
+	w, body = do(http.MethodPost, "/breakers/test/reset")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "closed", body["state"])
+
+	w, _ = do(http.MethodPost, "/breakers/test/explode")
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	w, _ = do(http.MethodGet, "/breakers/test/trip")
+	require.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
+
+func TestAdminDashboard(t *testing.T) {
+	reg := NewRegistry()
+
+	b, err := New(WithName("test"))
+	require.NoError(t, err)
+	require.NoError(t, reg.Register(b))
+
+	b.Trip()
+
+	h := AdminHandler(reg)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Header().Get("Content-Type"), "text/html")
+	require.Contains(t, w.Body.String(), "circuit breakers")
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/breakers/test/history", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var history []map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &history))
+	require.Len(t, history, 1)
+	require.Equal(t, "closed", history[0]["from"])
+	require.Equal(t, "open", history[0]["to"])
+	require.Equal(t, "trip", history[0]["reason"])
+	require.Equal(t, true, history[0]["manual"])
+}
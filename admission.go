@@ -0,0 +1,29 @@
+package circuitbreaker
+
+// Admission describes the state of a Breaker when it allowed a request, so callers can adjust the request,
+// such as by skipping optional work while the Breaker is half-open, without a separate call to State.
+type Admission struct {
+	// State is the state of the Breaker.
+	State State
+	// Generation is the number of state transitions of the Breaker. Requests allowed with the same
+	// Generation were allowed without a transition between them.
+	Generation uint64
+	// Degraded is true if State is StateDegraded.
+	Degraded bool
+	// HalfOpenRemaining is the number of further requests the Breaker allows while half-open.
+	HalfOpenRemaining uint64
+}
+
+// AllowAdmission is like AllowOutcome, but also returns the Admission of the request.
+func (b *Breaker) AllowAdmission() (Admission, func(Outcome), error) {
+	var a Admission
+
+	start, inflight, err := b.admit(&a)
+	if err != nil {
+		return Admission{}, nil, err
+	}
+
+	return a, func(o Outcome) {
+		b.record(start, inflight, o)
+	}, nil
+}
@@ -0,0 +1,77 @@
+package circuitbreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllowAdmission(t *testing.T) {
+	c := &testClock{
+		now: time.Now(),
+	}
+
+	readyToTrip := func(c Counts) bool {
+		return true
+	}
+
+	b, err := New(WithClock(c), WithReadyToTrip(readyToTrip), WithTimeout(time.Second), WithMaxRequests(3))
+	require.NoError(t, err)
+
+	a, record, err := b.AllowAdmission()
+	require.NoError(t, err)
+	require.Equal(t, Admission{State: StateClosed}, a)
+
+	record(OutcomeFailure)
+	require.Equal(t, StateOpen, b.State())
+
+	_, _, err = b.AllowAdmission()
+	require.ErrorIs(t, err, ErrOpenState)
+
+	c.advance(time.Minute)
+
+	for _, remaining := range []uint64{2, 1, 0} {
+		a, _, err = b.AllowAdmission()
+		require.NoError(t, err)
+		require.Equal(t, StateHalfOpen, a.State)
+		require.Equal(t, uint64(2), a.Generation)
+		require.Equal(t, remaining, a.HalfOpenRemaining)
+	}
+
+	_, _, err = b.AllowAdmission()
+	require.ErrorIs(t, err, ErrTooManyRequests)
+
+	b.Reset()
+
+	token, err := b.AllowToken()
+	require.NoError(t, err)
+	require.Equal(t, Admission{State: StateClosed, Generation: 3}, token.Admission())
+	token.Success()
+}
+
+func TestAllowAdmissionDegraded(t *testing.T) {
+	b, err := New(
+		WithWindow(time.Minute),
+		WithReadyToTrip(TripOnFailureRate(0.5, 4)),
+		WithDegraded(TripOnFailureRate(0.2, 4)),
+	)
+	require.NoError(t, err)
+
+	for i := 0; i < 4; i++ {
+		_, record, err := b.AllowAdmission()
+		require.NoError(t, err)
+		record(OutcomeSuccess)
+	}
+
+	_, record, err := b.AllowAdmission()
+	require.NoError(t, err)
+	record(OutcomeFailure)
+
+	a, record, err := b.AllowAdmission()
+	require.NoError(t, err)
+	require.True(t, a.Degraded)
+	require.Equal(t, StateDegraded, a.State)
+	require.Equal(t, uint64(1), a.Generation)
+	record(OutcomeSuccess)
+}
@@ -0,0 +1,89 @@
+package circuitbreaker
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// AnomalyDetector trips a Breaker when the success rate in its window falls more than a number of standard
+// deviations below a long-run baseline, rather than below a fixed threshold. The baseline and the variance
+// of the success rate are exponentially weighted moving averages of the rates seen by ReadyToTrip.
+// The standard deviation is at least that of a binomial distribution with the baseline rate and the
+// number of outcomes in the window, so low volumes need larger drops to trip.
+//
+// Add it to a Breaker with WithDetector. An AnomalyDetector must only be used by one Breaker.
+// ReadyToTrip may also be used with WithReadyToTrip or WithTripCondition, but then measures time with the time package.
+type AnomalyDetector struct {
+	clock       detectorClock
+	first       time.Time
+	last        time.Time
+	deviations  float64
+	minRequests uint64
+	halfLife    time.Duration
+	baseline    float64
+	variance    float64
+	lock        sync.Mutex
+}
+
+// NewAnomalyDetector creates an AnomalyDetector that trips when the success rate is more than deviations
+// standard deviations below the baseline and the window holds at least minRequests outcomes.
+// Samples lose half their weight in the baseline after halfLife, and the AnomalyDetector does not trip
+// until it has learned a baseline for at least halfLife.
+func NewAnomalyDetector(deviations float64, minRequests uint64, halfLife time.Duration) *AnomalyDetector {
+	return &AnomalyDetector{
+		deviations:  deviations,
+		minRequests: minRequests,
+		halfLife:    halfLife,
+	}
+}
+
+func (d *AnomalyDetector) setClock(clock Clock) {
+	d.clock.set(clock)
+}
+
+// ReadyToTrip is a ReadyToTrip that reports whether the success rate in counts is anomalous,
+// and otherwise adds it to the baseline.
+func (d *AnomalyDetector) ReadyToTrip(counts Counts) bool {
+	n := counts.TotalSuccesses + counts.TotalFailures
+	if n == 0 || n < d.minRequests {
+		return false
+	}
+
+	rate := float64(counts.TotalSuccesses) / float64(n)
+	now := d.clock.Now()
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if d.first.IsZero() {
+		d.first, d.last = now, now
+		d.baseline = rate
+
+		return false
+	}
+
+	if now.Sub(d.first) >= d.halfLife && d.baseline-rate > d.deviations*d.stddev(n) {
+		// anomalous rates are left out of the baseline
+		return true
+	}
+
+	// the weight of a sample grows with the time since the last one, as rates are only seen on failures
+	alpha := 1 - math.Exp2(-float64(now.Sub(d.last))/float64(d.halfLife))
+	diff := rate - d.baseline
+
+	d.baseline += alpha * diff
+	d.variance = (1 - alpha) * (d.variance + alpha*diff*diff)
+	d.last = now
+
+	return false
+}
+
+// must be called with lock
+func (d *AnomalyDetector) stddev(n uint64) float64 {
+	// a baseline of no failures still allows for one failure in the window
+	failureRate := math.Max(1-d.baseline, 1/float64(n))
+	binomial := d.baseline * failureRate / float64(n)
+
+	return math.Sqrt(math.Max(d.variance, binomial))
+}
@@ -0,0 +1,88 @@
+package circuitbreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnomalyDetector(t *testing.T) {
+	c := &testClock{
+		now: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	d := NewAnomalyDetector(3, 20, time.Minute)
+	d.setClock(c)
+
+	counts := func(successes uint64, failures uint64) Counts {
+		return Counts{Requests: successes + failures, TotalSuccesses: successes, TotalFailures: failures}
+	}
+
+	// a noisy baseline of around 90% successes
+	for i := 0; i < 30; i++ {
+		c.advance(10 * time.Second)
+		require.False(t, d.ReadyToTrip(counts(uint64(85+i%3*5), uint64(15-i%3*5))))
+	}
+
+	// too few requests
+	require.False(t, d.ReadyToTrip(counts(5, 10)))
+
+	// within the noise
+	require.False(t, d.ReadyToTrip(counts(84, 16)))
+
+	require.True(t, d.ReadyToTrip(counts(50, 50)))
+}
+
+func TestAnomalyDetectorLearning(t *testing.T) {
+	c := &testClock{
+		now: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	d := NewAnomalyDetector(3, 1, time.Minute)
+	d.setClock(c)
+
+	require.False(t, d.ReadyToTrip(Counts{Requests: 100, TotalSuccesses: 100}))
+
+	// the baseline is still being learned
+	c.advance(time.Second)
+	require.False(t, d.ReadyToTrip(Counts{Requests: 100, TotalFailures: 100}))
+}
+
+func TestAnomalyDetectorBreaker(t *testing.T) {
+	c := &testClock{
+		now: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	d := NewAnomalyDetector(3, 1, time.Minute)
+
+	// the detector measures time with the Clock of the Breaker
+	b, err := New(WithClock(c), WithWindow(time.Minute), WithReadyToTrip(func(Counts) bool { return false }),
+		WithDetector("anomaly", d))
+	require.NoError(t, err)
+
+	require.False(t, d.ReadyToTrip(Counts{Requests: 100, TotalSuccesses: 100}))
+
+	c.advance(time.Minute)
+
+	for i := 0; i < 99; i++ {
+		done, err := b.Allow()
+		require.NoError(t, err)
+		done(true)
+	}
+
+	done, err := b.Allow()
+	require.NoError(t, err)
+	done(false)
+	require.Equal(t, StateClosed, b.State())
+
+	// a few more failures are an anomaly against a baseline of no failures
+	for i := 0; i < 3; i++ {
+		done, err := b.Allow()
+		require.NoError(t, err)
+		done(false)
+	}
+
+	require.Equal(t, StateOpen, b.State())
+	require.Equal(t, "anomaly", b.History()[0].Condition)
+}
@@ -0,0 +1,118 @@
+package circuitbreaker
+
+import (
+	"time"
+
+	"github.com/bakins/circuitbreaker/internal/ring"
+)
+
+const (
+	// DefaultApdexThreshold is the default threshold set by WithApdexThreshold.
+	DefaultApdexThreshold = 500 * time.Millisecond
+
+	// apdexMinimumRequests is the number of requests in the window before the Apdex score can trip a Breaker.
+	apdexMinimumRequests = 20
+)
+
+const (
+	apdexRequests = iota
+	apdexSatisfied
+	apdexTolerating
+	apdexCounters
+)
+
+// WithApdexTrip opens a closed Breaker when its Apdex score over the window drops below target, once the window
+// holds at least 20 requests. Requests that succeed within the threshold set by WithApdexThreshold are satisfied,
+// those that succeed within four times the threshold are tolerating, and slower or failed requests are frustrated.
+// The score is the satisfied requests plus half the tolerating requests, divided by all requests.
+// Ignored requests are not scored. The condition is recorded in the Transition as "apdex".
+// There is no default. Must be between 0 and 1.
+func WithApdexTrip(target float64) Option {
+	return func(o *Options) {
+		o.apdexTarget = target
+	}
+}
+
+// WithApdexThreshold sets the latency within which a request is satisfied for WithApdexTrip.
+// Default is DefaultApdexThreshold.
+func WithApdexThreshold(threshold time.Duration) Option {
+	return func(o *Options) {
+		o.apdexThreshold = threshold
+	}
+}
+
+// apdex counts requests in each Apdex band over the window of a Breaker.
+type apdex struct {
+	counts    *ring.Ring
+	threshold time.Duration
+	target    float64
+}
+
+func newApdex(numBuckets int, shards int, threshold time.Duration, target float64) *apdex {
+	return &apdex{
+		counts:    ring.New(numBuckets, apdexCounters, shards, time.Second),
+		threshold: threshold,
+		target:    target,
+	}
+}
+
+func (a *apdex) add(start time.Time, latency time.Duration, o Outcome) {
+	if o == OutcomeIgnored {
+		return
+	}
+
+	a.counts.Add(start, apdexRequests, 1)
+
+	switch {
+	case o != OutcomeSuccess:
+	case latency <= a.threshold:
+		a.counts.Add(start, apdexSatisfied, 1)
+	case latency <= 4*a.threshold:
+		a.counts.Add(start, apdexTolerating, 1)
+	}
+}
+
+// score returns the Apdex score over the window ending at now and the number of requests it is for.
+func (a *apdex) score(now time.Time) (float64, uint64) {
+	var sums [apdexCounters]uint64
+	a.counts.Sums(now, sums[:])
+
+	if sums[apdexRequests] == 0 {
+		return 1, 0
+	}
+
+	return (float64(sums[apdexSatisfied]) + float64(sums[apdexTolerating])/2) / float64(sums[apdexRequests]), sums[apdexRequests]
+}
+
+// Apdex returns the Apdex score over the window, from 0 when every request was frustrated to 1 when every
+// request was satisfied. It is 1 when there are no requests or WithApdexTrip is not used.
+func (b *Breaker) Apdex() float64 {
+	if b.apdex == nil {
+		return 1
+	}
+
+	score, _ := b.apdex.score(b.options.clock.Now())
+
+	return score
+}
+
+// checkApdex opens a closed Breaker if its Apdex score has dropped below the target.
+func (b *Breaker) checkApdex() {
+	if !closed(b.loadState()) {
+		return
+	}
+
+	if score, n := b.apdex.score(b.options.clock.Now()); n < apdexMinimumRequests || score >= b.apdex.target {
+		return
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	state, _ := b.lockedState()
+	if b.forced || !closed(state) || b.monotonic()-b.closedAt < b.options.warmup {
+		return
+	}
+
+	b.switchState(state, StateOpen, ReasonReadyToTrip, "apdex")
+}
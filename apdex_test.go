@@ -0,0 +1,67 @@
+package circuitbreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestApdexTrip(t *testing.T) {
+	c := &testClock{
+		now: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	b, err := New(
+		WithClock(c),
+		WithWindow(time.Minute),
+		WithApdexTrip(0.7),
+		WithApdexThreshold(100*time.Millisecond),
+		WithReadyToTrip(func(Counts) bool { return false }),
+	)
+	require.NoError(t, err)
+
+	call := func(latency time.Duration, success bool) {
+		done, err := b.Allow()
+		require.NoError(t, err)
+		c.advance(latency)
+		done(success)
+	}
+
+	require.Equal(t, 1.0, b.Apdex())
+
+	for range 10 {
+		call(50*time.Millisecond, true)
+	}
+
+	for range 4 {
+		call(200*time.Millisecond, true)
+	}
+
+	require.InDelta(t, 12.0/14, b.Apdex(), 1e-9)
+
+	// slow successes trip the breaker, once there are enough requests
+	for range 5 {
+		call(time.Second, true)
+	}
+
+	require.Equal(t, StateClosed, b.State())
+	require.Less(t, b.Apdex(), 0.7)
+
+	call(time.Second, true)
+	require.Equal(t, StateOpen, b.State())
+	require.Equal(t, "apdex", b.History()[0].Condition)
+
+	// the counts are reset when the breaker closes
+	c.advance(time.Second)
+	call(0, true)
+	require.Equal(t, StateClosed, b.State())
+	require.Equal(t, 1.0, b.Apdex())
+
+	_, err = New(WithApdexTrip(1.5))
+	require.Error(t, err)
+
+	b, err = New(WithApdexTrip(0.9))
+	require.NoError(t, err)
+	require.Equal(t, DefaultApdexThreshold, b.Options().ApdexThreshold)
+}
@@ -0,0 +1,94 @@
+// Package awsbreaker provides AWS SDK for Go v2 middleware that runs operations through circuit breakers,
+// so that a throttled or failing service, such as S3 during a SlowDown storm, fails fast.
+//
+// Add the middleware to a client using its APIOptions:
+//
+//	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+//		o.APIOptions = append(o.APIOptions, awsbreaker.WithBreakers(group))
+//	})
+//
+// SQSPoller pauses polling SQS queues and SNSPublisher spools SNS publishes while their breakers are open.
+package awsbreaker
+
+import (
+	"context"
+
+	"github.com/aws/smithy-go/middleware"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+type options struct {
+	classifier Classifier
+}
+
+// Option sets awsbreaker options.
+type Option func(*options)
+
+// WithClassifier sets the classifier used to record the outcome of operations.
+// Default is DefaultClassifier.
+func WithClassifier(c Classifier) Option {
+	return func(o *options) {
+		o.classifier = c
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		classifier: DefaultClassifier,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// WithBreakers returns an API option that runs each operation through the breaker from g keyed by
+// the service and operation, such as "S3/GetObject", so that one failing operation does not open the
+// breaker for the others. An operation is run through the breaker once, including its retries.
+func WithBreakers(g *circuitbreaker.Group[string], opts ...Option) func(*middleware.Stack) error {
+	return add(func(ctx context.Context) *circuitbreaker.Breaker {
+		return g.Get(middleware.GetServiceID(ctx) + "/" + middleware.GetOperationName(ctx))
+	}, opts)
+}
+
+// WithBreaker returns an API option that runs every operation through b.
+func WithBreaker(b *circuitbreaker.Breaker, opts ...Option) func(*middleware.Stack) error {
+	return add(func(context.Context) *circuitbreaker.Breaker {
+		return b
+	}, opts)
+}
+
+func add(breaker func(ctx context.Context) *circuitbreaker.Breaker, opts []Option) func(*middleware.Stack) error {
+	m := &breakerMiddleware{
+		breaker: breaker,
+		options: newOptions(opts),
+	}
+
+	return func(stack *middleware.Stack) error {
+		return stack.Initialize.Add(m, middleware.After)
+	}
+}
+
+type breakerMiddleware struct {
+	breaker func(ctx context.Context) *circuitbreaker.Breaker
+	options options
+}
+
+func (m *breakerMiddleware) ID() string {
+	return "CircuitBreaker"
+}
+
+func (m *breakerMiddleware) HandleInitialize(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+	done, err := m.breaker(ctx).AllowOutcome()
+	if err != nil {
+		return middleware.InitializeOutput{}, middleware.Metadata{}, err
+	}
+
+	out, metadata, err := next.HandleInitialize(ctx, in)
+	done(m.options.classifier(err))
+
+	return out, metadata, err
+}
@@ -0,0 +1,71 @@
+package awsbreaker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+func newClient(t *testing.T, handler http.HandlerFunc, option func(*s3.Options)) *s3.Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return s3.New(s3.Options{
+		BaseEndpoint:     aws.String(server.URL),
+		Credentials:      aws.AnonymousCredentials{},
+		Region:           "us-east-1",
+		UsePathStyle:     true,
+		RetryMaxAttempts: 1,
+	}, option)
+}
+
+func TestWithBreakers(t *testing.T) {
+	g, err := circuitbreaker.NewGroup[string](
+		circuitbreaker.WithWindow(time.Minute),
+		circuitbreaker.WithReadyToTrip(circuitbreaker.TripOnConsecutiveFailures(1)),
+	)
+	require.NoError(t, err)
+
+	var calls int
+
+	client := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`<Error><Code>SlowDown</Code><Message>Please reduce your request rate.</Message></Error>`))
+	}, func(o *s3.Options) {
+		o.APIOptions = append(o.APIOptions, WithBreakers(g))
+	})
+
+	input := &s3.GetObjectInput{Bucket: aws.String("bucket"), Key: aws.String("key")}
+
+	_, err = client.GetObject(context.Background(), input)
+	require.Error(t, err)
+	require.Equal(t, circuitbreaker.StateOpen, g.Get("S3/GetObject").State())
+
+	_, err = client.GetObject(context.Background(), input)
+	require.ErrorIs(t, err, circuitbreaker.ErrOpenState)
+	require.Equal(t, 1, calls)
+
+	// other operations use their own breaker and 404s are ignored
+	_, err = client.HeadObject(context.Background(), &s3.HeadObjectInput{Bucket: aws.String("bucket"), Key: aws.String("key")})
+	require.Error(t, err)
+	require.Equal(t, 2, calls)
+
+	b := g.Get("S3/HeadObject")
+	require.Equal(t, circuitbreaker.StateClosed, b.State())
+	require.Equal(t, uint64(0), b.Status().Counts.TotalFailures)
+}
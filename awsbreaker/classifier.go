@@ -0,0 +1,62 @@
+package awsbreaker
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+// Classifier returns the outcome of an operation that returned err.
+type Classifier func(err error) circuitbreaker.Outcome
+
+// throttlingCodes are the error codes AWS services use for throttling.
+var throttlingCodes = map[string]bool{
+	"SlowDown":                               true,
+	"Throttling":                             true,
+	"ThrottlingException":                    true,
+	"ThrottledException":                     true,
+	"RequestThrottled":                       true,
+	"RequestThrottledException":              true,
+	"RequestLimitExceeded":                   true,
+	"TooManyRequestsException":               true,
+	"ProvisionedThroughputExceededException": true,
+	"BandwidthLimitExceeded":                 true,
+}
+
+// DefaultClassifier classifies errors that indicate a throttled or unhealthy service as failures:
+// throttling errors such as SlowDown, 429 and 5xx responses, network errors, and timeouts.
+// 404 responses, such as NoSuchKey, and operations canceled by the caller are ignored.
+// Other errors returned by the service, such as AccessDenied, are successes since the service responded.
+func DefaultClassifier(err error) circuitbreaker.Outcome {
+	if err == nil {
+		return circuitbreaker.OutcomeSuccess
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return circuitbreaker.OutcomeIgnored
+	}
+
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && throttlingCodes[apiErr.ErrorCode()] {
+		return circuitbreaker.OutcomeFailure
+	}
+
+	var respErr *smithyhttp.ResponseError
+	if errors.As(err, &respErr) {
+		switch code := respErr.HTTPStatusCode(); {
+		case code == 404:
+			return circuitbreaker.OutcomeIgnored
+		case code == 429 || code >= 500:
+			return circuitbreaker.OutcomeFailure
+		case code >= 400:
+			return circuitbreaker.OutcomeSuccess
+		}
+	}
+
+	// network errors, timeouts, and other errors without a response
+	return circuitbreaker.OutcomeFailure
+}
@@ -0,0 +1,45 @@
+package awsbreaker
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+func responseError(code int, err error) error {
+	return &smithyhttp.ResponseError{
+		Response: &smithyhttp.Response{Response: &http.Response{StatusCode: code}},
+		Err:      err,
+	}
+}
+
+func TestDefaultClassifier(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want circuitbreaker.Outcome
+	}{
+		{"nil", nil, circuitbreaker.OutcomeSuccess},
+		{"canceled", context.Canceled, circuitbreaker.OutcomeIgnored},
+		{"deadline", context.DeadlineExceeded, circuitbreaker.OutcomeFailure},
+		{"slow down", responseError(503, &smithy.GenericAPIError{Code: "SlowDown"}), circuitbreaker.OutcomeFailure},
+		{"throttling", responseError(400, &smithy.GenericAPIError{Code: "ThrottlingException"}), circuitbreaker.OutcomeFailure},
+		{"internal error", responseError(500, &smithy.GenericAPIError{Code: "InternalError"}), circuitbreaker.OutcomeFailure},
+		{"not found", responseError(404, &smithy.GenericAPIError{Code: "NoSuchKey"}), circuitbreaker.OutcomeIgnored},
+		{"access denied", responseError(403, &smithy.GenericAPIError{Code: "AccessDenied"}), circuitbreaker.OutcomeSuccess},
+		{"network", errors.New("connection refused"), circuitbreaker.OutcomeFailure},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, DefaultClassifier(tt.err))
+		})
+	}
+}
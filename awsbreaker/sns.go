@@ -0,0 +1,115 @@
+package awsbreaker
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+// SNSPublisherAPI is the subset of *sns.Client used by an SNSPublisher.
+type SNSPublisherAPI interface {
+	Publish(ctx context.Context, in *sns.PublishInput, optFns ...func(*sns.Options)) (*sns.PublishOutput, error)
+}
+
+var _ SNSPublisherAPI = &sns.Client{}
+
+// SNSPublisher publishes messages to SNS topics through a breaker per topic. Messages that are
+// rejected or fail are spooled using a circuitbreaker.Spooler per topic and published once the
+// topic's breaker closes. Call Close to stop watching the breakers.
+type SNSPublisher struct {
+	client   SNSPublisherAPI
+	group    *circuitbreaker.Group[string]
+	options  []circuitbreaker.SpoolOption
+	lock     sync.Mutex
+	spoolers map[string]*circuitbreaker.Spooler[*sns.PublishInput]
+}
+
+// NewSNSPublisher creates an SNSPublisher that uses the breaker from g keyed by topic ARN,
+// or target ARN for publishes to an endpoint. Each topic's spool is created using options.
+func NewSNSPublisher(client SNSPublisherAPI, g *circuitbreaker.Group[string], options ...circuitbreaker.SpoolOption) *SNSPublisher {
+	return &SNSPublisher{
+		client:   client,
+		group:    g,
+		options:  options,
+		spoolers: make(map[string]*circuitbreaker.Spooler[*sns.PublishInput]),
+	}
+}
+
+// Publish publishes in, or spools it if the topic's breaker rejects it or the publish fails.
+// Publish returns circuitbreaker.ErrSpoolFull if in is dropped, and nil otherwise.
+func (p *SNSPublisher) Publish(ctx context.Context, in *sns.PublishInput) error {
+	return p.spooler(topic(in)).Send(ctx, in)
+}
+
+// Len returns the number of spooled messages for a topic.
+func (p *SNSPublisher) Len(topicARN string) int {
+	p.lock.Lock()
+	s, ok := p.spoolers[topicARN]
+	p.lock.Unlock()
+
+	if !ok {
+		return 0
+	}
+
+	return s.Len()
+}
+
+// Flush publishes the spooled messages of every topic whose breaker allows it.
+func (p *SNSPublisher) Flush(ctx context.Context) error {
+	p.lock.Lock()
+	spoolers := make([]*circuitbreaker.Spooler[*sns.PublishInput], 0, len(p.spoolers))
+	for _, s := range p.spoolers {
+		spoolers = append(spoolers, s)
+	}
+	p.lock.Unlock()
+
+	var errs []error
+
+	for _, s := range spoolers {
+		if err := s.Flush(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Close stops publishing spooled messages when breakers close.
+func (p *SNSPublisher) Close() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for _, s := range p.spoolers {
+		s.Close()
+	}
+}
+
+func (p *SNSPublisher) spooler(key string) *circuitbreaker.Spooler[*sns.PublishInput] {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	s, ok := p.spoolers[key]
+	if !ok {
+		s = circuitbreaker.NewSpooler(p.group.Get(key), nil, func(ctx context.Context, in *sns.PublishInput) error {
+			_, err := p.client.Publish(ctx, in)
+			return err
+		}, p.options...)
+
+		p.spoolers[key] = s
+	}
+
+	return s
+}
+
+func topic(in *sns.PublishInput) string {
+	if in.TopicArn != nil {
+		return aws.ToString(in.TopicArn)
+	}
+
+	return aws.ToString(in.TargetArn)
+}
@@ -0,0 +1,80 @@
+package awsbreaker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+type testPublisher struct {
+	lock      sync.Mutex
+	err       error
+	published []string
+}
+
+func (p *testPublisher) Publish(_ context.Context, in *sns.PublishInput, _ ...func(*sns.Options)) (*sns.PublishOutput, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	p.published = append(p.published, aws.ToString(in.Message))
+
+	return &sns.PublishOutput{}, nil
+}
+
+func (p *testPublisher) messages() []string {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return append([]string(nil), p.published...)
+}
+
+func TestSNSPublisher(t *testing.T) {
+	g, err := circuitbreaker.NewGroup[string](
+		circuitbreaker.WithWindow(time.Minute),
+		circuitbreaker.WithReadyToTrip(circuitbreaker.TripOnConsecutiveFailures(1)),
+	)
+	require.NoError(t, err)
+
+	const topicARN = "arn:aws:sns:us-east-1:1:topic"
+
+	client := &testPublisher{}
+	p := NewSNSPublisher(client, g)
+	defer p.Close()
+
+	publish := func(message string) error {
+		return p.Publish(context.Background(), &sns.PublishInput{TopicArn: aws.String(topicARN), Message: aws.String(message)})
+	}
+
+	require.NoError(t, publish("one"))
+
+	client.lock.Lock()
+	client.err = errors.New("unavailable")
+	client.lock.Unlock()
+
+	// the failure trips the breaker, and both messages are spooled
+	require.NoError(t, publish("two"))
+	require.NoError(t, publish("three"))
+	require.Equal(t, 2, p.Len(topicARN))
+	require.Equal(t, circuitbreaker.StateOpen, g.Get(topicARN).State())
+
+	client.lock.Lock()
+	client.err = nil
+	client.lock.Unlock()
+
+	g.Get(topicARN).Reset()
+
+	require.Eventually(t, func() bool { return p.Len(topicARN) == 0 }, time.Second, time.Millisecond)
+	require.Equal(t, []string{"one", "two", "three"}, client.messages())
+}
@@ -0,0 +1,88 @@
+package awsbreaker
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+// SQSReceiver is the subset of *sqs.Client used by an SQSPoller.
+type SQSReceiver interface {
+	ReceiveMessage(ctx context.Context, in *sqs.ReceiveMessageInput, optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error)
+}
+
+var _ SQSReceiver = &sqs.Client{}
+
+// SQSPoller receives messages from SQS queues through a breaker per queue, and pauses polling
+// a queue while its breaker is open rather than making receive calls that are bound to fail.
+// Record the outcome of processing messages with the queue's Breaker to pause polling
+// while the processor is failing, too.
+type SQSPoller struct {
+	client  SQSReceiver
+	group   *circuitbreaker.Group[string]
+	options options
+}
+
+// NewSQSPoller creates an SQSPoller that uses the breaker from g keyed by queue URL.
+func NewSQSPoller(client SQSReceiver, g *circuitbreaker.Group[string], opts ...Option) *SQSPoller {
+	return &SQSPoller{
+		client:  client,
+		group:   g,
+		options: newOptions(opts),
+	}
+}
+
+// Breaker returns the breaker for a queue.
+func (p *SQSPoller) Breaker(queueURL string) *circuitbreaker.Breaker {
+	return p.group.Get(queueURL)
+}
+
+// ReceiveMessage waits until the queue's breaker is not open, or ctx is done, then receives messages.
+func (p *SQSPoller) ReceiveMessage(ctx context.Context, in *sqs.ReceiveMessageInput, optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
+	b := p.Breaker(aws.ToString(in.QueueUrl))
+
+	if err := waitNotOpen(ctx, b); err != nil {
+		return nil, err
+	}
+
+	done, err := b.AllowOutcome()
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := p.client.ReceiveMessage(ctx, in, optFns...)
+	done(p.options.classifier(err))
+
+	return out, err
+}
+
+// waitNotOpen waits until b is not open or ctx is done.
+func waitNotOpen(ctx context.Context, b *circuitbreaker.Breaker) error {
+	for {
+		changed := make(chan struct{}, 1)
+
+		unsubscribe := b.Subscribe(func(circuitbreaker.Transition) {
+			select {
+			case changed <- struct{}{}:
+			default:
+			}
+		})
+
+		// checked after subscribing so a transition is not missed
+		if b.State() != circuitbreaker.StateOpen {
+			unsubscribe()
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			unsubscribe()
+			return ctx.Err()
+		case <-changed:
+			unsubscribe()
+		}
+	}
+}
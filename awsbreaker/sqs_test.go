@@ -0,0 +1,69 @@
+package awsbreaker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bakins/circuitbreaker"
+	"github.com/bakins/circuitbreaker/breakertest"
+)
+
+type testReceiver struct {
+	calls int
+}
+
+func (r *testReceiver) ReceiveMessage(context.Context, *sqs.ReceiveMessageInput, ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
+	r.calls++
+	return &sqs.ReceiveMessageOutput{}, nil
+}
+
+func TestSQSPoller(t *testing.T) {
+	clock := breakertest.NewClock(time.Now())
+
+	g, err := circuitbreaker.NewGroup[string](circuitbreaker.WithClock(clock), circuitbreaker.WithTimeout(time.Second))
+	require.NoError(t, err)
+
+	client := &testReceiver{}
+	p := NewSQSPoller(client, g)
+
+	in := &sqs.ReceiveMessageInput{QueueUrl: aws.String("https://sqs.us-east-1.amazonaws.com/1/queue")}
+
+	_, err = p.ReceiveMessage(context.Background(), in)
+	require.NoError(t, err)
+
+	b := p.Breaker(aws.ToString(in.QueueUrl))
+	b.Trip()
+
+	// polling resumes once the breaker moves to half-open
+	received := make(chan error)
+
+	go func() {
+		_, err := p.ReceiveMessage(context.Background(), in)
+		received <- err
+	}()
+
+	select {
+	case <-received:
+		t.Fatal("received while the breaker is open")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	clock.Advance(time.Second)
+
+	require.NoError(t, <-received)
+	require.Equal(t, 2, client.calls)
+
+	b.ForceOpen()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = p.ReceiveMessage(ctx, in)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Equal(t, 2, client.calls)
+}
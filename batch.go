@@ -0,0 +1,96 @@
+package circuitbreaker
+
+import (
+	"math/rand/v2"
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// WithSuccessBatch buffers the successes of a closed Breaker and adds them to the counts in batches,
+// once a buffer holds size successes or interval has passed since it was last added. This reduces the
+// overhead of recording successes at very hot call sites. Failures are recorded immediately and add
+// any buffered successes first, as do Status and trip conditions, so counts are only approximate in
+// which second of the window a success is counted.
+// There is no default. Size and interval must both be positive.
+func WithSuccessBatch(size int, interval time.Duration) Option {
+	return func(o *Options) {
+		o.batchSize = size
+		o.batchInterval = interval
+	}
+}
+
+// successBatch buffers successes in stripes, one per processor, so concurrent successes rarely share a cache line.
+type successBatch struct {
+	stripes  []batchStripe
+	size     uint64
+	interval int64
+}
+
+type batchStripe struct {
+	pending atomic.Uint64
+	// added is when the stripe was last added to the counts, in Unix nanoseconds.
+	added atomic.Int64
+	_     [48]byte
+}
+
+func newSuccessBatch(size int, interval time.Duration) *successBatch {
+	return &successBatch{
+		stripes:  make([]batchStripe, runtime.GOMAXPROCS(0)),
+		size:     uint64(size),
+		interval: int64(interval),
+	}
+}
+
+// batchSuccess buffers a success of a request that started at start.
+func (b *Breaker) batchSuccess(start time.Time) {
+	s := &b.batch.stripes[rand.IntN(len(b.batch.stripes))]
+
+	// a success ends a run of failures, which must be seen immediately
+	if atomic.LoadUint64(&b.consecutiveFailures) != 0 {
+		atomic.StoreUint64(&b.consecutiveFailures, 0)
+	}
+
+	now := start.UnixNano()
+
+	if s.pending.Add(1) >= b.batch.size || now-s.added.Load() >= b.batch.interval {
+		b.flushStripe(s, start)
+	}
+}
+
+// flushSuccesses adds all buffered successes to the counts.
+func (b *Breaker) flushSuccesses() {
+	if b.batch == nil {
+		return
+	}
+
+	now := b.options.clock.Now()
+
+	for i := range b.batch.stripes {
+		b.flushStripe(&b.batch.stripes[i], now)
+	}
+}
+
+func (b *Breaker) flushStripe(s *batchStripe, now time.Time) {
+	s.added.Store(now.UnixNano())
+
+	n := s.pending.Swap(0)
+	if n == 0 {
+		return
+	}
+
+	b.buckets.Add(now, countSuccesses, n)
+	b.addShared(Counts{TotalSuccesses: n})
+	atomic.AddUint64(&b.consecutiveSuccesses, n)
+}
+
+// discardSuccesses drops buffered successes.
+func (b *Breaker) discardSuccesses() {
+	if b.batch == nil {
+		return
+	}
+
+	for i := range b.batch.stripes {
+		b.batch.stripes[i].pending.Store(0)
+	}
+}
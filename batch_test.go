@@ -0,0 +1,78 @@
+package circuitbreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuccessBatch(t *testing.T) {
+	c := &testClock{
+		now: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	b, err := New(
+		WithClock(c),
+		WithWindow(time.Minute),
+		WithSuccessBatch(100, time.Hour),
+		WithReadyToTrip(func(c Counts) bool { return c.ConsecutiveFailures >= 2 }),
+	)
+	require.NoError(t, err)
+
+	succeed := func(n int) {
+		for i := 0; i < n; i++ {
+			done, err := b.Allow()
+			require.NoError(t, err)
+			done(true)
+		}
+	}
+
+	fail := func() {
+		done, err := b.Allow()
+		require.NoError(t, err)
+		done(false)
+	}
+
+	succeed(10)
+
+	// buffered successes are added before the counts are read
+	require.Equal(t, Counts{Requests: 10, TotalSuccesses: 10, ConsecutiveSuccesses: 10}, b.Status().Counts)
+
+	// and successes still end a run of failures
+	fail()
+	succeed(1)
+	fail()
+	require.Equal(t, StateClosed, b.State())
+
+	fail()
+	require.Equal(t, StateOpen, b.State())
+	require.Equal(t, Counts{Requests: 14, TotalSuccesses: 11, TotalFailures: 3, ConsecutiveFailures: 2}, b.Status().Counts)
+
+	// successes are not buffered while half-open
+	c.advance(2 * time.Second)
+	succeed(1)
+	require.Equal(t, StateClosed, b.State())
+}
+
+func TestSuccessBatchInvalid(t *testing.T) {
+	_, err := New(WithSuccessBatch(10, 0))
+	require.ErrorIs(t, err, ErrInvalidOption)
+}
+
+func BenchmarkSuccessBatch(b *testing.B) {
+	cb, err := New(WithSuccessBatch(64, 100*time.Millisecond))
+	require.NoError(b, err)
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			token, err := cb.AllowToken()
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			token.Success()
+		}
+	})
+}
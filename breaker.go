@@ -1,22 +1,49 @@
 package circuitbreaker
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"log/slog"
+	"math"
 	"sync"
 	"sync/atomic"
 	"time"
 
-	"github.com/asecurityteam/rolling"
+	"golang.org/x/time/rate"
+
+	"github.com/bakins/circuitbreaker/internal/ring"
 )
 
 var (
 	// ErrTooManyRequests is returned when the Breaker state is StateHalfOpen and the requests count is over the  MaxRequests
 	ErrTooManyRequests = errors.New("too many requests")
-	// ErrOpenState is returned when the Breaker state is StateOpen
+	// ErrOpenState is returned when the Breaker state is StateOpen.
+	// Allow returns an *OpenStateError which matches ErrOpenState using errors.Is.
 	ErrOpenState = errors.New("circuit breaker is open")
 )
 
+// OpenStateError is returned by Allow when the Breaker state is StateOpen.
+// It describes why the Breaker was opened.
+type OpenStateError struct {
+	// Transition is the transition that opened the Breaker.
+	Transition Transition
+}
+
+// Error implements the error interface.
+func (e *OpenStateError) Error() string {
+	if e.Transition.Condition != "" {
+		return fmt.Sprintf("%s: %s (%s)", ErrOpenState, e.Transition.Reason, e.Transition.Condition)
+	}
+
+	return fmt.Sprintf("%s: %s", ErrOpenState, e.Transition.Reason)
+}
+
+// Is reports whether target is ErrOpenState.
+func (e *OpenStateError) Is(target error) bool {
+	return target == ErrOpenState
+}
+
 // State of a Breaker.
 type State int
 
@@ -25,6 +52,8 @@ const (
 	StateClosed State = iota
 	StateHalfOpen
 	StateOpen
+	// StateDegraded is like StateClosed, but the condition set by WithDegraded holds. See WithDegraded.
+	StateDegraded
 )
 
 // String returns a string representation of the Breaker state
@@ -36,6 +65,8 @@ func (s State) String() string {
 		return "half-open"
 	case StateOpen:
 		return "open"
+	case StateDegraded:
+		return "degraded"
 	default:
 		return fmt.Sprintf("unknown state: %d", s)
 	}
@@ -47,13 +78,51 @@ type ReadyToTrip func(Counts) bool
 // OnStateChange is called whenever the state of the Breaker changes.
 type OnStateChange func(from State, to State)
 
+// OnTransition is called whenever the state of the Breaker changes.
+type OnTransition func(Transition)
+
 // Options configure the Breaker.
 type Options struct {
-	readyToTrip   ReadyToTrip
-	onStateChange OnStateChange
-	window        time.Duration
-	timeout       time.Duration
-	maxRequests   uint64
+	readyToTrip      ReadyToTrip
+	onStateChange    OnStateChange
+	onTransition     OnTransition
+	onAbandoned      func(error)
+	conditions       []tripCondition
+	schedule         *schedule
+	errorBudget      *ErrorBudget
+	degraded         ReadyToTrip
+	notifiers        []Notifier
+	sinks            []eventSink
+	labels           map[string]string
+	logger           *slog.Logger
+	rateLimiter      *rate.Limiter
+	concurrencyLimit ConcurrencyLimit
+	chaos            *Chaos
+	store            Store
+	clock            Clock
+	signals          <-chan Signal
+	sharedCounts     SharedCounts
+	name             string
+	window           time.Duration
+	timeout          time.Duration
+	maxRequests      uint64
+	historySize      int
+	callTimeout      time.Duration
+	hedgeDelay       time.Duration
+	storeInterval    time.Duration
+	shards           int
+	warmup           time.Duration
+	critical         bool
+	ignoreDeadlines  bool
+	deadlines        bool
+	latencySampling  int
+	apdexTarget      float64
+	apdexThreshold   time.Duration
+	batchSize        int
+	batchInterval    time.Duration
+	observerQueue    int
+	syncObservers    bool
+	legacyState      bool
 }
 
 // Option sets Breaker options
@@ -69,7 +138,7 @@ func WithMaxRequests(max uint64) Option {
 }
 
 // WithWindow sets the rolling time window for counting successes and failures.
-// Default is 60 seconds. Must be at least one second.
+// Default is one second. Must be a whole number of seconds.
 func WithWindow(window time.Duration) Option {
 	return func(o *Options) {
 		o.window = window
@@ -78,13 +147,65 @@ func WithWindow(window time.Duration) Option {
 
 // WithTimeout sets the s the period of the open state,
 // after which the state of the Breaker becomes half-open.
-// Default is 10 seconds. Must be at least one second.
+// Default is one second. Must be at least one second.
 func WithTimeout(timeout time.Duration) Option {
 	return func(o *Options) {
 		o.timeout = timeout
 	}
 }
 
+// WithWarmup sets a grace period after the Breaker is created and after it closes, during which failures
+// are counted but do not trip the Breaker, so failures while caches and connection pools fill up
+// do not open it. A failure in the half-open state still opens the Breaker.
+// There is no default.
+func WithWarmup(d time.Duration) Option {
+	return func(o *Options) {
+		o.warmup = d
+	}
+}
+
+// WithCritical marks the Breaker as protecting a critical dependency, so that ReadinessHandler reports
+// the instance as not ready while the Breaker is open.
+func WithCritical() Option {
+	return func(o *Options) {
+		o.critical = true
+	}
+}
+
+// WithShards splits the counts of each second of the window into n shards, so concurrent requests on many cores
+// rarely update the same counters. Each shard adds a cache line per second of the window.
+// Default is 1.
+func WithShards(n int) Option {
+	return func(o *Options) {
+		o.shards = n
+	}
+}
+
+// WithLegacyState makes State change an open Breaker whose timeout has expired to half-open,
+// as it did in earlier versions, rather than only reading the state.
+func WithLegacyState() Option {
+	return func(o *Options) {
+		o.legacyState = true
+	}
+}
+
+// WithName sets the name of the Breaker. The name is included in log records.
+// There is no default.
+func WithName(name string) Option {
+	return func(o *Options) {
+		o.name = name
+	}
+}
+
+// WithLogger sets a logger for the Breaker. State transitions and forced overrides
+// are logged at info level, rejected requests at debug level.
+// There is no default.
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *Options) {
+		o.logger = logger
+	}
+}
+
 // Counts holds the numbers of requests and their successes/failures.
 // Counts are kept in rolling window.
 type Counts struct {
@@ -95,6 +216,17 @@ type Counts struct {
 	ConsecutiveFailures  uint64
 }
 
+// LogValue implements slog.LogValuer.
+func (c Counts) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Uint64("requests", c.Requests),
+		slog.Uint64("totalSuccesses", c.TotalSuccesses),
+		slog.Uint64("totalFailures", c.TotalFailures),
+		slog.Uint64("consecutiveSuccesses", c.ConsecutiveSuccesses),
+		slog.Uint64("consecutiveFailures", c.ConsecutiveFailures),
+	)
+}
+
 // DefaultReadyToTrip is the default function called by WithReadyToTrip.
 // It returns true if ConsecutiveFailures is greater than 5
 func DefaultReadyToTrip(counts Counts) bool {
@@ -111,6 +243,7 @@ func WithReadyToTrip(readyToTrip ReadyToTrip) Option {
 }
 
 // WithOnStateChange sets a function that is called whenever the state of the Breaker changes.
+// It is called in the background, see WithObserverQueueSize and WithSynchronousObservers.
 // There is no default.
 func WithOnStateChange(onStateChange OnStateChange) Option {
 	return func(o *Options) {
@@ -118,20 +251,113 @@ func WithOnStateChange(onStateChange OnStateChange) Option {
 	}
 }
 
+// WithOnTransition sets a function that is called whenever the state of the Breaker changes.
+// Unlike OnStateChange, it receives the reason for the transition.
+// There is no default.
+func WithOnTransition(onTransition OnTransition) Option {
+	return func(o *Options) {
+		o.onTransition = onTransition
+	}
+}
+
+// WithTripCondition adds a named condition that is evaluated, along with ReadyToTrip,
+// whenever a request fails in the closed state. If it returns true, the Breaker
+// will be placed into the open state and the name is recorded in the Transition.
+func WithTripCondition(name string, readyToTrip ReadyToTrip) Option {
+	return func(o *Options) {
+		o.conditions = append(o.conditions, tripCondition{name: name, readyToTrip: readyToTrip})
+	}
+}
+
+type tripCondition struct {
+	readyToTrip ReadyToTrip
+	detector    Detector
+	name        string
+}
+
+// Detector is a trip condition that keeps a history of the counts it sees. It is implemented by
+// AnomalyDetector and SpikeDetector.
+type Detector interface {
+	ReadyToTrip(counts Counts) bool
+	setClock(clock Clock)
+}
+
+// WithDetector adds d as a named condition, as WithTripCondition does. The Detector measures time
+// with the Clock of the Breaker, see WithClock.
+func WithDetector(name string, d Detector) Option {
+	return func(o *Options) {
+		o.conditions = append(o.conditions, tripCondition{name: name, readyToTrip: d.ReadyToTrip, detector: d})
+	}
+}
+
+// setClocks sets the Clock of the detectors in conditions and of the ErrorBudget in o.
+func (o *Options) setClocks(conditions []tripCondition) {
+	for _, c := range conditions {
+		if c.detector != nil {
+			c.detector.setClock(o.clock)
+		}
+	}
+
+	if o.errorBudget != nil {
+		o.errorBudget.clock.set(o.clock)
+	}
+}
+
+// counters in each bucket of a Breaker
+const (
+	countRequests = iota
+	countSuccesses
+	countFailures
+	numCounters
+)
+
 // Breaker is a circuit breaker that uses rolling time windows.
 type Breaker struct {
-	lastStateChange      time.Time
-	requests             *timePolicy
-	totalSuccesses       *timePolicy
-	totalFailures        *timePolicy
-	options              Options
-	currentState         State
+	lastStateChange time.Time
+	// changedAt is the monotonic reading at the last state change, which open timeouts are measured from.
+	changedAt time.Duration
+	// closedAt is the monotonic reading when the Breaker was created or last closed, which warmups are measured from.
+	closedAt time.Duration
+	// epoch is the time the Breaker was created, for clocks that are not a MonotonicClock.
+	epoch          time.Time
+	lastOpen       Transition
+	openTimeout    time.Duration
+	history        *history
+	stats          *stats
+	limiter        *concurrencyLimiter
+	chaos          *chaos
+	persistence    *persistence
+	subscribers    map[uint64]OnTransition
+	nextSubscriber uint64
+	// buckets counts requests and their outcomes in the window. Outcomes are counted in the
+	// bucket of the request, so the successes and failures do not exceed the requests.
+	buckets          *ring.Ring
+	halfOpenRequests *window
+	// batch buffers successes if WithSuccessBatch is used.
+	batch *successBatch
+	// latency holds sampled latencies if WithLatencySampling is used.
+	latency *latencySamples
+	// observers delivers calls to observers in the background, or is nil if they are called synchronously.
+	observers *observerQueue
+	// events delivers events to the sinks added by WithEventSink, or is nil if there are none.
+	events *eventBus
+	// apdex counts Apdex bands if WithApdexTrip is used.
+	apdex   *apdex
+	options Options
+	// currentState holds the state in its low byte and the number of transitions above it.
+	// It is written with the lock held, but may be read without it.
+	currentState atomic.Uint64
+	// openUntil is the monotonic reading when an open Breaker becomes half-open, or zero if it is not open.
+	openUntil            atomic.Int64
+	forced               bool
 	consecutiveSuccesses uint64
 	consecutiveFailures  uint64
 	lock                 sync.Mutex
+	// callerDeadlines counts the failures due to the caller's deadline, see WithIgnoreCallerDeadlines.
+	callerDeadlines atomic.Uint64
 }
 
-// New creates a Breaker
+// New creates a Breaker. It returns an error wrapping ErrInvalidOption if an option is invalid.
 func New(options ...Option) (*Breaker, error) {
 	opts := Options{}
 
@@ -139,173 +365,631 @@ func New(options ...Option) (*Breaker, error) {
 		o(&opts)
 	}
 
-	if opts.maxRequests <= 0 {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	if opts.maxRequests == 0 {
 		opts.maxRequests = 1
 	}
 
-	if opts.window < time.Second {
+	if opts.window == 0 {
 		opts.window = time.Second
 	}
 
-	if opts.timeout < time.Second {
+	if opts.timeout == 0 {
 		opts.timeout = time.Second
 	}
 
+	if opts.historySize == 0 {
+		opts.historySize = 10
+	}
+
+	if opts.storeInterval == 0 {
+		opts.storeInterval = 10 * time.Second
+	}
+
+	if opts.clock == nil {
+		opts.clock = realClock{}
+	}
+
+	opts.setClocks(opts.conditions)
+
+	if opts.shards == 0 {
+		opts.shards = 1
+	}
+
+	if opts.observerQueue == 0 {
+		opts.observerQueue = DefaultObserverQueueSize
+	}
+
+	if opts.apdexTarget > 0 && opts.apdexThreshold == 0 {
+		opts.apdexThreshold = DefaultApdexThreshold
+	}
+
 	if opts.readyToTrip == nil {
 		opts.readyToTrip = DefaultReadyToTrip
 	}
 
-	if opts.onStateChange == nil {
-		opts.onStateChange = func(from State, to State) {}
-	}
+	opts.conditions = append([]tripCondition{{name: "readyToTrip", readyToTrip: opts.readyToTrip}}, opts.conditions...)
 
 	// one bucket per second.  Should this be configurable?
 	numBuckets := opts.window / time.Second
 
 	b := &Breaker{
-		options:         opts,
-		requests:        newTimePolicy(rolling.NewWindow(int(numBuckets)), time.Second),
-		totalSuccesses:  newTimePolicy(rolling.NewWindow(int(numBuckets)), time.Second),
-		totalFailures:   newTimePolicy(rolling.NewWindow(int(numBuckets)), time.Second),
-		currentState:    StateClosed,
-		lastStateChange: timeNow(),
+		options:          opts,
+		buckets:          ring.New(int(numBuckets), numCounters, opts.shards, time.Second),
+		halfOpenRequests: newWindow(opts.clock, int(numBuckets), time.Second),
+		lastStateChange:  opts.clock.Now(),
+		epoch:            opts.clock.Now(),
+		history:          newHistory(opts.historySize),
+		stats:            newStats(opts.clock),
+	}
+
+	b.changedAt = b.monotonic()
+	b.closedAt = b.changedAt
+
+	if opts.concurrencyLimit != nil {
+		b.limiter = newConcurrencyLimiter(opts.concurrencyLimit)
+	}
+
+	if opts.batchSize > 0 {
+		b.batch = newSuccessBatch(opts.batchSize, opts.batchInterval)
+	}
+
+	if opts.latencySampling > 0 {
+		b.latency = newLatencySamples(opts.latencySampling)
+	}
+
+	// functions added by Subscribe are observers too, so the queue is created even without observer options.
+	// It only starts a goroutine once a call is queued.
+	if !opts.syncObservers {
+		b.observers = newObserverQueue(opts.observerQueue)
+	}
+
+	if len(opts.sinks) > 0 {
+		b.events = newEventBus(opts.name, opts.labels, opts.sinks, b.observers)
+	}
+
+	if opts.apdexTarget > 0 {
+		b.apdex = newApdex(int(numBuckets), opts.shards, opts.apdexThreshold, opts.apdexTarget)
+	}
+
+	if opts.chaos != nil {
+		b.chaos = &chaos{config: *opts.chaos, start: b.lastStateChange}
+	}
+
+	if opts.store != nil {
+		if err := b.startPersistence(); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.signals != nil {
+		go b.receiveSignals(opts.signals)
 	}
 
 	return b, nil
 }
 
-// State returns the current state .
+// Name returns the name of the Breaker.
+func (b *Breaker) Name() string {
+	return b.options.name
+}
+
+// Clock returns the Clock set by WithClock, so that integrations measure time as the Breaker does.
+func (b *Breaker) Clock() Clock {
+	return b.options.clock
+}
+
+// State returns the current state. It does not change the state: an open Breaker becomes half-open
+// when its timeout expires, or when a request is allowed after that if the Clock has not yet signaled it.
+// See WithLegacyState.
 func (b *Breaker) State() State {
+	if b.options.legacyState {
+		return b.advance()
+	}
+
+	return b.loadState()
+}
+
+// advance returns the current state after an open Breaker whose timeout has expired becomes half-open.
+func (b *Breaker) advance() State {
+	state := b.loadState()
+
+	// only an open Breaker whose timeout has expired needs the lock
+	if state != StateOpen || int64(b.monotonic()) <= b.openUntil.Load() {
+		return state
+	}
+
+	state, _ = b.state()
+
+	return state
+}
+
+// tick makes an open Breaker half-open when the Clock signals that the timeout ending at until has expired.
+func (b *Breaker) tick(until int64) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	// the Breaker may have closed, been forced, or had its timeout extended since the tick was scheduled
+	if b.loadState() == StateOpen && !b.forced && b.openUntil.Load() == until {
+		b.switchState(StateOpen, StateHalfOpen, ReasonTimeout, "")
+	}
+}
+
+// state returns the current state and the transition that last opened the Breaker.
+func (b *Breaker) state() (State, Transition) {
 	b.lock.Lock()
 	defer b.lock.Unlock()
 
-	state := b.currentState
+	return b.lockedState()
+}
+
+// lockedState is state for callers that hold the lock.
+func (b *Breaker) lockedState() (State, Transition) {
+	state := b.loadState()
 
-	if state == StateOpen {
-		now := timeNow()
-		if b.lastStateChange.Add(b.options.timeout).Before(now) {
-			b.switchState(StateOpen, StateHalfOpen)
-			return b.currentState
+	if state == StateOpen && !b.forced {
+		if b.changedAt+b.openTimeout < b.monotonic() {
+			b.switchState(StateOpen, StateHalfOpen, ReasonTimeout, "")
+			return b.loadState(), b.lastOpen
 		}
 	}
 
-	return state
+	return state, b.lastOpen
+}
+
+func (b *Breaker) loadState() State {
+	return State(b.currentState.Load() & 0xff)
+}
+
+// updateOpenUntil must be called with lock after the state, forced, changedAt, or openTimeout change.
+// It schedules a tick for when an open Breaker becomes half-open.
+func (b *Breaker) updateOpenUntil() {
+	switch {
+	case b.loadState() != StateOpen:
+		b.openUntil.Store(0)
+	case b.forced:
+		b.openUntil.Store(math.MaxInt64)
+	default:
+		until := b.changedAt + b.openTimeout
+		b.openUntil.Store(int64(until))
+		b.scheduleTick(until)
+	}
 }
 
 // Allow checks if a new request can proceed. It returns a callback that should be used to register
 // the success or failure in a separate step. If the circuit breaker doesn't allow requests, it returns an error.
 func (b *Breaker) Allow() (func(bool), error) {
-	s := b.State()
+	start, inflight, err := b.admit(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(success bool) {
+		if success {
+			b.record(start, inflight, OutcomeSuccess)
+		} else {
+			b.record(start, inflight, OutcomeFailure)
+		}
+	}, nil
+}
+
+// allow checks if a new request can proceed and returns the function that records its outcome.
+func (b *Breaker) allow() (func(Outcome), error) {
+	start, inflight, err := b.admit(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(o Outcome) {
+		b.record(start, inflight, o)
+	}, nil
+}
+
+// admit checks if a new request can proceed and counts it. It returns when the request started
+// and, if there is a concurrency limit, the number of requests in flight, which are passed to record.
+// If a is not nil, it is set to the state of the Breaker when the request was allowed.
+func (b *Breaker) admit(a *Admission) (time.Time, int, error) {
+	start, inflight, err := b.tryAdmit(a)
+
+	switch {
+	case err != nil && b.events.wants(EventRejection):
+		b.events.emit(Event{Kind: EventRejection, Time: b.options.clock.Now(), State: b.loadState(), Err: err})
+	case err == nil && b.events.wants(EventAdmission):
+		b.events.emit(Event{Kind: EventAdmission, Time: start, State: b.loadState()})
+	}
+
+	return start, inflight, err
+}
+
+func (b *Breaker) tryAdmit(a *Admission) (time.Time, int, error) {
+	b.advance()
+
+	// the state and generation are read together
+	v := b.currentState.Load()
+	s := State(v & 0xff)
 
 	switch s {
 	case StateOpen:
-		return nil, ErrOpenState
-	case StateHalfOpen:
-		requests := uint64(b.requests.Reduce(rolling.Sum))
-		if requests > b.options.maxRequests {
-			return nil, ErrTooManyRequests
+		b.lock.Lock()
+		lastOpen := b.lastOpen
+		b.lock.Unlock()
+
+		err := &OpenStateError{Transition: lastOpen}
+		b.logRejection(s, err)
+		return time.Time{}, 0, err
+	}
+
+	// requests rejected below are never sent, so they are checked before a half-open request is counted
+
+	if b.chaos != nil && b.chaos.reject(b.options.clock.Now()) {
+		err := b.chaos.error()
+		b.logRejection(s, err)
+		return time.Time{}, 0, err
+	}
+
+	if b.options.rateLimiter != nil && !b.options.rateLimiter.AllowN(b.options.clock.Now(), 1) {
+		b.logRejection(s, ErrRateLimited)
+		return time.Time{}, 0, ErrRateLimited
+	}
+
+	var inflight int
+
+	if b.limiter != nil {
+		var ok bool
+
+		inflight, ok = b.limiter.acquire()
+		if !ok {
+			b.logRejection(s, ErrLimitExceeded)
+			return time.Time{}, 0, ErrLimitExceeded
+		}
+	}
+
+	if s == StateHalfOpen {
+		_, maxRequests := b.thresholds()
+
+		if !b.halfOpenRequests.TryAdd(1, maxRequests) {
+			if b.limiter != nil {
+				b.limiter.release(0, inflight, OutcomeIgnored)
+			}
+
+			b.logRejection(s, ErrTooManyRequests)
+			return time.Time{}, 0, ErrTooManyRequests
+		}
+
+		if a != nil {
+			a.HalfOpenRemaining = maxRequests - min(b.halfOpenRequests.Sum(), maxRequests)
 		}
 	}
 
-	b.requests.Append(1.0)
+	if a != nil {
+		a.State = s
+		a.Generation = v >> 8
+		a.Degraded = s == StateDegraded
+	}
+
+	start := b.options.clock.Now()
 
-	return b.allowResult, nil
+	b.buckets.Add(start, countRequests, 1)
+	b.addShared(Counts{Requests: 1})
+
+	return start, inflight, nil
 }
 
-// to help testing
-var timeNow = time.Now
+// record records the outcome of a request admitted at start.
+func (b *Breaker) record(start time.Time, inflight int, o Outcome) {
+	if b.limiter != nil || b.latency != nil || b.apdex != nil || b.events.wants(EventOutcome) {
+		now := b.options.clock.Now()
 
-// must be called with lock
-func (b *Breaker) switchState(from State, to State) {
-	if from == to {
-		return
+		if b.limiter != nil {
+			b.limiter.release(now.Sub(start), inflight, o)
+		}
+
+		if b.latency != nil {
+			b.latency.add(now, now.Sub(start), o)
+		}
+
+		if b.apdex != nil {
+			b.apdex.add(start, now.Sub(start), o)
+		}
+
+		if b.events.wants(EventOutcome) {
+			b.events.emit(Event{Kind: EventOutcome, Time: now, State: b.loadState(), Outcome: o, Latency: now.Sub(start)})
+		}
+	}
+
+	b.recordOutcome(start, o)
+
+	if b.apdex != nil {
+		b.checkApdex()
 	}
+}
 
-	b.lastStateChange = timeNow()
+// Trip places the Breaker into the open state. After the timeout, the Breaker
+// becomes half-open as if it had been tripped by a failed request.
+func (b *Breaker) Trip() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
 
-	b.currentState = to
+	b.forced = false
 
-	b.options.onStateChange(from, to)
+	b.switchState(b.loadState(), StateOpen, ReasonTrip, "")
+	b.updateOpenUntil()
 }
 
-func (b *Breaker) setState(state State) {
+// TripFor places the Breaker into the open state for at least d, or the timeout
+// if it is longer. If the Breaker is already open, the open period is extended if needed.
+// It has no effect on a forced Breaker.
+func (b *Breaker) TripFor(d time.Duration) {
 	b.lock.Lock()
 	defer b.lock.Unlock()
 
-	b.switchState(b.currentState, state)
+	if b.forced {
+		return
+	}
+
+	b.switchState(b.loadState(), StateOpen, ReasonTrip, "")
+
+	if until := b.monotonic() + d; b.changedAt+b.openTimeout < until {
+		b.openTimeout = until - b.changedAt
+	}
+
+	b.updateOpenUntil()
 }
 
-func (b *Breaker) allowResult(success bool) {
-	state := b.State()
+// ForceOpen places the Breaker into the open state and holds it there,
+// regardless of the timeout, until ForceClose or Reset is called.
+func (b *Breaker) ForceOpen() {
+	b.force(StateOpen, true, ReasonForceOpen)
+}
 
-	if success {
-		b.onSuccess()
-		switch state {
-		case StateClosed, StateOpen:
-			return
-		case StateHalfOpen:
-			consecutiveSuccesses := atomic.LoadUint64(&b.consecutiveSuccesses)
-			if consecutiveSuccesses >= b.options.maxRequests {
-				b.setState(StateClosed)
-			}
-		}
+// ForceClose places the Breaker into the closed state and holds it there,
+// regardless of failures, until ForceOpen or Reset is called.
+func (b *Breaker) ForceClose() {
+	b.force(StateClosed, true, ReasonForceClose)
+}
+
+// Reset clears any forced state and counts and places the Breaker into the closed state.
+func (b *Breaker) Reset() {
+	b.discardSuccesses()
+	b.buckets.Reset()
+	if b.apdex != nil {
+		b.apdex.counts.Reset()
+	}
+	atomic.StoreUint64(&b.consecutiveSuccesses, 0)
+	atomic.StoreUint64(&b.consecutiveFailures, 0)
+
+	b.force(StateClosed, false, ReasonReset)
+}
+
+func (b *Breaker) force(state State, forced bool, reason Reason) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	from := b.loadState()
+	b.forced = forced
+
+	if b.options.logger != nil {
+		b.options.logger.LogAttrs(context.Background(), slog.LevelInfo, "circuit breaker state forced",
+			slog.String("breaker", b.options.name),
+			slog.String("from", from.String()),
+			slog.String("to", state.String()),
+			slog.Bool("forced", forced),
+		)
+	}
+
+	b.switchState(from, state, reason, "")
+	b.updateOpenUntil()
+}
 
+// must be called with lock
+func (b *Breaker) switchState(from State, to State, reason Reason, condition string) {
+	if from == to {
 		return
 	}
 
-	b.onFailure()
+	now := b.options.clock.Now()
+	b.stats.transition(from, to, b.lastStateChange, now)
 
-	switch state {
-	case StateClosed:
-		counts := Counts{
-			Requests:             uint64(b.requests.Reduce(rolling.Sum)),
-			TotalSuccesses:       uint64(b.totalSuccesses.Reduce(rolling.Sum)),
-			TotalFailures:        uint64(b.totalFailures.Reduce(rolling.Sum)),
-			ConsecutiveSuccesses: atomic.LoadUint64(&b.consecutiveSuccesses),
-			ConsecutiveFailures:  atomic.LoadUint64(&b.consecutiveFailures),
-		}
+	b.lastStateChange = now
+	b.changedAt = b.monotonic()
 
-		if b.options.readyToTrip(counts) {
-			b.setState(StateOpen)
+	if to == StateClosed && from != StateDegraded {
+		b.closedAt = b.changedAt
+
+		// the requests that tripped the Breaker would trip it again
+		if b.apdex != nil {
+			b.apdex.counts.Reset()
 		}
-	case StateHalfOpen:
-		b.setState(StateOpen)
 	}
-}
+	b.openTimeout = b.options.timeout
 
-func (b *Breaker) onSuccess() {
-	b.totalSuccesses.Append(1.0)
-	atomic.AddUint64(&b.consecutiveSuccesses, 1)
-	atomic.StoreUint64(&b.consecutiveFailures, 0)
-}
+	b.currentState.Store((b.currentState.Load()>>8+1)<<8 | uint64(to))
+	b.updateOpenUntil()
 
-func (b *Breaker) onFailure() {
-	b.totalFailures.Append(1.0)
-	atomic.AddUint64(&b.consecutiveFailures, 1)
-	atomic.StoreUint64(&b.consecutiveSuccesses, 0)
+	if to == StateHalfOpen {
+		b.halfOpenRequests.Reset()
+	}
+
+	counts := b.counts()
+
+	t := Transition{
+		Time:      b.lastStateChange,
+		From:      from,
+		To:        to,
+		Counts:    counts,
+		Reason:    reason,
+		Condition: condition,
+	}
+
+	if to == StateOpen {
+		b.lastOpen = t
+	}
+
+	b.history.add(t)
+
+	if b.options.logger != nil {
+		b.options.logger.LogAttrs(context.Background(), slog.LevelInfo, "circuit breaker state changed",
+			slog.String("breaker", b.options.name),
+			slog.String("from", from.String()),
+			slog.String("to", to.String()),
+			slog.String("reason", reason.String()),
+			slog.String("condition", condition),
+			slog.Bool("manual", reason.Manual()),
+			slog.Any("counts", counts),
+		)
+	}
+
+	// the subscribers are copied, as they may change before the observers are called
+	subscribers := make([]OnTransition, 0, len(b.subscribers))
+	for _, fn := range b.subscribers {
+		subscribers = append(subscribers, fn)
+	}
+
+	if onStateChange, onTransition, notifiers := b.options.onStateChange, b.options.onTransition, b.options.notifiers; onStateChange != nil || onTransition != nil || len(notifiers) > 0 || len(subscribers) > 0 {
+		e := TransitionEvent{Name: b.options.name, Labels: b.Labels(), Transition: t}
+
+		b.observe(func() {
+			if onStateChange != nil {
+				onStateChange(from, to)
+			}
+
+			if onTransition != nil {
+				onTransition(t)
+			}
+
+			for _, n := range notifiers {
+				n.Notify(e)
+			}
+
+			for _, fn := range subscribers {
+				fn(t)
+			}
+		})
+	}
+
+	if b.events.wants(EventTransition) {
+		b.events.emit(Event{Kind: EventTransition, Time: t.Time, State: to, Transition: t})
+	}
 }
 
-type timePolicy struct {
-	policy *rolling.TimePolicy
-	lock   sync.Mutex
+func (b *Breaker) logRejection(state State, err error) {
+	if b.options.logger == nil {
+		return
+	}
+
+	b.options.logger.LogAttrs(context.Background(), slog.LevelDebug, "circuit breaker rejected request",
+		slog.String("breaker", b.options.name),
+		slog.String("state", state.String()),
+		slog.String("error", err.Error()),
+	)
 }
 
-func newTimePolicy(window rolling.Window, bucketDuration time.Duration) *timePolicy {
-	return &timePolicy{
-		policy: rolling.NewTimePolicy(window, bucketDuration),
+func (b *Breaker) counts() Counts {
+	b.flushSuccesses()
+
+	if b.options.sharedCounts != nil {
+		c := b.options.sharedCounts.Counts(b.options.name, b.buckets.Window())
+		c.ConsecutiveSuccesses = atomic.LoadUint64(&b.consecutiveSuccesses)
+		c.ConsecutiveFailures = atomic.LoadUint64(&b.consecutiveFailures)
+
+		return c
+	}
+
+	var sums [numCounters]uint64
+	b.buckets.Sums(b.options.clock.Now(), sums[:])
+
+	return Counts{
+		Requests:             sums[countRequests],
+		TotalSuccesses:       sums[countSuccesses],
+		TotalFailures:        sums[countFailures],
+		ConsecutiveSuccesses: atomic.LoadUint64(&b.consecutiveSuccesses),
+		ConsecutiveFailures:  atomic.LoadUint64(&b.consecutiveFailures),
 	}
 }
 
-func (p *timePolicy) Append(value float64) {
-	p.lock.Lock()
-	defer p.lock.Unlock()
+// allowResult records the result of a request that started at start.
+// Counts are updated without the lock. A success only needs the lock when the Breaker is half-open,
+// and a failure takes it once to decide on a transition against a consistent state and options.
+func (b *Breaker) allowResult(start time.Time, success bool) {
+	if b.options.errorBudget != nil {
+		b.options.errorBudget.add(start, success)
+	}
+
+	var maxRequests uint64
+
+	if success {
+		b.onSuccess(start)
+
+		if s := b.advance(); s != StateHalfOpen && s != StateDegraded {
+			return
+		}
+
+		// the threshold is read as admission reads it, as signals may adjust it
+		_, maxRequests = b.thresholds()
+	} else {
+		b.onFailure(start)
+	}
 
-	p.policy.Append(value)
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.forced {
+		return
+	}
+
+	state, _ := b.lockedState()
+
+	switch {
+	case success && state == StateHalfOpen:
+		if atomic.LoadUint64(&b.consecutiveSuccesses) >= maxRequests {
+			b.switchState(state, StateClosed, ReasonHalfOpenSuccess, "")
+		}
+	case success && state == StateDegraded:
+		b.degrade(state, b.counts())
+	case !success && closed(state):
+		if b.monotonic()-b.closedAt < b.options.warmup {
+			return
+		}
+
+		counts := b.counts()
+
+		conditions := b.options.conditions
+		if b.options.schedule != nil {
+			conditions = b.options.schedule.conditions(b.options.clock.Now(), conditions)
+		}
+
+		for _, c := range conditions {
+			if c.readyToTrip(counts) {
+				b.switchState(state, StateOpen, ReasonReadyToTrip, c.name)
+				return
+			}
+		}
+
+		b.degrade(state, counts)
+	case !success && state == StateHalfOpen:
+		b.switchState(state, StateOpen, ReasonHalfOpenFailure, "")
+	}
 }
 
-func (p *timePolicy) Reduce(f func(rolling.Window) float64) float64 {
-	p.lock.Lock()
-	defer p.lock.Unlock()
+func (b *Breaker) onSuccess(start time.Time) {
+	if b.batch != nil && b.loadState() == StateClosed {
+		b.batchSuccess(start)
+		return
+	}
+
+	b.buckets.Add(start, countSuccesses, 1)
+	b.addShared(Counts{TotalSuccesses: 1})
+	atomic.AddUint64(&b.consecutiveSuccesses, 1)
+	atomic.StoreUint64(&b.consecutiveFailures, 0)
+}
 
-	return p.policy.Reduce(f)
+func (b *Breaker) onFailure(start time.Time) {
+	b.flushSuccesses()
+	b.buckets.Add(start, countFailures, 1)
+	b.addShared(Counts{TotalFailures: 1})
+	atomic.AddUint64(&b.consecutiveFailures, 1)
+	atomic.StoreUint64(&b.consecutiveSuccesses, 0)
 }
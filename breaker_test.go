@@ -1,6 +1,11 @@
 package circuitbreaker
 
 import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -42,36 +47,66 @@ func TestFailure(t *testing.T) {
 	require.Equal(t, StateOpen, b.State())
 
 	cb, err = b.Allow()
-	require.Equal(t, ErrOpenState, err)
+	require.ErrorIs(t, err, ErrOpenState)
 	require.Nil(t, cb)
 }
 
 type testClock struct {
-	now time.Time
+	now   time.Time
+	funcs []testFunc
+}
+
+type testFunc struct {
+	at time.Time
+	f  func()
 }
 
 func (t *testClock) Now() time.Time {
 	return t.now
 }
 
-func TestHalfOpen(t *testing.T) {
-	current := timeNow
+func (t *testClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (t *testClock) AfterFunc(d time.Duration, f func()) {
+	t.funcs = append(t.funcs, testFunc{at: t.now.Add(d), f: f})
+}
+
+// advance moves the clock forward by d and calls the functions passed to AfterFunc that are due.
+func (t *testClock) advance(d time.Duration) {
+	t.now = t.now.Add(d)
+
+	var due []func()
+
+	funcs := t.funcs[:0]
+
+	for _, f := range t.funcs {
+		if t.now.Before(f.at) {
+			funcs = append(funcs, f)
+			continue
+		}
 
-	defer func() {
-		timeNow = current
-	}()
+		due = append(due, f.f)
+	}
+
+	t.funcs = funcs
+
+	for _, f := range due {
+		f()
+	}
+}
 
+func TestHalfOpen(t *testing.T) {
 	c := &testClock{
 		now: time.Now(),
 	}
 
-	timeNow = c.Now
-
 	readyToTrip := func(c Counts) bool {
 		return true
 	}
 
-	b, err := New(WithReadyToTrip(readyToTrip), WithTimeout(time.Second))
+	b, err := New(WithClock(c), WithReadyToTrip(readyToTrip), WithTimeout(time.Second))
 	require.NoError(t, err)
 
 	require.Equal(t, StateClosed, b.State())
@@ -86,10 +121,10 @@ func TestHalfOpen(t *testing.T) {
 	require.Equal(t, StateOpen, b.State())
 
 	cb, err = b.Allow()
-	require.Equal(t, ErrOpenState, err)
+	require.ErrorIs(t, err, ErrOpenState)
 	require.Nil(t, cb)
 
-	c.now = c.now.Add(time.Minute)
+	c.advance(time.Minute)
 
 	require.Equal(t, StateHalfOpen, b.State())
 
@@ -104,7 +139,7 @@ func TestHalfOpen(t *testing.T) {
 	require.Equal(t, StateHalfOpen, b.State())
 
 	// for time window
-	time.Sleep(time.Second * 2)
+	c.advance(2 * time.Second)
 
 	require.Equal(t, StateHalfOpen, b.State())
 
@@ -118,3 +153,286 @@ func TestHalfOpen(t *testing.T) {
 
 	require.Equal(t, StateClosed, b.State())
 }
+
+func TestForce(t *testing.T) {
+	b, err := New()
+	require.NoError(t, err)
+
+	b.ForceOpen()
+	require.Equal(t, StateOpen, b.State())
+
+	cb, err := b.Allow()
+	require.ErrorIs(t, err, ErrOpenState)
+	require.Nil(t, cb)
+
+	b.ForceClose()
+	require.Equal(t, StateClosed, b.State())
+
+	for i := 0; i < 10; i++ {
+		cb, err = b.Allow()
+		require.NoError(t, err)
+		cb(false)
+	}
+
+	require.Equal(t, StateClosed, b.State())
+
+	b.Reset()
+	require.Equal(t, StateClosed, b.State())
+	require.Equal(t, Counts{}, b.counts())
+}
+
+func TestLogger(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	readyToTrip := func(c Counts) bool {
+		return true
+	}
+
+	b, err := New(WithName("test"), WithLogger(logger), WithReadyToTrip(readyToTrip))
+	require.NoError(t, err)
+
+	cb, err := b.Allow()
+	require.NoError(t, err)
+
+	cb(false)
+
+	_, err = b.Allow()
+	require.ErrorIs(t, err, ErrOpenState)
+
+	b.ForceClose()
+
+	var records []map[string]interface{}
+
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var r map[string]interface{}
+		require.NoError(t, dec.Decode(&r))
+		records = append(records, r)
+	}
+
+	require.Len(t, records, 4)
+
+	require.Equal(t, "circuit breaker state changed", records[0]["msg"])
+	require.Equal(t, "test", records[0]["breaker"])
+	require.Equal(t, "closed", records[0]["from"])
+	require.Equal(t, "open", records[0]["to"])
+	require.Equal(t, float64(1), records[0]["counts"].(map[string]interface{})["totalFailures"])
+
+	require.Equal(t, "circuit breaker rejected request", records[1]["msg"])
+	require.Equal(t, "DEBUG", records[1]["level"])
+
+	require.Equal(t, "circuit breaker state forced", records[2]["msg"])
+	require.Equal(t, "circuit breaker state changed", records[3]["msg"])
+}
+
+func TestTripFor(t *testing.T) {
+	c := &testClock{
+		now: time.Now(),
+	}
+
+	b, err := New(WithClock(c), WithTimeout(10*time.Second))
+	require.NoError(t, err)
+
+	b.TripFor(time.Second)
+	require.Equal(t, StateOpen, b.State())
+	require.Equal(t, 10*time.Second, b.Status().RetryAfter)
+
+	b.TripFor(time.Minute)
+	require.Equal(t, time.Minute, b.Status().RetryAfter)
+	require.Len(t, b.History(), 1)
+
+	c.advance(30 * time.Second)
+	require.Equal(t, StateOpen, b.State())
+
+	c.advance(31 * time.Second)
+	require.Equal(t, StateHalfOpen, b.State())
+
+	b.ForceClose()
+	b.TripFor(time.Minute)
+	require.Equal(t, StateClosed, b.State())
+}
+
+func TestCountsConsistent(t *testing.T) {
+	c := &testClock{
+		now: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	b, err := New(WithClock(c), WithWindow(2*time.Second), WithReadyToTrip(func(Counts) bool { return false }))
+	require.NoError(t, err)
+
+	slow, err := b.Allow()
+	require.NoError(t, err)
+
+	c.advance(time.Second)
+
+	fast, err := b.Allow()
+	require.NoError(t, err)
+
+	// the outcome of the slow request is counted with the request
+	slow(false)
+	fast(true)
+
+	require.Equal(t, Counts{Requests: 2, TotalSuccesses: 1, TotalFailures: 1, ConsecutiveSuccesses: 1}, b.Status().Counts)
+
+	c.advance(time.Second)
+
+	require.Equal(t, Counts{Requests: 1, TotalSuccesses: 1, ConsecutiveSuccesses: 1}, b.Status().Counts)
+
+	// the request is no longer in the window, so neither is its outcome
+	late, err := b.Allow()
+	require.NoError(t, err)
+
+	c.advance(2 * time.Second)
+	late(false)
+
+	require.Equal(t, Counts{ConsecutiveFailures: 1}, b.Status().Counts)
+}
+
+func TestShards(t *testing.T) {
+	b, err := New(WithShards(4), WithWindow(time.Minute), WithReadyToTrip(func(Counts) bool { return false }))
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 50; j++ {
+				done, err := b.Allow()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+
+				done(j%2 == 0)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	counts := b.Status().Counts
+	require.Equal(t, uint64(200), counts.Requests)
+	require.Equal(t, uint64(100), counts.TotalSuccesses)
+	require.Equal(t, uint64(100), counts.TotalFailures)
+}
+
+func TestConcurrentFailuresTripOnce(t *testing.T) {
+	var transitions atomic.Int32
+
+	b, err := New(
+		WithWindow(time.Minute),
+		WithTimeout(time.Minute),
+		WithReadyToTrip(func(c Counts) bool { return c.TotalFailures >= 2 }),
+		WithOnTransition(func(Transition) { transitions.Add(1) }),
+		WithSynchronousObservers(),
+	)
+	require.NoError(t, err)
+
+	dones := make([]func(bool), 10)
+	for i := range dones {
+		dones[i], err = b.Allow()
+		require.NoError(t, err)
+	}
+
+	var wg sync.WaitGroup
+
+	for _, done := range dones {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			done(false)
+		}()
+	}
+
+	wg.Wait()
+
+	require.Equal(t, StateOpen, b.State())
+	require.Equal(t, int32(1), transitions.Load())
+}
+
+func TestStateDoesNotTransition(t *testing.T) {
+	c := &testClock{
+		now: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	b, err := New(WithClock(c))
+	require.NoError(t, err)
+
+	b.Trip()
+
+	// the timeout expires without the clock signaling it
+	c.now = c.now.Add(2 * time.Second)
+	require.Equal(t, StateOpen, b.State())
+	require.Equal(t, StateOpen, b.Status().State)
+
+	// until a request is allowed
+	_, err = b.Allow()
+	require.NoError(t, err)
+	require.Equal(t, StateHalfOpen, b.State())
+
+	// but the clock signals the next timeout
+	b.Trip()
+	c.advance(2 * time.Second)
+	require.Equal(t, StateHalfOpen, b.State())
+}
+
+func TestLegacyState(t *testing.T) {
+	c := &testClock{
+		now: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	b, err := New(WithClock(c), WithLegacyState())
+	require.NoError(t, err)
+
+	b.Trip()
+
+	c.now = c.now.Add(2 * time.Second)
+	require.Equal(t, StateHalfOpen, b.State())
+}
+
+func TestWarmup(t *testing.T) {
+	c := &testClock{
+		now: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	b, err := New(WithClock(c), WithWindow(time.Minute), WithWarmup(10*time.Second),
+		WithReadyToTrip(func(c Counts) bool { return c.ConsecutiveFailures >= 1 }))
+	require.NoError(t, err)
+
+	fail := func() {
+		done, err := b.Allow()
+		require.NoError(t, err)
+		done(false)
+	}
+
+	// failures are counted, but do not trip the breaker while it warms up
+	fail()
+	require.Equal(t, StateClosed, b.State())
+	require.Equal(t, uint64(1), b.Status().Counts.TotalFailures)
+
+	c.advance(10 * time.Second)
+	fail()
+	require.Equal(t, StateOpen, b.State())
+
+	// a failure while half-open still opens the breaker
+	c.advance(2 * time.Second)
+	fail()
+	require.Equal(t, StateOpen, b.State())
+
+	// and the warmup starts again once it closes
+	c.advance(2 * time.Second)
+	done, err := b.Allow()
+	require.NoError(t, err)
+	done(true)
+	require.Equal(t, StateClosed, b.State())
+
+	fail()
+	require.Equal(t, StateClosed, b.State())
+}
@@ -0,0 +1,161 @@
+// Package breakertest provides helpers for testing code that uses circuit breakers:
+// a fake clock, a scripted breaker, and assertions for transitions.
+package breakertest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+// Clock is a circuitbreaker.Clock that only moves when it is advanced.
+// Like the system clock, it has a wall clock and a monotonic clock: Advance and Set move both,
+// while Jump only moves the wall clock. Timers wait on the monotonic clock.
+// It is safe for concurrent use.
+type Clock struct {
+	now       time.Time
+	monotonic time.Duration
+	waiters   []waiter
+	lock      sync.Mutex
+}
+
+type waiter struct {
+	until time.Duration
+	ch    chan time.Time
+	f     func()
+}
+
+var (
+	_ circuitbreaker.AfterFuncClock = &Clock{}
+	_ circuitbreaker.MonotonicClock = &Clock{}
+)
+
+// NewClock creates a Clock set to now.
+func NewClock(now time.Time) *Clock {
+	return &Clock{now: now}
+}
+
+// Now implements circuitbreaker.Clock.
+func (c *Clock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.now
+}
+
+// Monotonic implements circuitbreaker.MonotonicClock. It starts at zero.
+func (c *Clock) Monotonic() time.Duration {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.monotonic
+}
+
+// After implements circuitbreaker.Clock. The channel receives when the Clock is advanced by at least d.
+func (c *Clock) After(d time.Duration) <-chan time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	ch := make(chan time.Time, 1)
+
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+
+	c.waiters = append(c.waiters, waiter{until: c.monotonic + d, ch: ch})
+
+	return ch
+}
+
+// AfterFunc implements circuitbreaker.AfterFuncClock. f is called by Advance or Set once the Clock
+// is advanced by at least d, after the Clock is unlocked.
+func (c *Clock) AfterFunc(d time.Duration, f func()) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if d <= 0 {
+		go f()
+		return
+	}
+
+	c.waiters = append(c.waiters, waiter{until: c.monotonic + d, f: f})
+}
+
+// Advance moves the Clock forward by d.
+func (c *Clock) Advance(d time.Duration) {
+	c.lock.Lock()
+	c.now = c.now.Add(d)
+	funcs := c.advance(d)
+	c.lock.Unlock()
+
+	call(funcs)
+}
+
+// Set moves the Clock to now, which may be in the past. The monotonic clock only moves forward.
+func (c *Clock) Set(now time.Time) {
+	c.lock.Lock()
+	d := max(now.Sub(c.now), 0)
+	c.now = now
+	funcs := c.advance(d)
+	c.lock.Unlock()
+
+	call(funcs)
+}
+
+// Jump moves the wall clock by d, which may be negative, without moving the monotonic clock,
+// like a step of the system clock by NTP.
+func (c *Clock) Jump(d time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+// Waiters returns the number of channels returned by After that have not received.
+// It can be used to wait until code under test is waiting on the Clock before advancing it.
+func (c *Clock) Waiters() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	n := 0
+
+	for _, w := range c.waiters {
+		if w.ch != nil {
+			n++
+		}
+	}
+
+	return n
+}
+
+// must be called with lock. It returns the functions passed to AfterFunc that are due.
+func (c *Clock) advance(d time.Duration) []func() {
+	c.monotonic += d
+
+	var due []func()
+
+	waiters := c.waiters[:0]
+
+	for _, w := range c.waiters {
+		switch {
+		case c.monotonic < w.until:
+			waiters = append(waiters, w)
+		case w.f != nil:
+			due = append(due, w.f)
+		default:
+			w.ch <- c.now
+		}
+	}
+
+	c.waiters = waiters
+
+	return due
+}
+
+func call(funcs []func()) {
+	for _, f := range funcs {
+		f()
+	}
+}
@@ -0,0 +1,75 @@
+package breakertest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+func TestClock(t *testing.T) {
+	start := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	c := NewClock(start)
+
+	after := c.After(time.Second)
+	require.Equal(t, 1, c.Waiters())
+
+	c.Advance(500 * time.Millisecond)
+
+	select {
+	case <-after:
+		t.Fatal("received before the duration")
+	default:
+	}
+
+	c.Advance(500 * time.Millisecond)
+	require.Equal(t, start.Add(time.Second), <-after)
+	require.Equal(t, 0, c.Waiters())
+}
+
+func TestClockBreaker(t *testing.T) {
+	c := NewClock(time.Now())
+
+	b, err := circuitbreaker.New(
+		circuitbreaker.WithClock(c),
+		circuitbreaker.WithReadyToTrip(func(circuitbreaker.Counts) bool { return true }),
+		circuitbreaker.WithTimeout(time.Minute),
+	)
+	require.NoError(t, err)
+
+	done, err := b.Allow()
+	require.NoError(t, err)
+	done(false)
+
+	RequireState(t, b, circuitbreaker.StateOpen)
+
+	c.Advance(time.Minute + time.Second)
+
+	RequireState(t, b, circuitbreaker.StateHalfOpen)
+}
+
+func TestClockJump(t *testing.T) {
+	c := NewClock(time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	b, err := circuitbreaker.New(circuitbreaker.WithClock(c), circuitbreaker.WithTimeout(time.Minute))
+	require.NoError(t, err)
+
+	b.Trip()
+
+	// steps of the wall clock do not change how long the Breaker is open
+	c.Jump(time.Hour)
+	_, err = b.Allow()
+	require.ErrorIs(t, err, circuitbreaker.ErrOpenState)
+	require.Equal(t, time.Minute, b.Status().RetryAfter)
+
+	c.Jump(-2 * time.Hour)
+	c.Advance(30 * time.Second)
+	_, err = b.Allow()
+	require.ErrorIs(t, err, circuitbreaker.ErrOpenState)
+	require.Equal(t, 30*time.Second, b.Status().RetryAfter)
+
+	c.Advance(30 * time.Second)
+	RequireState(t, b, circuitbreaker.StateHalfOpen)
+}
@@ -0,0 +1,109 @@
+package breakertest
+
+import (
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+// Recorder records the transitions of a Breaker.
+type Recorder struct {
+	transitions []circuitbreaker.Transition
+	lock        sync.Mutex
+}
+
+// Record records the transitions of b until the test ends.
+// b should be created with circuitbreaker.WithSynchronousObservers, so transitions are recorded before the call that caused them returns.
+func Record(t testing.TB, b *circuitbreaker.Breaker) *Recorder {
+	r := &Recorder{}
+
+	unsubscribe := b.Subscribe(func(tr circuitbreaker.Transition) {
+		r.lock.Lock()
+		defer r.lock.Unlock()
+
+		r.transitions = append(r.transitions, tr)
+	})
+
+	t.Cleanup(unsubscribe)
+
+	return r
+}
+
+// Transitions returns the recorded transitions.
+func (r *Recorder) Transitions() []circuitbreaker.Transition {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return append([]circuitbreaker.Transition(nil), r.transitions...)
+}
+
+// States returns the state entered by each recorded transition.
+func (r *Recorder) States() []circuitbreaker.State {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	states := make([]circuitbreaker.State, 0, len(r.transitions))
+	for _, tr := range r.transitions {
+		states = append(states, tr.To)
+	}
+
+	return states
+}
+
+// RequireStates fails the test unless the recorded transitions entered exactly the states, in order.
+func RequireStates(t testing.TB, r *Recorder, states ...circuitbreaker.State) {
+	t.Helper()
+
+	if got := r.States(); !slices.Equal(got, states) {
+		t.Fatalf("expected transitions to %v, got %v", states, got)
+	}
+}
+
+// RequireReasons fails the test unless the recorded transitions have exactly the reasons, in order.
+func RequireReasons(t testing.TB, r *Recorder, reasons ...circuitbreaker.Reason) {
+	t.Helper()
+
+	transitions := r.Transitions()
+
+	got := make([]circuitbreaker.Reason, 0, len(transitions))
+	for _, tr := range transitions {
+		got = append(got, tr.Reason)
+	}
+
+	if !slices.Equal(got, reasons) {
+		t.Fatalf("expected transitions with reasons %v, got %v", reasons, got)
+	}
+}
+
+// RequireState fails the test unless b is in state.
+func RequireState(t testing.TB, b interface{ State() circuitbreaker.State }, state circuitbreaker.State) {
+	t.Helper()
+
+	if got := b.State(); got != state {
+		t.Fatalf("expected state %s, got %s", state, got)
+	}
+}
+
+// EventuallyState fails the test unless b is in state within timeout, checking every millisecond.
+// It is for code that changes the state in another goroutine.
+func EventuallyState(t testing.TB, b interface{ State() circuitbreaker.State }, state circuitbreaker.State, timeout time.Duration) {
+	t.Helper()
+
+	deadline := time.Now().Add(timeout)
+
+	for {
+		got := b.State()
+		if got == state {
+			return
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("expected state %s within %s, got %s", state, timeout, got)
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+}
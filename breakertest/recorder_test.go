@@ -0,0 +1,36 @@
+package breakertest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+func TestRecorder(t *testing.T) {
+	c := NewClock(time.Now())
+
+	b, err := circuitbreaker.New(
+		circuitbreaker.WithClock(c),
+		circuitbreaker.WithTimeout(time.Minute),
+		circuitbreaker.WithSynchronousObservers(),
+	)
+	require.NoError(t, err)
+
+	r := Record(t, b)
+
+	b.Trip()
+	c.Advance(2 * time.Minute)
+	RequireState(t, b, circuitbreaker.StateHalfOpen)
+
+	done, err := b.Allow()
+	require.NoError(t, err)
+
+	done(true)
+
+	RequireStates(t, r, circuitbreaker.StateOpen, circuitbreaker.StateHalfOpen, circuitbreaker.StateClosed)
+	RequireReasons(t, r, circuitbreaker.ReasonTrip, circuitbreaker.ReasonTimeout, circuitbreaker.ReasonHalfOpenSuccess)
+	EventuallyState(t, b, circuitbreaker.StateClosed, time.Second)
+}
@@ -0,0 +1,137 @@
+package breakertest
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+// Step is the result of one request to a Scripted breaker.
+type Step struct {
+	// Err is returned by Allow. If it is nil and State is StateOpen, an error matching
+	// circuitbreaker.ErrOpenState is returned.
+	Err   error
+	State circuitbreaker.State
+}
+
+// Allowed is a Step in the closed state that allows the request.
+var Allowed = Step{State: circuitbreaker.StateClosed}
+
+// Rejected is a Step in the open state that rejects the request.
+var Rejected = Step{State: circuitbreaker.StateOpen}
+
+// Scripted is a breaker that returns a programmed sequence of results, for testing code
+// that guards calls with a breaker. It has the same Allow, AllowOutcome, Execute, and State methods as
+// *circuitbreaker.Breaker, so it can be used where code accepts an interface with those methods.
+// It is safe for concurrent use.
+type Scripted struct {
+	steps    []Step
+	outcomes []circuitbreaker.Outcome
+	next     int
+	lock     sync.Mutex
+}
+
+// NewScripted creates a Scripted breaker. Each request uses the next step, and the last step is repeated
+// once the steps are used. Without steps, every request is allowed.
+func NewScripted(steps ...Step) *Scripted {
+	if len(steps) == 0 {
+		steps = []Step{Allowed}
+	}
+
+	return &Scripted{steps: steps}
+}
+
+// step returns the step for the next request.
+func (s *Scripted) step() Step {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	step := s.steps[min(s.next, len(s.steps)-1)]
+	s.next++
+
+	return step
+}
+
+// AllowOutcome returns the result of the next step. Outcomes recorded using the callback are returned by Outcomes.
+func (s *Scripted) AllowOutcome() (func(circuitbreaker.Outcome), error) {
+	step := s.step()
+
+	switch {
+	case step.Err != nil:
+		return nil, step.Err
+	case step.State == circuitbreaker.StateOpen:
+		return nil, &circuitbreaker.OpenStateError{
+			Transition: circuitbreaker.Transition{To: circuitbreaker.StateOpen, Reason: circuitbreaker.ReasonTrip},
+		}
+	}
+
+	return func(o circuitbreaker.Outcome) {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+
+		s.outcomes = append(s.outcomes, o)
+	}, nil
+}
+
+// Allow is like AllowOutcome, but the callback records a success or failure.
+func (s *Scripted) Allow() (func(bool), error) {
+	done, err := s.AllowOutcome()
+	if err != nil {
+		return nil, err
+	}
+
+	return func(success bool) {
+		if success {
+			done(circuitbreaker.OutcomeSuccess)
+		} else {
+			done(circuitbreaker.OutcomeFailure)
+		}
+	}, nil
+}
+
+// Execute runs fn if the next step allows it and records the outcome like (*circuitbreaker.Breaker).Execute.
+func (s *Scripted) Execute(ctx context.Context, fn func(ctx context.Context) error) error {
+	done, err := s.AllowOutcome()
+	if err != nil {
+		return err
+	}
+
+	err = fn(ctx)
+
+	switch {
+	case err == nil:
+		done(circuitbreaker.OutcomeSuccess)
+	case errors.Is(err, context.Canceled) && ctx.Err() != nil:
+		done(circuitbreaker.OutcomeIgnored)
+	default:
+		done(circuitbreaker.OutcomeFailure)
+	}
+
+	return err
+}
+
+// State returns the state of the step used by the last request, or of the first step if there were no requests.
+func (s *Scripted) State() circuitbreaker.State {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.steps[min(max(s.next-1, 0), len(s.steps)-1)].State
+}
+
+// Requests returns the number of requests, including rejected requests.
+func (s *Scripted) Requests() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.next
+}
+
+// Outcomes returns the outcomes recorded for allowed requests, in the order they were recorded.
+func (s *Scripted) Outcomes() []circuitbreaker.Outcome {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return append([]circuitbreaker.Outcome(nil), s.outcomes...)
+}
@@ -0,0 +1,57 @@
+package breakertest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+func TestScripted(t *testing.T) {
+	s := NewScripted(Allowed, Rejected, Step{Err: circuitbreaker.ErrTooManyRequests, State: circuitbreaker.StateHalfOpen}, Allowed)
+
+	require.Equal(t, circuitbreaker.StateClosed, s.State())
+
+	err := s.Execute(context.Background(), func(context.Context) error {
+		return errors.New("fail")
+	})
+	require.EqualError(t, err, "fail")
+
+	_, err = s.Allow()
+	require.ErrorIs(t, err, circuitbreaker.ErrOpenState)
+	require.Equal(t, circuitbreaker.StateOpen, s.State())
+
+	_, err = s.Allow()
+	require.ErrorIs(t, err, circuitbreaker.ErrTooManyRequests)
+	require.Equal(t, circuitbreaker.StateHalfOpen, s.State())
+
+	// the last step repeats
+	for i := 0; i < 2; i++ {
+		done, err := s.Allow()
+		require.NoError(t, err)
+		done(true)
+	}
+
+	require.Equal(t, 5, s.Requests())
+	require.Equal(t, []circuitbreaker.Outcome{
+		circuitbreaker.OutcomeFailure,
+		circuitbreaker.OutcomeSuccess,
+		circuitbreaker.OutcomeSuccess,
+	}, s.Outcomes())
+}
+
+func TestScriptedInterface(t *testing.T) {
+	var b interface {
+		Execute(ctx context.Context, fn func(ctx context.Context) error) error
+		State() circuitbreaker.State
+	}
+
+	b = NewScripted()
+	require.NoError(t, b.Execute(context.Background(), func(context.Context) error { return nil }))
+
+	b, _ = circuitbreaker.New()
+	require.NoError(t, b.Execute(context.Background(), func(context.Context) error { return nil }))
+}
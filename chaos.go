@@ -0,0 +1,55 @@
+package circuitbreaker
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// Chaos configures fault injection, so fallback paths can be exercised in staging.
+// Requests rejected by Chaos return an *OpenStateError with ReasonChaos and are not counted.
+// The state of the Breaker is not changed.
+type Chaos struct {
+	// RejectFraction is the fraction of requests to reject at random, between 0 and 1.
+	RejectFraction float64
+	// OpenEvery is the period of the open windows, in which every request is rejected.
+	OpenEvery time.Duration
+	// OpenFor is the duration of each open window, at the start of each period. Must not exceed OpenEvery.
+	OpenFor time.Duration
+}
+
+// WithChaos rejects requests as configured by c.
+// It only has an effect in programs built with the circuitbreaker_chaos build tag, so it cannot be enabled in
+// a production build by accident. Use ChaosEnabled to check.
+// There is no default.
+func WithChaos(c Chaos) Option {
+	return func(o *Options) {
+		if chaosEnabled {
+			o.chaos = &c
+		}
+	}
+}
+
+// ChaosEnabled reports whether the program was built with the circuitbreaker_chaos build tag,
+// which is needed for WithChaos to have an effect.
+func ChaosEnabled() bool {
+	return chaosEnabled
+}
+
+// chaos is the fault injection state of a Breaker.
+type chaos struct {
+	config Chaos
+	start  time.Time
+}
+
+// reject reports whether a request at now should be rejected.
+func (c *chaos) reject(now time.Time) bool {
+	if c.config.OpenEvery > 0 && c.config.OpenFor > 0 && now.Sub(c.start)%c.config.OpenEvery < c.config.OpenFor {
+		return true
+	}
+
+	return c.config.RejectFraction > 0 && rand.Float64() < c.config.RejectFraction
+}
+
+func (c *chaos) error() error {
+	return &OpenStateError{Transition: Transition{To: StateOpen, Reason: ReasonChaos}}
+}
@@ -0,0 +1,5 @@
+//go:build circuitbreaker_chaos
+
+package circuitbreaker
+
+const chaosEnabled = true
@@ -0,0 +1,49 @@
+package circuitbreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChaosReject(t *testing.T) {
+	start := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	c := &chaos{
+		config: Chaos{OpenEvery: time.Minute, OpenFor: 10 * time.Second},
+		start:  start,
+	}
+
+	require.True(t, c.reject(start))
+	require.True(t, c.reject(start.Add(9*time.Second)))
+	require.False(t, c.reject(start.Add(10*time.Second)))
+	require.False(t, c.reject(start.Add(59*time.Second)))
+	require.True(t, c.reject(start.Add(time.Minute)))
+
+	all := &chaos{config: Chaos{RejectFraction: 1}}
+	require.True(t, all.reject(start))
+
+	none := &chaos{config: Chaos{}}
+	require.False(t, none.reject(start))
+}
+
+func TestWithChaos(t *testing.T) {
+	b, err := New(WithChaos(Chaos{RejectFraction: 1}))
+	require.NoError(t, err)
+
+	_, err = b.Allow()
+
+	if !ChaosEnabled() {
+		// without the build tag, chaos cannot be enabled
+		require.NoError(t, err)
+		return
+	}
+
+	require.ErrorIs(t, err, ErrOpenState)
+
+	var openErr *OpenStateError
+	require.ErrorAs(t, err, &openErr)
+	require.Equal(t, ReasonChaos, openErr.Transition.Reason)
+	require.Equal(t, StateClosed, b.State())
+}
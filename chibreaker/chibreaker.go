@@ -0,0 +1,58 @@
+// Package chibreaker provides chi middleware that runs requests through circuit breakers.
+package chibreaker
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/bakins/circuitbreaker"
+	"github.com/bakins/circuitbreaker/httpbreaker"
+)
+
+// RouteKey returns the method and route of a request, such as "GET /users/{id}".
+// Middleware added with Use runs before chi routes the request, so the route is found using the router.
+// Requests that match no route have an empty route.
+func RouteKey(r *http.Request) string {
+	var pattern string
+
+	if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.Routes != nil {
+		pattern = rctx.Routes.Find(chi.NewRouteContext(), r.Method, r.URL.Path)
+	}
+
+	return r.Method + " " + pattern
+}
+
+// Middleware returns chi middleware that runs each request through the breaker from g keyed by RouteKey.
+// It behaves like httpbreaker.Middleware and accepts the same options, such as httpbreaker.WithRejectHandler.
+func Middleware(g *circuitbreaker.Group[string], opts ...httpbreaker.Option) func(http.Handler) http.Handler {
+	in := httpbreaker.NewGroupInbound(g, opts...)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			done, ok := in.Allow(w, r, RouteKey(r))
+			if !ok {
+				return
+			}
+
+			defer func() {
+				if p := recover(); p != nil {
+					done.Panic()
+					panic(p)
+				}
+			}()
+
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+			next.ServeHTTP(ww, r)
+
+			code := ww.Status()
+			if code == 0 {
+				code = http.StatusOK
+			}
+
+			done.Status(code)
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package chibreaker
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+func TestMiddleware(t *testing.T) {
+	g, err := circuitbreaker.NewGroup[string](
+		circuitbreaker.WithWindow(time.Minute),
+		circuitbreaker.WithReadyToTrip(circuitbreaker.TripOnConsecutiveFailures(1)),
+	)
+	require.NoError(t, err)
+
+	r := chi.NewRouter()
+	r.Use(Middleware(g))
+	r.Route("/users", func(r chi.Router) {
+		r.Get("/{id}", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+	})
+	r.Get("/orders", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	})
+
+	serve := func(path string) int {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+		return w.Code
+	}
+
+	require.Equal(t, http.StatusInternalServerError, serve("/users/1"))
+	require.Equal(t, circuitbreaker.StateOpen, g.Get("GET /users/{id}").State())
+	require.Equal(t, http.StatusServiceUnavailable, serve("/users/2"))
+	require.Equal(t, http.StatusOK, serve("/orders"))
+	require.Equal(t, circuitbreaker.StateClosed, g.Get("GET /orders").State())
+}
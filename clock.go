@@ -0,0 +1,108 @@
+package circuitbreaker
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Clock provides the time to a Breaker.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// After returns a channel that receives the current time after d.
+	After(d time.Duration) <-chan time.Time
+}
+
+// AfterFuncClock is a Clock that can call a function after a duration. If the Clock passed to WithClock
+// implements it, an open Breaker uses AfterFunc to become half-open when its timeout expires,
+// otherwise it waits on After in a goroutine.
+type AfterFuncClock interface {
+	Clock
+	// AfterFunc calls f in its own goroutine, or in the goroutine that advances the Clock, after d.
+	AfterFunc(d time.Duration, f func())
+}
+
+// MonotonicClock is a Clock with a monotonic clock, which is not affected by changes to the wall clock.
+// If the Clock passed to WithClock implements it, open timeouts are measured with Monotonic, otherwise
+// with the difference between readings of Now, which uses the monotonic readings of times from time.Now.
+type MonotonicClock interface {
+	Clock
+	// Monotonic returns the time elapsed since an arbitrary, fixed point.
+	Monotonic() time.Duration
+}
+
+type realClock struct{}
+
+// processStart is the origin of the monotonic readings of realClock.
+var processStart = time.Now()
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (realClock) AfterFunc(d time.Duration, f func()) {
+	time.AfterFunc(d, f)
+}
+
+func (realClock) Monotonic() time.Duration {
+	return time.Since(processStart)
+}
+
+// monotonic returns the reading of the monotonic clock.
+func (b *Breaker) monotonic() time.Duration {
+	if c, ok := b.options.clock.(MonotonicClock); ok {
+		return c.Monotonic()
+	}
+
+	return b.options.clock.Now().Sub(b.epoch)
+}
+
+// scheduleTick makes the Breaker half-open at the monotonic reading until, if it is still open until then.
+func (b *Breaker) scheduleTick(until time.Duration) {
+	d := until - b.monotonic()
+	tick := func() {
+		b.tick(int64(until))
+	}
+
+	if c, ok := b.options.clock.(AfterFuncClock); ok {
+		c.AfterFunc(d, tick)
+		return
+	}
+
+	ch := b.options.clock.After(d)
+
+	go func() {
+		<-ch
+		tick()
+	}()
+}
+
+// WithClock sets the Clock used for timeouts, rolling windows, rate limits, delays, and saving to a Store,
+// and by integrations, see Breaker.Clock. It is intended for tests. The default uses the time package.
+func WithClock(clock Clock) Option {
+	return func(o *Options) {
+		o.clock = clock
+	}
+}
+
+// detectorClock is the Clock of the first Breaker a detector or ErrorBudget is added to.
+// It uses the time package until then.
+type detectorClock struct {
+	clock atomic.Pointer[Clock]
+}
+
+func (c *detectorClock) set(clock Clock) {
+	c.clock.CompareAndSwap(nil, &clock)
+}
+
+func (c *detectorClock) Now() time.Time {
+	if clock := c.clock.Load(); clock != nil {
+		return (*clock).Now()
+	}
+
+	return time.Now()
+}
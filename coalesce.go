@@ -0,0 +1,135 @@
+package circuitbreaker
+
+import (
+	"context"
+	"sync"
+)
+
+// Coalescer collapses identical concurrent requests into a single call. By default, requests are only
+// coalesced while the Breaker is not closed, such as while it is half-open and the dependency is
+// recovering, so that a burst of identical requests results in one call and one recorded outcome.
+// All callers waiting on a call share its result.
+type Coalescer[K comparable, V any] struct {
+	breaker *Breaker
+	calls   map[K]*coalescedCall[V]
+	always  bool
+	lock    sync.Mutex
+}
+
+type coalescedCall[V any] struct {
+	done    chan struct{}
+	cancel  context.CancelFunc
+	value   V
+	err     error
+	waiters int
+}
+
+// CoalescerOption sets Coalescer options.
+type CoalescerOption func(*coalescerOptions)
+
+type coalescerOptions struct {
+	always bool
+}
+
+// WithAlwaysCoalesce coalesces requests regardless of the state of the Breaker.
+func WithAlwaysCoalesce() CoalescerOption {
+	return func(o *coalescerOptions) {
+		o.always = true
+	}
+}
+
+// NewCoalescer creates a Coalescer that uses b.
+func NewCoalescer[K comparable, V any](b *Breaker, options ...CoalescerOption) *Coalescer[K, V] {
+	var opts coalescerOptions
+
+	for _, o := range options {
+		o(&opts)
+	}
+
+	return &Coalescer[K, V]{
+		breaker: b,
+		calls:   make(map[K]*coalescedCall[V]),
+		always:  opts.always,
+	}
+}
+
+// Execute runs fn using the Breaker's Execute, unless a call for key is already in progress,
+// in which case it waits for that call's result.
+//
+// A coalesced call is not canceled when the caller that started it gives up, only when all
+// callers waiting on it have. If ctx is done before the call returns, ctx's error is returned.
+func (c *Coalescer[K, V]) Execute(ctx context.Context, key K, fn func(ctx context.Context) (V, error)) (V, error) {
+	if !c.always && c.breaker.advance() == StateClosed {
+		return c.execute(ctx, fn)
+	}
+
+	c.lock.Lock()
+
+	call, ok := c.calls[key]
+	if !ok {
+		callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+
+		call = &coalescedCall[V]{
+			done:   make(chan struct{}),
+			cancel: cancel,
+		}
+
+		c.calls[key] = call
+
+		go c.run(callCtx, key, call, fn)
+	}
+
+	call.waiters++
+
+	c.lock.Unlock()
+
+	select {
+	case <-call.done:
+		return call.value, call.err
+	case <-ctx.Done():
+		c.lock.Lock()
+
+		call.waiters--
+		if call.waiters == 0 {
+			call.cancel()
+
+			// later callers start a new call rather than joining the canceled one
+			if c.calls[key] == call {
+				delete(c.calls, key)
+			}
+		}
+
+		c.lock.Unlock()
+
+		var zero V
+
+		return zero, ctx.Err()
+	}
+}
+
+func (c *Coalescer[K, V]) run(ctx context.Context, key K, call *coalescedCall[V], fn func(ctx context.Context) (V, error)) {
+	defer call.cancel()
+
+	call.value, call.err = c.execute(ctx, fn)
+
+	c.lock.Lock()
+	if c.calls[key] == call {
+		delete(c.calls, key)
+	}
+	c.lock.Unlock()
+
+	close(call.done)
+}
+
+func (c *Coalescer[K, V]) execute(ctx context.Context, fn func(ctx context.Context) (V, error)) (V, error) {
+	var value V
+
+	err := c.breaker.Execute(ctx, func(ctx context.Context) error {
+		v, err := fn(ctx)
+		value = v
+
+		return err
+	})
+
+	return value, err
+}
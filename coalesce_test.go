@@ -0,0 +1,121 @@
+package circuitbreaker
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoalescer(t *testing.T) {
+	c := &testClock{
+		now: time.Now(),
+	}
+
+	b, err := New(WithClock(c), WithTimeout(time.Second), WithMaxRequests(3))
+	require.NoError(t, err)
+
+	b.Trip()
+
+	c.advance(2 * time.Second)
+	require.Equal(t, StateHalfOpen, b.State())
+
+	co := NewCoalescer[string, int](b)
+
+	var calls atomic.Int32
+
+	release := make(chan struct{})
+
+	fn := func(context.Context) (int, error) {
+		calls.Add(1)
+		<-release
+
+		return 42, nil
+	}
+
+	var wg sync.WaitGroup
+
+	results := make(chan int, 5)
+
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			v, err := co.Execute(context.Background(), "key", fn)
+			if err == nil {
+				results <- v
+			}
+		}()
+	}
+
+	require.Eventually(t, func() bool {
+		co.lock.Lock()
+		defer co.lock.Unlock()
+
+		call, ok := co.calls["key"]
+
+		return ok && call.waiters == 5
+	}, time.Second, time.Millisecond)
+
+	close(release)
+	wg.Wait()
+	close(results)
+
+	require.Equal(t, int32(1), calls.Load())
+
+	for v := range results {
+		require.Equal(t, 42, v)
+	}
+
+	require.Equal(t, uint64(1), b.counts().TotalSuccesses)
+}
+
+func TestCoalescerCanceled(t *testing.T) {
+	b, err := New(WithWindow(time.Minute))
+	require.NoError(t, err)
+
+	co := NewCoalescer[string, int](b, WithAlwaysCoalesce())
+
+	canceled := make(chan struct{})
+	release := make(chan struct{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	_, err = co.Execute(ctx, "key", func(ctx context.Context) (int, error) {
+		<-ctx.Done()
+		close(canceled)
+		<-release
+
+		return 0, ctx.Err()
+	})
+	require.ErrorIs(t, err, context.Canceled)
+
+	// the call is canceled once all callers have given up
+	<-canceled
+
+	// a later caller does not join the canceled call, even while it is still running
+	v, err := co.Execute(context.Background(), "key", func(context.Context) (int, error) {
+		return 1, nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, v)
+
+	close(release)
+
+	require.Eventually(t, func() bool {
+		co.lock.Lock()
+		defer co.lock.Unlock()
+
+		return len(co.calls) == 0
+	}, time.Second, time.Millisecond)
+
+	counts := b.counts()
+	require.Equal(t, uint64(1), counts.TotalSuccesses)
+	require.Equal(t, uint64(0), counts.TotalFailures)
+}
@@ -0,0 +1,154 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrMaxConcurrency is returned by a Command when the maximum number of runs are in flight.
+var ErrMaxConcurrency = errors.New("circuit breaker command max concurrency exceeded")
+
+// Fallback is called by a Command with the error of a run that failed or was not allowed.
+// Its error is returned in place of the original error.
+type Fallback func(ctx context.Context, err error) error
+
+// Command bundles a Breaker with a timeout, a bulkhead, and a fallback, similar to a hystrix-go command.
+// Runs are recorded by the Breaker: nil errors are successes, runs canceled by the caller are ignored,
+// and other errors, including timeouts, are failures. Runs rejected by the bulkhead are not recorded.
+type Command struct {
+	breaker *Breaker
+	options commandOptions
+	tickets chan struct{}
+}
+
+type commandOptions struct {
+	timeout       time.Duration
+	maxConcurrent int
+	fallback      Fallback
+}
+
+// CommandOption sets Command options.
+type CommandOption func(*commandOptions)
+
+// WithCommandTimeout sets the maximum time a run may take. The run's context has this deadline.
+// If the run does not return in time, Run returns an error that matches both ErrCallTimeout and
+// context.DeadlineExceeded, while the run keeps its slot in the bulkhead until it returns.
+// There is no default.
+func WithCommandTimeout(d time.Duration) CommandOption {
+	return func(o *commandOptions) {
+		o.timeout = d
+	}
+}
+
+// WithMaxConcurrent sets the number of runs that may be in flight. Further runs are rejected
+// with ErrMaxConcurrency. There is no default.
+func WithMaxConcurrent(n int) CommandOption {
+	return func(o *commandOptions) {
+		o.maxConcurrent = n
+	}
+}
+
+// WithFallback sets the function called when a run fails or is not allowed.
+// There is no default.
+func WithFallback(fn Fallback) CommandOption {
+	return func(o *commandOptions) {
+		o.fallback = fn
+	}
+}
+
+// NewCommand creates a Command that runs requests through b.
+func NewCommand(b *Breaker, options ...CommandOption) *Command {
+	var opts commandOptions
+
+	for _, o := range options {
+		o(&opts)
+	}
+
+	c := &Command{
+		breaker: b,
+		options: opts,
+	}
+
+	if opts.maxConcurrent > 0 {
+		c.tickets = make(chan struct{}, opts.maxConcurrent)
+	}
+
+	return c
+}
+
+// Run runs fn if the bulkhead and Breaker allow it and returns its error, or the error from the fallback if fn
+// fails or is not allowed.
+func (c *Command) Run(ctx context.Context, fn Job) error {
+	err := c.run(ctx, fn)
+	if err != nil && c.options.fallback != nil {
+		return c.options.fallback(ctx, err)
+	}
+
+	return err
+}
+
+// RunAsync is like Run, but runs fn in a goroutine. The returned channel receives the result of Run.
+func (c *Command) RunAsync(ctx context.Context, fn Job) <-chan error {
+	result := make(chan error, 1)
+
+	go func() {
+		result <- c.Run(ctx, fn)
+	}()
+
+	return result
+}
+
+func (c *Command) run(ctx context.Context, fn Job) error {
+	if c.tickets != nil {
+		select {
+		case c.tickets <- struct{}{}:
+		default:
+			return ErrMaxConcurrency
+		}
+	}
+
+	release := func() {
+		if c.tickets != nil {
+			<-c.tickets
+		}
+	}
+
+	done, err := c.breaker.AllowOutcome()
+	if err != nil {
+		release()
+		return err
+	}
+
+	if c.options.timeout <= 0 {
+		err = fn(ctx)
+		release()
+		done(outcomeOf(ctx, err))
+
+		return err
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, c.options.timeout)
+	defer cancel()
+
+	result := make(chan error, 1)
+
+	go func() {
+		defer release()
+		result <- fn(callCtx)
+	}()
+
+	select {
+	case err = <-result:
+	case <-callCtx.Done():
+		err = ctx.Err()
+		if err == nil {
+			err = fmt.Errorf("%w: %w", ErrCallTimeout, context.DeadlineExceeded)
+		}
+	}
+
+	done(outcomeOf(ctx, err))
+
+	return err
+}
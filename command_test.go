@@ -0,0 +1,66 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommand(t *testing.T) {
+	b, err := New(WithWindow(time.Minute), WithReadyToTrip(func(c Counts) bool { return c.TotalFailures >= 2 }))
+	require.NoError(t, err)
+
+	var fallbacks []error
+
+	c := NewCommand(b, WithFallback(func(_ context.Context, err error) error {
+		fallbacks = append(fallbacks, err)
+		return nil
+	}))
+
+	ctx := context.Background()
+	fail := errors.New("fail")
+
+	require.NoError(t, c.Run(ctx, func(context.Context) error { return nil }))
+	require.NoError(t, c.Run(ctx, func(context.Context) error { return fail }))
+	require.NoError(t, <-c.RunAsync(ctx, func(context.Context) error { return fail }))
+	require.Equal(t, StateOpen, b.State())
+
+	require.NoError(t, c.Run(ctx, func(context.Context) error { return nil }))
+
+	require.Len(t, fallbacks, 3)
+	require.ErrorIs(t, fallbacks[0], fail)
+	require.ErrorIs(t, fallbacks[2], ErrOpenState)
+}
+
+func TestCommandTimeout(t *testing.T) {
+	b, err := New(WithWindow(time.Minute))
+	require.NoError(t, err)
+
+	c := NewCommand(b, WithCommandTimeout(10*time.Millisecond), WithMaxConcurrent(1))
+
+	release := make(chan struct{})
+	returned := make(chan struct{})
+
+	err = c.Run(context.Background(), func(context.Context) error {
+		defer close(returned)
+		<-release
+		return nil
+	})
+	require.ErrorIs(t, err, ErrCallTimeout)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Equal(t, uint64(1), b.Status().Counts.TotalFailures)
+
+	// the timed out run holds its slot until it returns
+	require.ErrorIs(t, c.Run(context.Background(), func(context.Context) error { return nil }), ErrMaxConcurrency)
+	require.Equal(t, uint64(1), b.Status().Counts.Requests)
+
+	close(release)
+	<-returned
+
+	require.Eventually(t, func() bool {
+		return c.Run(context.Background(), func(context.Context) error { return nil }) == nil
+	}, time.Second, time.Millisecond)
+}
@@ -0,0 +1,243 @@
+package circuitbreaker
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"time"
+)
+
+// ErrLimitExceeded is returned when the Breaker does not allow a request because the number of
+// requests in flight has reached the concurrency limit.
+var ErrLimitExceeded = errors.New("circuit breaker concurrency limit exceeded")
+
+// ConcurrencyLimit is an algorithm that adjusts the number of requests the Breaker allows in flight
+// based on their latency and outcome. It is implemented by AIMDLimit and GradientLimit.
+type ConcurrencyLimit interface {
+	newAlgorithm() limitAlgorithm
+}
+
+// limitAlgorithm is the state of a ConcurrencyLimit for a single Breaker.
+type limitAlgorithm interface {
+	limit() int
+	// update is called with the latency of a request, the number of requests in flight
+	// when it was allowed, and whether it failed.
+	update(rtt time.Duration, inflight int, failed bool)
+}
+
+// WithConcurrencyLimit limits the number of requests the Breaker allows in flight, adjusting the limit
+// as the dependency's latency changes. Requests over the limit are rejected with ErrLimitExceeded and are not
+// counted as requests. The limit applies in the closed and half-open states.
+// Callbacks returned by Allow and AllowOutcome must be called for every allowed request, including ignored ones.
+// There is no default.
+func WithConcurrencyLimit(l ConcurrencyLimit) Option {
+	return func(o *Options) {
+		o.concurrencyLimit = l
+	}
+}
+
+// AIMDLimit is an additive increase, multiplicative decrease ConcurrencyLimit. The limit grows by one
+// after each successful request while at least half of it is in use, and shrinks by BackoffRatio after
+// a failed request or a request slower than Timeout.
+type AIMDLimit struct {
+	// Initial is the initial limit. Default is 20.
+	Initial int
+	// Min is the minimum limit. Default is 1.
+	Min int
+	// Max is the maximum limit. Default is 200.
+	Max int
+	// BackoffRatio is the ratio the limit is multiplied by after a failure. Default is 0.9.
+	BackoffRatio float64
+	// Timeout is the latency above which a request is treated as a failure. Zero means no timeout.
+	Timeout time.Duration
+}
+
+func (a AIMDLimit) newAlgorithm() limitAlgorithm {
+	if a.Min <= 0 {
+		a.Min = 1
+	}
+
+	if a.Max <= 0 {
+		a.Max = 200
+	}
+
+	if a.Initial <= 0 {
+		a.Initial = 20
+	}
+
+	if a.BackoffRatio <= 0 || a.BackoffRatio >= 1 {
+		a.BackoffRatio = 0.9
+	}
+
+	return &aimd{
+		config:  a,
+		current: clampInt(a.Initial, a.Min, a.Max),
+	}
+}
+
+type aimd struct {
+	config  AIMDLimit
+	current int
+}
+
+func (a *aimd) limit() int {
+	return a.current
+}
+
+func (a *aimd) update(rtt time.Duration, inflight int, failed bool) {
+	if failed || (a.config.Timeout > 0 && rtt > a.config.Timeout) {
+		a.current = clampInt(int(float64(a.current)*a.config.BackoffRatio), a.config.Min, a.config.Max)
+		return
+	}
+
+	if inflight*2 >= a.current {
+		a.current = clampInt(a.current+1, a.config.Min, a.config.Max)
+	}
+}
+
+// GradientLimit is a ConcurrencyLimit that compares the latency of each request with the long term average.
+// While latency is near the average, the limit grows by roughly its square root; as latency rises,
+// which indicates requests are queueing, the limit shrinks in proportion.
+type GradientLimit struct {
+	// Initial is the initial limit. Default is 20.
+	Initial int
+	// Min is the minimum limit. Default is 1.
+	Min int
+	// Max is the maximum limit. Default is 200.
+	Max int
+	// Smoothing is how much of each new limit is applied, between 0 and 1. Default is 0.2.
+	Smoothing float64
+	// Window is the number of requests in the long term average. Default is 600.
+	Window int
+}
+
+func (g GradientLimit) newAlgorithm() limitAlgorithm {
+	if g.Min <= 0 {
+		g.Min = 1
+	}
+
+	if g.Max <= 0 {
+		g.Max = 200
+	}
+
+	if g.Initial <= 0 {
+		g.Initial = 20
+	}
+
+	if g.Smoothing <= 0 || g.Smoothing > 1 {
+		g.Smoothing = 0.2
+	}
+
+	if g.Window <= 0 {
+		g.Window = 600
+	}
+
+	return &gradient{
+		config:  g,
+		current: float64(clampInt(g.Initial, g.Min, g.Max)),
+	}
+}
+
+type gradient struct {
+	config  GradientLimit
+	current float64
+	longRTT float64
+	samples int
+}
+
+func (g *gradient) limit() int {
+	return int(g.current)
+}
+
+func (g *gradient) update(rtt time.Duration, inflight int, failed bool) {
+	short := float64(rtt)
+	if short <= 0 {
+		return
+	}
+
+	// exponential moving average, which is a simple average until the window is full
+	if g.samples < g.config.Window {
+		g.samples++
+	}
+
+	g.longRTT += (short - g.longRTT) / float64(g.samples)
+
+	// recover quickly after latency drops, rather than waiting for the average
+	if g.longRTT/short > 2 {
+		g.longRTT *= 0.95
+	}
+
+	// do not grow while the limit is not being used
+	if float64(inflight) < g.current/2 && !failed {
+		return
+	}
+
+	grad := math.Max(0.5, math.Min(1, g.longRTT/short))
+	if failed {
+		grad = 0.5
+	}
+
+	next := g.current*grad + math.Sqrt(g.current)
+	next = g.current*(1-g.config.Smoothing) + next*g.config.Smoothing
+
+	g.current = math.Max(float64(g.config.Min), math.Min(float64(g.config.Max), next))
+}
+
+func clampInt(v, lower, upper int) int {
+	return max(lower, min(upper, v))
+}
+
+// concurrencyLimiter tracks the requests in flight for a Breaker.
+type concurrencyLimiter struct {
+	algorithm limitAlgorithm
+	inflight  int
+	lock      sync.Mutex
+}
+
+func newConcurrencyLimiter(l ConcurrencyLimit) *concurrencyLimiter {
+	return &concurrencyLimiter{
+		algorithm: l.newAlgorithm(),
+	}
+}
+
+// acquire returns the number of requests in flight, including this one, or false if the limit has been reached.
+func (l *concurrencyLimiter) acquire() (int, bool) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.inflight >= l.algorithm.limit() {
+		return 0, false
+	}
+
+	l.inflight++
+
+	return l.inflight, true
+}
+
+func (l *concurrencyLimiter) release(rtt time.Duration, inflight int, o Outcome) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.inflight--
+
+	if o != OutcomeIgnored {
+		l.algorithm.update(rtt, inflight, o == OutcomeFailure)
+	}
+}
+
+func (l *concurrencyLimiter) state() (int, int) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	return l.algorithm.limit(), l.inflight
+}
+
+// ConcurrencyLimit returns the current concurrency limit and the number of requests in flight.
+// It returns zeros if the Breaker does not have a concurrency limit.
+func (b *Breaker) ConcurrencyLimit() (limit int, inflight int) {
+	if b.limiter == nil {
+		return 0, 0
+	}
+
+	return b.limiter.state()
+}
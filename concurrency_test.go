@@ -0,0 +1,118 @@
+package circuitbreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConcurrencyLimit(t *testing.T) {
+	c := &testClock{
+		now: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	b, err := New(WithClock(c), WithConcurrencyLimit(AIMDLimit{Initial: 2, Max: 3}))
+	require.NoError(t, err)
+
+	first, err := b.AllowOutcome()
+	require.NoError(t, err)
+
+	second, err := b.Allow()
+	require.NoError(t, err)
+
+	_, err = b.Allow()
+	require.ErrorIs(t, err, ErrLimitExceeded)
+
+	limit, inflight := b.ConcurrencyLimit()
+	require.Equal(t, 2, limit)
+	require.Equal(t, 2, inflight)
+
+	// ignored requests release their slot without changing the limit
+	first(OutcomeIgnored)
+
+	limit, inflight = b.ConcurrencyLimit()
+	require.Equal(t, 2, limit)
+	require.Equal(t, 1, inflight)
+
+	second(true)
+
+	limit, inflight = b.ConcurrencyLimit()
+	require.Equal(t, 3, limit)
+	require.Equal(t, 0, inflight)
+
+	counts := b.counts()
+	require.Equal(t, uint64(2), counts.Requests)
+	require.Equal(t, uint64(1), counts.TotalSuccesses)
+}
+
+func TestConcurrencyLimitHalfOpen(t *testing.T) {
+	c := &testClock{
+		now: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	b, err := New(
+		WithClock(c),
+		WithTimeout(time.Minute),
+		WithMaxRequests(1),
+		WithConcurrencyLimit(AIMDLimit{Initial: 1, Max: 1}),
+	)
+	require.NoError(t, err)
+
+	first, err := b.AllowOutcome()
+	require.NoError(t, err)
+
+	b.Trip()
+	c.advance(time.Minute)
+	require.Equal(t, StateHalfOpen, b.State())
+
+	// a request rejected by the limiter does not use the half-open budget
+	_, err = b.Allow()
+	require.ErrorIs(t, err, ErrLimitExceeded)
+
+	first(OutcomeIgnored)
+
+	done, err := b.Allow()
+	require.NoError(t, err)
+	done(true)
+
+	require.Equal(t, StateClosed, b.State())
+}
+
+func TestAIMDLimit(t *testing.T) {
+	a := AIMDLimit{Initial: 10, Timeout: time.Second}.newAlgorithm()
+
+	// not enough requests in flight to grow
+	a.update(time.Millisecond, 1, false)
+	require.Equal(t, 10, a.limit())
+
+	a.update(time.Millisecond, 5, false)
+	require.Equal(t, 11, a.limit())
+
+	a.update(time.Millisecond, 5, true)
+	require.Equal(t, 9, a.limit())
+
+	a.update(2*time.Second, 5, false)
+	require.Equal(t, 8, a.limit())
+}
+
+func TestGradientLimit(t *testing.T) {
+	g := GradientLimit{Initial: 20, Smoothing: 1}.newAlgorithm()
+
+	for i := 0; i < 10; i++ {
+		g.update(10*time.Millisecond, 20, false)
+	}
+
+	// latency is steady, so the limit grows
+	require.Greater(t, g.limit(), 20)
+
+	grown := g.limit()
+
+	for i := 0; i < 5; i++ {
+		g.update(100*time.Millisecond, grown, false)
+	}
+
+	// latency rose, so the limit shrinks
+	require.Less(t, g.limit(), grown)
+	require.GreaterOrEqual(t, g.limit(), 1)
+}
@@ -0,0 +1,260 @@
+package circuitbreaker
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrInvalidConfig is returned when a Config has invalid values.
+var ErrInvalidConfig = errors.New("invalid circuit breaker config")
+
+// Duration is a time.Duration that is encoded as a string, such as "30s", in JSON and YAML.
+type Duration time.Duration
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(v)
+
+	return nil
+}
+
+// Config configures a Breaker, typically from a JSON or YAML file.
+// Zero values use the defaults of New.
+type Config struct {
+	Name        string   `json:"name,omitempty" yaml:"name,omitempty"`
+	Window      Duration `json:"window,omitempty" yaml:"window,omitempty"`
+	Timeout     Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	MaxRequests uint64   `json:"maxRequests,omitempty" yaml:"maxRequests,omitempty"`
+	// ConsecutiveFailures trips the Breaker when this many consecutive requests fail.
+	ConsecutiveFailures uint64 `json:"consecutiveFailures,omitempty" yaml:"consecutiveFailures,omitempty"`
+	// FailureRate trips the Breaker when the ratio of failures to requests in the window
+	// is at least this value. Must be between 0 and 1.
+	FailureRate float64 `json:"failureRate,omitempty" yaml:"failureRate,omitempty"`
+	// MinimumRequests is the number of requests in the window needed before FailureRate is evaluated.
+	MinimumRequests uint64 `json:"minimumRequests,omitempty" yaml:"minimumRequests,omitempty"`
+	// FailureRateConfidence compares FailureRate with the lower bound of the failure rate at this confidence,
+	// such as 0.95, rather than with the ratio itself. See TripOnFailureRateBound.
+	FailureRateConfidence float64 `json:"failureRateConfidence,omitempty" yaml:"failureRateConfidence,omitempty"`
+}
+
+// Validate returns an error wrapping ErrInvalidConfig for each invalid value.
+func (c Config) Validate() error {
+	var errs []error
+
+	if w := time.Duration(c.Window); w != 0 && (w < time.Second || w%time.Second != 0) {
+		errs = append(errs, fmt.Errorf("%w: window must be a whole number of seconds: %s", ErrInvalidConfig, w))
+	}
+
+	if c.Timeout != 0 && time.Duration(c.Timeout) < time.Second {
+		errs = append(errs, fmt.Errorf("%w: timeout must be at least one second: %s", ErrInvalidConfig, time.Duration(c.Timeout)))
+	}
+
+	if c.FailureRate < 0 || c.FailureRate > 1 {
+		errs = append(errs, fmt.Errorf("%w: failureRate must be between 0 and 1: %v", ErrInvalidConfig, c.FailureRate))
+	}
+
+	if c.MinimumRequests > 0 && c.FailureRate == 0 {
+		errs = append(errs, fmt.Errorf("%w: minimumRequests requires failureRate", ErrInvalidConfig))
+	}
+
+	if c.FailureRateConfidence < 0 || c.FailureRateConfidence >= 1 {
+		errs = append(errs, fmt.Errorf("%w: failureRateConfidence must be at least 0 and less than 1: %v", ErrInvalidConfig, c.FailureRateConfidence))
+	}
+
+	if c.FailureRateConfidence > 0 && c.FailureRate == 0 {
+		errs = append(errs, fmt.Errorf("%w: failureRateConfidence requires failureRate", ErrInvalidConfig))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Options returns the options for the Config, or an error if it is invalid.
+func (c Config) Options() ([]Option, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	var options []Option
+
+	if c.Name != "" {
+		options = append(options, WithName(c.Name))
+	}
+
+	if c.Window != 0 {
+		options = append(options, WithWindow(time.Duration(c.Window)))
+	}
+
+	if c.Timeout != 0 {
+		options = append(options, WithTimeout(time.Duration(c.Timeout)))
+	}
+
+	if c.MaxRequests != 0 {
+		options = append(options, WithMaxRequests(c.MaxRequests))
+	}
+
+	var conditions []ReadyToTrip
+
+	if c.ConsecutiveFailures > 0 {
+		conditions = append(conditions, TripOnConsecutiveFailures(c.ConsecutiveFailures))
+	}
+
+	switch {
+	case c.FailureRateConfidence > 0:
+		bound := TripOnFailureRateBound(c.FailureRate, c.FailureRateConfidence)
+		conditions = append(conditions, func(counts Counts) bool {
+			return counts.Requests >= c.MinimumRequests && bound(counts)
+		})
+	case c.FailureRate > 0:
+		conditions = append(conditions, TripOnFailureRate(c.FailureRate, c.MinimumRequests))
+	}
+
+	if len(conditions) > 0 {
+		options = append(options, WithReadyToTrip(func(counts Counts) bool {
+			for _, fn := range conditions {
+				if fn(counts) {
+					return true
+				}
+			}
+
+			return false
+		}))
+	}
+
+	return options, nil
+}
+
+// NewFromConfig creates a Breaker from the Config. The options are applied after the Config,
+// for settings such as hooks that cannot be configured from a file.
+func NewFromConfig(c Config, options ...Option) (*Breaker, error) {
+	opts, err := c.Options()
+	if err != nil {
+		return nil, err
+	}
+
+	return New(append(opts, options...)...)
+}
+
+// TripOnConsecutiveFailures returns a ReadyToTrip that returns true when at least n consecutive requests have failed.
+func TripOnConsecutiveFailures(n uint64) ReadyToTrip {
+	return func(counts Counts) bool {
+		return counts.ConsecutiveFailures >= n
+	}
+}
+
+// TripOnFailureRate returns a ReadyToTrip that returns true when there are at least minimumRequests requests
+// and the ratio of failures to requests is at least rate.
+func TripOnFailureRate(rate float64, minimumRequests uint64) ReadyToTrip {
+	return func(counts Counts) bool {
+		if counts.Requests == 0 || counts.Requests < minimumRequests {
+			return false
+		}
+
+		return float64(counts.TotalFailures)/float64(counts.Requests) >= rate
+	}
+}
+
+// TripOnFailureRateBound returns a ReadyToTrip that returns true when the lower bound of the Wilson score interval
+// for the failure rate, at confidence, is at least rate. A few failures among few outcomes have a wide interval,
+// so low-traffic breakers need more evidence to trip and a minimum number of requests is rarely needed.
+// Confidence is one-sided, such as 0.95.
+func TripOnFailureRateBound(rate float64, confidence float64) ReadyToTrip {
+	z := math.Sqrt2 * math.Erfinv(2*confidence-1)
+
+	return func(counts Counts) bool {
+		return WilsonLowerBound(counts.TotalFailures, counts.TotalSuccesses+counts.TotalFailures, z) >= rate
+	}
+}
+
+// WilsonLowerBound returns the lower bound of the Wilson score interval for the proportion of n trials
+// that were positive, for the normal quantile z, such as 1.645 for one-sided 95% confidence. It is 0 when n is 0.
+func WilsonLowerBound(positive uint64, n uint64, z float64) float64 {
+	if n == 0 {
+		return 0
+	}
+
+	p := float64(positive) / float64(n)
+	z2n := z * z / float64(n)
+
+	return (p + z2n/2 - z*math.Sqrt(p*(1-p)/float64(n)+z2n/float64(4*n))) / (1 + z2n)
+}
+
+// RegistryConfig declares many Breakers, typically from a JSON or YAML file.
+type RegistryConfig struct {
+	// Breakers holds the Config of each Breaker by name.
+	Breakers map[string]Config `json:"breakers,omitempty" yaml:"breakers,omitempty"`
+	// Defaults provides the values that are not set in a Breaker's Config.
+	Defaults Config `json:"defaults,omitempty" yaml:"defaults,omitempty"`
+}
+
+// ParseRegistryConfig decodes a RegistryConfig from YAML or JSON. Unknown fields are an error.
+func ParseRegistryConfig(r io.Reader) (RegistryConfig, error) {
+	var c RegistryConfig
+
+	d := yaml.NewDecoder(r)
+	d.KnownFields(true)
+
+	if err := d.Decode(&c); err != nil && !errors.Is(err, io.EOF) {
+		return RegistryConfig{}, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+
+	return c, nil
+}
+
+// Configs returns the Config of each Breaker by name, with the defaults applied.
+func (c RegistryConfig) Configs() map[string]Config {
+	configs := make(map[string]Config, len(c.Breakers))
+
+	for name, config := range c.Breakers {
+		configs[name] = config.withDefaults(c.Defaults)
+	}
+
+	return configs
+}
+
+// withDefaults returns c with the zero values replaced by the values in defaults.
+func (c Config) withDefaults(defaults Config) Config {
+	if c.Window == 0 {
+		c.Window = defaults.Window
+	}
+
+	if c.Timeout == 0 {
+		c.Timeout = defaults.Timeout
+	}
+
+	if c.MaxRequests == 0 {
+		c.MaxRequests = defaults.MaxRequests
+	}
+
+	if c.ConsecutiveFailures == 0 {
+		c.ConsecutiveFailures = defaults.ConsecutiveFailures
+	}
+
+	if c.FailureRate == 0 {
+		c.FailureRate = defaults.FailureRate
+	}
+
+	if c.MinimumRequests == 0 {
+		c.MinimumRequests = defaults.MinimumRequests
+	}
+
+	if c.FailureRateConfidence == 0 {
+		c.FailureRateConfidence = defaults.FailureRateConfidence
+	}
+
+	return c
+}
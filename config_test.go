@@ -0,0 +1,102 @@
+package circuitbreaker
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFromConfig(t *testing.T) {
+	var c Config
+
+	err := json.Unmarshal([]byte(`{
+		"name": "test",
+		"window": "30s",
+		"timeout": "1m",
+		"maxRequests": 3,
+		"consecutiveFailures": 2
+	}`), &c)
+	require.NoError(t, err)
+
+	b, err := NewFromConfig(c)
+	require.NoError(t, err)
+
+	status := b.Status()
+	require.Equal(t, "test", status.Name)
+	require.Equal(t, StatusOptions{Window: 30 * time.Second, Timeout: time.Minute, MaxRequests: 3}, status.Options)
+
+	record(t, b, false)
+	require.Equal(t, StateClosed, b.State())
+
+	record(t, b, false)
+	require.Equal(t, StateOpen, b.State())
+
+	data, err := json.Marshal(c)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"name":"test","window":"30s","timeout":"1m0s","maxRequests":3,"consecutiveFailures":2}`, string(data))
+}
+
+func TestConfigValidate(t *testing.T) {
+	c := Config{
+		Window:          Duration(time.Millisecond),
+		Timeout:         Duration(-time.Second),
+		FailureRate:     1.5,
+		MinimumRequests: 10,
+	}
+
+	_, err := NewFromConfig(c)
+	require.ErrorIs(t, err, ErrInvalidConfig)
+	require.ErrorContains(t, err, "window")
+	require.ErrorContains(t, err, "timeout")
+	require.ErrorContains(t, err, "failureRate")
+
+	require.NoError(t, Config{}.Validate())
+
+	// a window that is not a whole number of seconds is invalid config, not an invalid option
+	_, err = NewFromConfig(Config{Window: Duration(1500 * time.Millisecond)})
+	require.ErrorIs(t, err, ErrInvalidConfig)
+	require.NotErrorIs(t, err, ErrInvalidOption)
+	require.ErrorContains(t, err, "window")
+}
+
+func TestTripOnFailureRate(t *testing.T) {
+	fn := TripOnFailureRate(0.5, 4)
+
+	require.False(t, fn(Counts{}))
+	require.False(t, fn(Counts{Requests: 2, TotalFailures: 2}))
+	require.False(t, fn(Counts{Requests: 4, TotalFailures: 1}))
+	require.True(t, fn(Counts{Requests: 4, TotalFailures: 2}))
+}
+
+func TestTripOnFailureRateBound(t *testing.T) {
+	fn := TripOnFailureRateBound(0.5, 0.95)
+
+	counts := func(successes uint64, failures uint64) Counts {
+		return Counts{Requests: successes + failures, TotalSuccesses: successes, TotalFailures: failures}
+	}
+
+	require.False(t, fn(Counts{}))
+	// too little evidence
+	require.False(t, fn(counts(0, 2)))
+	require.True(t, fn(counts(0, 3)))
+	require.False(t, fn(counts(10, 10)))
+	require.True(t, fn(counts(5, 15)))
+
+	b, err := NewFromConfig(Config{FailureRate: 0.5, FailureRateConfidence: 0.95})
+	require.NoError(t, err)
+
+	record(t, b, false)
+	record(t, b, false)
+	require.Equal(t, StateClosed, b.State())
+
+	record(t, b, false)
+	require.Equal(t, StateOpen, b.State())
+
+	_, err = NewFromConfig(Config{FailureRateConfidence: 0.95})
+	require.ErrorContains(t, err, "failureRateConfidence requires failureRate")
+
+	require.Zero(t, WilsonLowerBound(0, 0, 1.645))
+	require.InDelta(t, 0.568, WilsonLowerBound(15, 20, 1.645), 0.001)
+}
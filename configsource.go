@@ -0,0 +1,202 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"time"
+)
+
+// ConfigSource provides the configuration of Breakers by name.
+type ConfigSource interface {
+	// Get returns the Config of each Breaker, by name.
+	Get(ctx context.Context) (map[string]Config, error)
+	// Watch returns a channel that receives a value whenever the configuration may have changed.
+	// The channel is closed when ctx is done.
+	Watch(ctx context.Context) (<-chan struct{}, error)
+}
+
+// FileConfigSource is a ConfigSource that reads a RegistryConfig from a YAML or JSON file.
+// It watches for changes by polling the file. Polling uses the time package rather than a Clock,
+// as it is not part of a Breaker and the modification times it compares are set by the file system.
+type FileConfigSource struct {
+	path         string
+	pollInterval time.Duration
+}
+
+var _ ConfigSource = &FileConfigSource{}
+
+// NewFileConfigSource creates a FileConfigSource that reads the file at path.
+// Watch checks for changes at the poll interval, which defaults to one second.
+func NewFileConfigSource(path string, pollInterval time.Duration) *FileConfigSource {
+	if pollInterval <= 0 {
+		pollInterval = time.Second
+	}
+
+	return &FileConfigSource{
+		path:         path,
+		pollInterval: pollInterval,
+	}
+}
+
+// Get implements ConfigSource.
+func (f *FileConfigSource) Get(_ context.Context) (map[string]Config, error) {
+	file, err := os.Open(f.path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	c, err := ParseRegistryConfig(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", f.path, err)
+	}
+
+	return c.Configs(), nil
+}
+
+// Watch implements ConfigSource.
+func (f *FileConfigSource) Watch(ctx context.Context) (<-chan struct{}, error) {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return nil, err
+	}
+
+	last := info.ModTime()
+
+	changes := make(chan struct{}, 1)
+
+	go func() {
+		defer close(changes)
+
+		ticker := time.NewTicker(f.pollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			info, err := os.Stat(f.path)
+			if err != nil || info.ModTime().Equal(last) {
+				continue
+			}
+
+			last = info.ModTime()
+
+			select {
+			case changes <- struct{}{}:
+			default:
+			}
+		}
+	}()
+
+	return changes, nil
+}
+
+// ApplyConfig creates and registers a Breaker for each Config with a name that is not registered,
+// using the options after the Config. Registered Breakers are updated using UpdateOptions, keeping their state and counts;
+// zero values in the Config leave the current values unchanged.
+// Registered Breakers without a Config are not changed.
+// Every Config is applied even if some are invalid, and an error is returned for each invalid Config.
+func (r *Registry) ApplyConfig(configs map[string]Config, options ...Option) error {
+	names := make([]string, 0, len(configs))
+	for name := range configs {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	var errs []error
+
+	for _, name := range names {
+		if err := r.applyConfig(name, configs[name], options); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (r *Registry) applyConfig(name string, c Config, options []Option) error {
+	c.Name = ""
+
+	opts, err := c.Options()
+	if err != nil {
+		return err
+	}
+
+	if b, ok := r.Get(name); ok {
+		return b.UpdateOptions(opts...)
+	}
+
+	opts = append(opts, WithName(name))
+
+	b, err := New(append(opts, options...)...)
+	if err != nil {
+		return err
+	}
+
+	if err := r.Register(b); err != nil {
+		_ = b.Close()
+		return err
+	}
+
+	return nil
+}
+
+// LoadConfig reads a RegistryConfig in YAML or JSON from rd and applies it using ApplyConfig.
+func (r *Registry) LoadConfig(rd io.Reader, options ...Option) error {
+	c, err := ParseRegistryConfig(rd)
+	if err != nil {
+		return err
+	}
+
+	return r.ApplyConfig(c.Configs(), options...)
+}
+
+// WatchConfig applies the configuration from src using ApplyConfig, and again whenever it changes, until ctx is done.
+// An error applying the initial configuration is returned. Later errors are logged using the logger
+// set by WithLogger in options, if any, and the configuration is applied again on the next change.
+func (r *Registry) WatchConfig(ctx context.Context, src ConfigSource, options ...Option) error {
+	var opts Options
+	for _, o := range options {
+		o(&opts)
+	}
+
+	configs, err := src.Get(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := r.ApplyConfig(configs, options...); err != nil {
+		return err
+	}
+
+	changes, err := src.Watch(ctx)
+	if err != nil {
+		return err
+	}
+
+	for range changes {
+		configs, err := src.Get(ctx)
+		if err == nil {
+			err = r.ApplyConfig(configs, options...)
+		}
+
+		if err != nil && opts.logger != nil {
+			opts.logger.LogAttrs(ctx, slog.LevelError, "failed to apply circuit breaker config",
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+
+	return ctx.Err()
+}
@@ -0,0 +1,108 @@
+package circuitbreaker
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "breakers.json")
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"breakers": {"a": {"timeout": "30s"}, "b": {"maxRequests": 2}}}`), 0o600))
+
+	r := NewRegistry()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	errs := make(chan error, 1)
+
+	go func() {
+		errs <- r.WatchConfig(ctx, NewFileConfigSource(path, 10*time.Millisecond))
+	}()
+
+	require.Eventually(t, func() bool {
+		return len(r.Breakers()) == 2
+	}, time.Second, time.Millisecond)
+
+	a, ok := r.Get("a")
+	require.True(t, ok)
+	require.Equal(t, 30*time.Second, a.Status().Options.Timeout)
+
+	record(t, a, false)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"breakers": {"a": {"timeout": "1m", "consecutiveFailures": 2}}}`), 0o600))
+	// make sure the modification time changes
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Second)))
+
+	require.Eventually(t, func() bool {
+		return a.Status().Options.Timeout == time.Minute
+	}, time.Second, time.Millisecond)
+
+	// the counts are kept
+	record(t, a, false)
+	require.Equal(t, StateOpen, a.State())
+
+	cancel()
+	require.ErrorIs(t, <-errs, context.Canceled)
+}
+
+func TestApplyConfigInvalid(t *testing.T) {
+	r := NewRegistry()
+
+	err := r.ApplyConfig(map[string]Config{
+		"a": {FailureRate: 2},
+		"b": {},
+	})
+	require.ErrorIs(t, err, ErrInvalidConfig)
+	require.ErrorContains(t, err, "a: ")
+
+	_, ok := r.Get("a")
+	require.False(t, ok)
+
+	_, ok = r.Get("b")
+	require.True(t, ok)
+}
+
+func TestLoadConfig(t *testing.T) {
+	r := NewRegistry()
+
+	err := r.LoadConfig(strings.NewReader(`
+defaults:
+  window: 30s
+  timeout: 10s
+  consecutiveFailures: 5
+breakers:
+  payments:
+    timeout: 1m
+    failureRate: 0.5
+    minimumRequests: 20
+  search: {}
+`))
+	require.NoError(t, err)
+
+	payments, ok := r.Get("payments")
+	require.True(t, ok)
+	require.Equal(t, StatusOptions{Window: 30 * time.Second, Timeout: time.Minute, MaxRequests: 1}, payments.Status().Options)
+
+	search, ok := r.Get("search")
+	require.True(t, ok)
+	require.Equal(t, StatusOptions{Window: 30 * time.Second, Timeout: 10 * time.Second, MaxRequests: 1}, search.Status().Options)
+
+	for i := 0; i < 5; i++ {
+		record(t, search, false)
+	}
+
+	require.Equal(t, StateOpen, search.State())
+}
+
+func TestLoadConfigUnknownField(t *testing.T) {
+	err := NewRegistry().LoadConfig(strings.NewReader(`{"breakers": {"a": {"timout": "1m"}}}`))
+	require.ErrorIs(t, err, ErrInvalidConfig)
+	require.ErrorContains(t, err, "timout")
+}
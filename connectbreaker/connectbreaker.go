@@ -0,0 +1,271 @@
+// Package connectbreaker provides a connect-go interceptor for circuit breakers.
+package connectbreaker
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+
+	"connectrpc.com/connect"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+// Classifier returns the outcome of an RPC that failed with the code.
+// RPCs that complete without an error are always successes.
+type Classifier func(connect.Code) circuitbreaker.Outcome
+
+// DefaultClassifier records Unavailable, DeadlineExceeded, ResourceExhausted, Internal, and Unknown as failures.
+// Canceled and InvalidArgument are ignored. All other codes are successes.
+func DefaultClassifier(code connect.Code) circuitbreaker.Outcome {
+	switch code {
+	case connect.CodeUnavailable, connect.CodeDeadlineExceeded, connect.CodeResourceExhausted, connect.CodeInternal, connect.CodeUnknown:
+		return circuitbreaker.OutcomeFailure
+	case connect.CodeCanceled, connect.CodeInvalidArgument:
+		return circuitbreaker.OutcomeIgnored
+	default:
+		return circuitbreaker.OutcomeSuccess
+	}
+}
+
+// Codes returns a Classifier that records the failure codes as failures and the ignored
+// codes as ignored. All other codes are successes.
+func Codes(failures []connect.Code, ignored []connect.Code) Classifier {
+	outcomes := make(map[connect.Code]circuitbreaker.Outcome, len(failures)+len(ignored))
+
+	for _, c := range failures {
+		outcomes[c] = circuitbreaker.OutcomeFailure
+	}
+
+	for _, c := range ignored {
+		outcomes[c] = circuitbreaker.OutcomeIgnored
+	}
+
+	return func(code connect.Code) circuitbreaker.Outcome {
+		if o, ok := outcomes[code]; ok {
+			return o
+		}
+
+		return circuitbreaker.OutcomeSuccess
+	}
+}
+
+func (c Classifier) classify(err error) circuitbreaker.Outcome {
+	if err == nil {
+		return circuitbreaker.OutcomeSuccess
+	}
+
+	return c(connect.CodeOf(err))
+}
+
+// KeyFunc returns the key used to choose the breaker for an RPC from a Group.
+type KeyFunc func(ctx context.Context, spec connect.Spec) string
+
+// ProcedureKey returns the procedure name, such as "/package.Service/Method".
+func ProcedureKey(_ context.Context, spec connect.Spec) string {
+	return spec.Procedure
+}
+
+type options struct {
+	classifier Classifier
+	keyFunc    KeyFunc
+	rejectCode connect.Code
+}
+
+// Option sets Interceptor options.
+type Option func(*options)
+
+// WithClassifier sets the classifier used to record the outcome of RPCs.
+// Default is DefaultClassifier.
+func WithClassifier(c Classifier) Option {
+	return func(o *options) {
+		o.classifier = c
+	}
+}
+
+// WithKeyFunc sets the function used to choose the breaker for an RPC when using a Group.
+// Default is ProcedureKey.
+func WithKeyFunc(fn KeyFunc) Option {
+	return func(o *options) {
+		o.keyFunc = fn
+	}
+}
+
+// WithRejectCode sets the code returned when the breaker does not allow an RPC.
+// Default is connect.CodeUnavailable.
+func WithRejectCode(code connect.Code) Option {
+	return func(o *options) {
+		o.rejectCode = code
+	}
+}
+
+// Interceptor is a connect.Interceptor that runs RPCs through circuit breakers.
+// It may be used by both clients and handlers.
+type Interceptor struct {
+	breaker func(context.Context, connect.Spec) *circuitbreaker.Breaker
+	options options
+}
+
+var _ connect.Interceptor = (*Interceptor)(nil)
+
+func newOptions(opts []Option) options {
+	o := options{
+		classifier: DefaultClassifier,
+		keyFunc:    ProcedureKey,
+		rejectCode: connect.CodeUnavailable,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// NewInterceptor creates an Interceptor that runs each RPC through the breaker.
+// When the breaker does not allow an RPC, an error is returned with the code set by WithRejectCode,
+// see Error.
+func NewInterceptor(b *circuitbreaker.Breaker, opts ...Option) *Interceptor {
+	return &Interceptor{
+		breaker: func(context.Context, connect.Spec) *circuitbreaker.Breaker {
+			return b
+		},
+		options: newOptions(opts),
+	}
+}
+
+// NewGroupInterceptor creates an Interceptor that runs each RPC through a breaker from the group,
+// so that a failing procedure does not open the breaker for every procedure. See WithKeyFunc.
+// Otherwise, it behaves like NewInterceptor.
+func NewGroupInterceptor(g *circuitbreaker.Group[string], opts ...Option) *Interceptor {
+	o := newOptions(opts)
+
+	return &Interceptor{
+		breaker: func(ctx context.Context, spec connect.Spec) *circuitbreaker.Breaker {
+			return g.Get(o.keyFunc(ctx, spec))
+		},
+		options: o,
+	}
+}
+
+// WrapUnary implements connect.Interceptor.
+func (i *Interceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		b := i.breaker(ctx, req.Spec())
+
+		done, err := b.AllowOutcome()
+		if err != nil {
+			return nil, Error(b, i.options.rejectCode, err)
+		}
+
+		resp, err := next(ctx, req)
+
+		done(i.options.classifier.classify(err))
+
+		return resp, err
+	}
+}
+
+// WrapStreamingClient implements connect.Interceptor. The outcome is recorded when the
+// stream ends: when Receive returns an error or io.EOF, or the response is closed.
+func (i *Interceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return func(ctx context.Context, spec connect.Spec) connect.StreamingClientConn {
+		b := i.breaker(ctx, spec)
+
+		done, err := b.AllowOutcome()
+		if err != nil {
+			return &rejectedConn{
+				spec: spec,
+				err:  Error(b, i.options.rejectCode, err),
+			}
+		}
+
+		return &clientConn{
+			StreamingClientConn: next(ctx, spec),
+			done:                done,
+			classifier:          i.options.classifier,
+		}
+	}
+}
+
+// WrapStreamingHandler implements connect.Interceptor.
+func (i *Interceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		b := i.breaker(ctx, conn.Spec())
+
+		done, err := b.AllowOutcome()
+		if err != nil {
+			return Error(b, i.options.rejectCode, err)
+		}
+
+		err = next(ctx, conn)
+
+		done(i.options.classifier.classify(err))
+
+		return err
+	}
+}
+
+// Error converts an error returned by the breaker into a connect error with the code.
+// When the breaker is open, the error includes a RetryInfo detail with the time remaining
+// until it becomes half-open.
+func Error(b *circuitbreaker.Breaker, code connect.Code, err error) *connect.Error {
+	cerr := connect.NewError(code, err)
+
+	if d := b.Status().RetryAfter; d > 0 {
+		if detail, err := connect.NewErrorDetail(&errdetails.RetryInfo{RetryDelay: durationpb.New(d)}); err == nil {
+			cerr.AddDetail(detail)
+		}
+	}
+
+	return cerr
+}
+
+type clientConn struct {
+	connect.StreamingClientConn
+	done       func(circuitbreaker.Outcome)
+	classifier Classifier
+	once       sync.Once
+}
+
+func (c *clientConn) Receive(m any) error {
+	err := c.StreamingClientConn.Receive(m)
+
+	switch {
+	case err == nil:
+	case errors.Is(err, io.EOF):
+		c.once.Do(func() { c.done(circuitbreaker.OutcomeSuccess) })
+	default:
+		c.once.Do(func() { c.done(c.classifier.classify(err)) })
+	}
+
+	return err
+}
+
+func (c *clientConn) CloseResponse() error {
+	err := c.StreamingClientConn.CloseResponse()
+
+	c.once.Do(func() { c.done(c.classifier.classify(err)) })
+
+	return err
+}
+
+// rejectedConn is returned when the breaker does not allow a stream.
+type rejectedConn struct {
+	err  error
+	spec connect.Spec
+}
+
+func (c *rejectedConn) Spec() connect.Spec           { return c.spec }
+func (c *rejectedConn) Peer() connect.Peer           { return connect.Peer{} }
+func (c *rejectedConn) Send(any) error               { return c.err }
+func (c *rejectedConn) RequestHeader() http.Header   { return http.Header{} }
+func (c *rejectedConn) CloseRequest() error          { return c.err }
+func (c *rejectedConn) Receive(any) error            { return c.err }
+func (c *rejectedConn) ResponseHeader() http.Header  { return http.Header{} }
+func (c *rejectedConn) ResponseTrailer() http.Header { return http.Header{} }
+func (c *rejectedConn) CloseResponse() error         { return nil }
@@ -0,0 +1,148 @@
+package connectbreaker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+const (
+	unaryProcedure  = "/test.v1.TestService/Unary"
+	streamProcedure = "/test.v1.TestService/Stream"
+)
+
+func alwaysTrip(circuitbreaker.Counts) bool {
+	return true
+}
+
+// newTestServer returns a server whose procedures return the error set on the returned pointer.
+func newTestServer(t *testing.T, opts ...connect.HandlerOption) (*httptest.Server, *error) {
+	var err error
+
+	mux := http.NewServeMux()
+
+	mux.Handle(unaryProcedure, connect.NewUnaryHandler(unaryProcedure,
+		func(context.Context, *connect.Request[emptypb.Empty]) (*connect.Response[emptypb.Empty], error) {
+			if err != nil {
+				return nil, err
+			}
+
+			return connect.NewResponse(&emptypb.Empty{}), nil
+		}, opts...))
+
+	mux.Handle(streamProcedure, connect.NewServerStreamHandler(streamProcedure,
+		func(_ context.Context, _ *connect.Request[emptypb.Empty], stream *connect.ServerStream[emptypb.Empty]) error {
+			if err != nil {
+				return err
+			}
+
+			return stream.Send(&emptypb.Empty{})
+		}, opts...))
+
+	svr := httptest.NewUnstartedServer(mux)
+	svr.EnableHTTP2 = true
+	svr.StartTLS()
+
+	t.Cleanup(svr.Close)
+
+	return svr, &err
+}
+
+func TestClientInterceptor(t *testing.T) {
+	svr, serverErr := newTestServer(t)
+
+	b, err := circuitbreaker.New(
+		circuitbreaker.WithReadyToTrip(alwaysTrip),
+		circuitbreaker.WithTimeout(time.Minute),
+		circuitbreaker.WithWindow(time.Minute),
+	)
+	require.NoError(t, err)
+
+	i := NewInterceptor(b)
+
+	unary := connect.NewClient[emptypb.Empty, emptypb.Empty](svr.Client(), svr.URL+unaryProcedure, connect.WithInterceptors(i))
+	stream := connect.NewClient[emptypb.Empty, emptypb.Empty](svr.Client(), svr.URL+streamProcedure, connect.WithInterceptors(i))
+
+	ctx := context.Background()
+
+	_, err = unary.CallUnary(ctx, connect.NewRequest(&emptypb.Empty{}))
+	require.NoError(t, err)
+
+	s, err := stream.CallServerStream(ctx, connect.NewRequest(&emptypb.Empty{}))
+	require.NoError(t, err)
+
+	for s.Receive() {
+	}
+
+	require.NoError(t, s.Err())
+	require.NoError(t, s.Close())
+	require.Equal(t, uint64(2), b.Status().Counts.TotalSuccesses)
+
+	*serverErr = connect.NewError(connect.CodeInvalidArgument, nil)
+
+	_, err = unary.CallUnary(ctx, connect.NewRequest(&emptypb.Empty{}))
+	require.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+	require.Equal(t, circuitbreaker.StateClosed, b.State())
+
+	*serverErr = connect.NewError(connect.CodeUnavailable, nil)
+
+	s, err = stream.CallServerStream(ctx, connect.NewRequest(&emptypb.Empty{}))
+	require.NoError(t, err)
+	require.False(t, s.Receive())
+	require.Equal(t, connect.CodeUnavailable, connect.CodeOf(s.Err()))
+	require.Equal(t, circuitbreaker.StateOpen, b.State())
+
+	_, err = unary.CallUnary(ctx, connect.NewRequest(&emptypb.Empty{}))
+	require.Equal(t, connect.CodeUnavailable, connect.CodeOf(err))
+	require.ErrorIs(t, err, circuitbreaker.ErrOpenState)
+
+	var cerr *connect.Error
+	require.ErrorAs(t, err, &cerr)
+	require.Len(t, cerr.Details(), 1)
+
+	detail, err := cerr.Details()[0].Value()
+	require.NoError(t, err)
+	require.IsType(t, &errdetails.RetryInfo{}, detail)
+
+	_, err = stream.CallServerStream(ctx, connect.NewRequest(&emptypb.Empty{}))
+	require.ErrorIs(t, err, circuitbreaker.ErrOpenState)
+}
+
+func TestGroupHandlerInterceptor(t *testing.T) {
+	g, err := circuitbreaker.NewGroup[string](circuitbreaker.WithReadyToTrip(alwaysTrip))
+	require.NoError(t, err)
+
+	svr, serverErr := newTestServer(t, connect.WithInterceptors(NewGroupInterceptor(g, WithRejectCode(connect.CodeResourceExhausted))))
+
+	unary := connect.NewClient[emptypb.Empty, emptypb.Empty](svr.Client(), svr.URL+unaryProcedure)
+	stream := connect.NewClient[emptypb.Empty, emptypb.Empty](svr.Client(), svr.URL+streamProcedure)
+
+	ctx := context.Background()
+
+	*serverErr = connect.NewError(connect.CodeInternal, nil)
+
+	_, err = unary.CallUnary(ctx, connect.NewRequest(&emptypb.Empty{}))
+	require.Equal(t, connect.CodeInternal, connect.CodeOf(err))
+
+	*serverErr = nil
+
+	_, err = unary.CallUnary(ctx, connect.NewRequest(&emptypb.Empty{}))
+	require.Equal(t, connect.CodeResourceExhausted, connect.CodeOf(err))
+
+	s, err := stream.CallServerStream(ctx, connect.NewRequest(&emptypb.Empty{}))
+	require.NoError(t, err)
+	require.True(t, s.Receive())
+	require.NoError(t, s.Close())
+
+	require.Equal(t, circuitbreaker.StateOpen, g.Get(unaryProcedure).State())
+	require.Equal(t, circuitbreaker.StateClosed, g.Get(streamProcedure).State())
+}
@@ -0,0 +1,20 @@
+module github.com/bakins/circuitbreaker/connectbreaker
+
+go 1.25.0
+
+require (
+	connectrpc.com/connect v1.19.1
+	github.com/bakins/circuitbreaker v0.0.0
+	github.com/stretchr/testify v1.12.1
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20260414002931-afd174a4e478
+	google.golang.org/protobuf v1.36.12
+)
+
+require (
+	github.com/kr/text v0.2.0 // indirect
+	go.yaml.in/yaml/v3 v3.0.5 // indirect
+	golang.org/x/time v0.10.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/bakins/circuitbreaker => ../
@@ -0,0 +1,66 @@
+package circuitbreaker
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+type (
+	contextKey struct{}
+	requestKey struct{}
+)
+
+// request is a request started by Begin.
+type request struct {
+	done  func(Outcome)
+	ended atomic.Bool
+}
+
+// NewContext returns a copy of ctx that carries b, so code handling a request can find its Breaker with FromContext.
+func NewContext(ctx context.Context, b *Breaker) context.Context {
+	return context.WithValue(ctx, contextKey{}, b)
+}
+
+// FromContext returns the Breaker carried by ctx, if any.
+func FromContext(ctx context.Context) (*Breaker, bool) {
+	b, ok := ctx.Value(contextKey{}).(*Breaker)
+	return b, ok && b != nil
+}
+
+// ExecuteFromContext runs fn using Execute on the Breaker carried by ctx.
+// If ctx does not carry a Breaker, fn is run directly.
+func ExecuteFromContext(ctx context.Context, fn func(ctx context.Context) error) error {
+	b, ok := FromContext(ctx)
+	if !ok {
+		return fn(ctx)
+	}
+
+	return b.Execute(ctx, fn)
+}
+
+// Begin is like AllowOutcome, but returns a copy of ctx that carries the allowed request rather than a callback,
+// so that the request can be started and finished in different layers, such as middleware and an interceptor.
+// End must be called with the returned context to record the outcome.
+func (b *Breaker) Begin(ctx context.Context) (context.Context, error) {
+	if err := b.checkDeadline(ctx); err != nil {
+		return ctx, err
+	}
+
+	done, err := b.AllowOutcome()
+	if err != nil {
+		return ctx, err
+	}
+
+	return context.WithValue(ctx, requestKey{}, &request{done: done}), nil
+}
+
+// End records the outcome of the request started by Begin and carried by ctx, as Execute would for err.
+// End does nothing if ctx does not carry a request or the request has already ended.
+func End(ctx context.Context, err error) {
+	r, ok := ctx.Value(requestKey{}).(*request)
+	if !ok || !r.ended.CompareAndSwap(false, true) {
+		return
+	}
+
+	r.done(outcomeOf(ctx, err))
+}
@@ -0,0 +1,63 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestContext(t *testing.T) {
+	b, err := New(WithWindow(time.Minute))
+	require.NoError(t, err)
+
+	_, ok := FromContext(context.Background())
+	require.False(t, ok)
+
+	// without a breaker, the function is run directly
+	fail := errors.New("fail")
+	require.ErrorIs(t, ExecuteFromContext(context.Background(), func(context.Context) error { return fail }), fail)
+
+	ctx := NewContext(context.Background(), b)
+
+	found, ok := FromContext(ctx)
+	require.True(t, ok)
+	require.Same(t, b, found)
+
+	require.ErrorIs(t, ExecuteFromContext(ctx, func(context.Context) error { return fail }), fail)
+	require.Equal(t, uint64(1), b.Status().Counts.TotalFailures)
+
+	b.Trip()
+	require.ErrorIs(t, ExecuteFromContext(ctx, func(context.Context) error { return nil }), ErrOpenState)
+}
+
+func TestBeginEnd(t *testing.T) {
+	b, err := New(WithWindow(time.Minute))
+	require.NoError(t, err)
+
+	// without a request, End does nothing
+	End(context.Background(), errors.New("fail"))
+
+	ctx, err := b.Begin(context.Background())
+	require.NoError(t, err)
+
+	End(ctx, errors.New("fail"))
+	End(ctx, nil)
+
+	counts := b.Status().Counts
+	require.Equal(t, uint64(1), counts.TotalFailures)
+	require.Equal(t, uint64(0), counts.TotalSuccesses)
+
+	ctx, err = b.Begin(context.Background())
+	require.NoError(t, err)
+
+	End(ctx, nil)
+	require.Equal(t, uint64(1), b.Status().Counts.TotalSuccesses)
+
+	b.Trip()
+
+	_, err = b.Begin(context.Background())
+	require.ErrorIs(t, err, ErrOpenState)
+}
@@ -0,0 +1,47 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrInsufficientDeadline is returned by Execute and Begin when the deadline of the context is too soon
+// for the request to finish, see WithDeadlineAdmission.
+var ErrInsufficientDeadline = errors.New("circuit breaker deadline is too short for request")
+
+// WithDeadlineAdmission rejects requests made by Execute and Begin with ErrInsufficientDeadline when the time left
+// before the deadline of their context is less than the 95th percentile latency in the window, rather than
+// admitting requests that cannot finish in time. The percentile is estimated from the latencies sampled by
+// WithLatencySampling, which is required, at most once a second. Requests without a deadline are not rejected.
+// There is no default.
+func WithDeadlineAdmission() Option {
+	return func(o *Options) {
+		o.deadlines = true
+	}
+}
+
+// checkDeadline returns ErrInsufficientDeadline if deadline admission is used and ctx cannot finish in time.
+func (b *Breaker) checkDeadline(ctx context.Context) error {
+	if !b.options.deadlines {
+		return nil
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return nil
+	}
+
+	now := b.options.clock.Now()
+
+	if deadline.Sub(now) >= b.latency.percentile95(now, now.Add(-b.buckets.Window())) {
+		return nil
+	}
+
+	b.logRejection(b.loadState(), ErrInsufficientDeadline)
+
+	if b.events.wants(EventRejection) {
+		b.events.emit(Event{Kind: EventRejection, Time: now, State: b.loadState(), Err: ErrInsufficientDeadline})
+	}
+
+	return ErrInsufficientDeadline
+}
@@ -0,0 +1,49 @@
+package circuitbreaker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeadlineAdmission(t *testing.T) {
+	b, err := New(WithWindow(time.Minute), WithLatencySampling(1), WithDeadlineAdmission())
+	require.NoError(t, err)
+
+	slow := func(context.Context) error {
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	}
+
+	// there is no estimate yet
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	require.NoError(t, b.Execute(ctx, func(context.Context) error { return nil }))
+
+	b, err = New(WithWindow(time.Minute), WithLatencySampling(1), WithDeadlineAdmission())
+	require.NoError(t, err)
+
+	for range 5 {
+		require.NoError(t, b.Execute(context.Background(), slow))
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+
+	err = b.Execute(ctx, func(context.Context) error { return nil })
+	require.ErrorIs(t, err, ErrInsufficientDeadline)
+
+	_, err = b.Begin(ctx)
+	require.ErrorIs(t, err, ErrInsufficientDeadline)
+
+	// requests with enough time, or no deadline, are admitted
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, b.Execute(ctx, func(context.Context) error { return nil }))
+	require.NoError(t, b.Execute(context.Background(), func(context.Context) error { return nil }))
+
+	_, err = New(WithDeadlineAdmission())
+	require.ErrorIs(t, err, ErrInvalidOption)
+}
@@ -0,0 +1,32 @@
+package circuitbreaker
+
+// WithDegraded enables StateDegraded. When a request fails in the closed state and the Breaker does not trip,
+// it becomes degraded if condition returns true for the counts, and a degraded Breaker closes again when
+// condition returns false after a request completes. A degraded Breaker allows requests and trips like a closed
+// one, so callers can check for it, such as with Token.State, to switch to cheaper code paths before it trips.
+// condition should return true for lower failure rates than the trip conditions.
+// There is no default.
+func WithDegraded(condition ReadyToTrip) Option {
+	return func(o *Options) {
+		o.degraded = condition
+	}
+}
+
+// closed reports whether s allows requests like StateClosed.
+func closed(s State) bool {
+	return s == StateClosed || s == StateDegraded
+}
+
+// degrade switches between the closed and degraded states. It must be called with lock.
+func (b *Breaker) degrade(state State, counts Counts) {
+	if b.options.degraded == nil {
+		return
+	}
+
+	switch degraded := b.options.degraded(counts); {
+	case degraded && state == StateClosed:
+		b.switchState(state, StateDegraded, ReasonDegraded, "")
+	case !degraded && state == StateDegraded:
+		b.switchState(state, StateClosed, ReasonRecovered, "")
+	}
+}
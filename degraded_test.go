@@ -0,0 +1,63 @@
+package circuitbreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDegraded(t *testing.T) {
+	var transitions []Transition
+
+	b, err := New(
+		WithWindow(time.Minute),
+		WithReadyToTrip(TripOnFailureRate(0.5, 4)),
+		WithDegraded(TripOnFailureRate(0.2, 4)),
+		WithOnTransition(func(t Transition) { transitions = append(transitions, t) }),
+		WithSynchronousObservers(),
+	)
+	require.NoError(t, err)
+
+	result := func(success bool) State {
+		token, err := b.AllowToken()
+		require.NoError(t, err)
+
+		state := token.State()
+
+		if success {
+			token.Success()
+		} else {
+			token.Failure()
+		}
+
+		return state
+	}
+
+	for i := 0; i < 4; i++ {
+		require.Equal(t, StateClosed, result(true))
+	}
+
+	result(false)
+	require.Equal(t, StateDegraded, b.State())
+	require.Equal(t, StateDegraded, result(true))
+
+	// recovers once the failure rate falls
+	for b.State() == StateDegraded {
+		result(true)
+	}
+
+	require.Equal(t, StateClosed, b.State())
+
+	// and trips from the degraded state
+	for b.State() != StateOpen {
+		result(false)
+	}
+
+	require.Len(t, transitions, 4)
+	require.Equal(t, StateDegraded, transitions[2].To)
+	require.Equal(t, StateOpen, transitions[3].To)
+	require.Equal(t, ReasonDegraded, transitions[0].Reason)
+	require.Equal(t, ReasonRecovered, transitions[1].Reason)
+	require.Equal(t, "degraded", StateDegraded.String())
+}
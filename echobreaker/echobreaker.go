@@ -0,0 +1,61 @@
+// Package echobreaker provides echo middleware that runs requests through circuit breakers.
+package echobreaker
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/bakins/circuitbreaker"
+	"github.com/bakins/circuitbreaker/httpbreaker"
+)
+
+// RouteKey returns the method and route of a request, such as "GET /users/:id".
+func RouteKey(c echo.Context) string {
+	return c.Request().Method + " " + c.Path()
+}
+
+// Middleware returns echo middleware that runs each request through the breaker from g keyed by RouteKey.
+// It behaves like httpbreaker.Middleware and accepts the same options, such as httpbreaker.WithRejectHandler.
+// The outcome of a handler that returns an error is recorded from the error's status code,
+// which is 500 unless it is an *echo.HTTPError.
+func Middleware(g *circuitbreaker.Group[string], opts ...httpbreaker.Option) echo.MiddlewareFunc {
+	in := httpbreaker.NewGroupInbound(g, opts...)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			done, ok := in.Allow(c.Response(), c.Request(), RouteKey(c))
+			if !ok {
+				return nil
+			}
+
+			defer func() {
+				if p := recover(); p != nil {
+					done.Panic()
+					panic(p)
+				}
+			}()
+
+			err := next(c)
+
+			done.Status(status(c, err))
+
+			return err
+		}
+	}
+}
+
+// status returns the status code of a response. Errors are written after middleware returns.
+func status(c echo.Context, err error) int {
+	if err == nil || c.Response().Committed {
+		return c.Response().Status
+	}
+
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.Code
+	}
+
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,50 @@
+package echobreaker
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bakins/circuitbreaker"
+	"github.com/bakins/circuitbreaker/httpbreaker"
+)
+
+func TestMiddleware(t *testing.T) {
+	g, err := circuitbreaker.NewGroup[string](
+		circuitbreaker.WithWindow(time.Minute),
+		circuitbreaker.WithReadyToTrip(circuitbreaker.TripOnConsecutiveFailures(1)),
+	)
+	require.NoError(t, err)
+
+	e := echo.New()
+	e.Use(Middleware(g, httpbreaker.WithRejectHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte("try again later"))
+	})))
+	e.GET("/users/:id", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusServiceUnavailable)
+	})
+	e.GET("/orders", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+		return w
+	}
+
+	require.Equal(t, http.StatusServiceUnavailable, serve("/users/1").Code)
+	require.Equal(t, circuitbreaker.StateOpen, g.Get("GET /users/:id").State())
+
+	w := serve("/users/2")
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	require.Equal(t, "try again later", w.Body.String())
+
+	require.Equal(t, http.StatusOK, serve("/orders").Code)
+}
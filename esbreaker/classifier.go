@@ -0,0 +1,66 @@
+package esbreaker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+// Classifier returns the outcome of a request to a node. A Classifier may read resp.Body
+// if it replaces it with the same content.
+type Classifier func(resp *http.Response, err error) circuitbreaker.Outcome
+
+// maxErrorBody is the size of a 429 response body DefaultClassifier reads.
+const maxErrorBody = 64 << 10
+
+// DefaultClassifier classifies responses that indicate an overloaded or unhealthy node as failures:
+// 429 responses, such as circuit_breaking_exception and es_rejected_execution_exception, 5xx responses,
+// and transport errors. A circuit_breaking_exception with a PERMANENT durability is ignored since
+// it means the request is too large for the node rather than that the node is overloaded.
+// Requests canceled by the caller are ignored. Other responses, such as 404s, are successes.
+func DefaultClassifier(resp *http.Response, err error) circuitbreaker.Outcome {
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return circuitbreaker.OutcomeIgnored
+		}
+
+		return circuitbreaker.OutcomeFailure
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		if permanent(resp) {
+			return circuitbreaker.OutcomeIgnored
+		}
+
+		return circuitbreaker.OutcomeFailure
+	case resp.StatusCode >= 500:
+		return circuitbreaker.OutcomeFailure
+	default:
+		return circuitbreaker.OutcomeSuccess
+	}
+}
+
+// permanent reports whether resp is a circuit_breaking_exception with a PERMANENT durability.
+func permanent(resp *http.Response) bool {
+	if resp.Body == nil {
+		return false
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+
+	if err != nil {
+		return false
+	}
+
+	return bytes.Contains(body, []byte(`"circuit_breaking_exception"`)) &&
+		bytes.Contains(body, []byte(`"durability":"PERMANENT"`))
+}
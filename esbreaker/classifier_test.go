@@ -0,0 +1,54 @@
+package esbreaker
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+func TestDefaultClassifier(t *testing.T) {
+	const (
+		transient = `{"error":{"type":"circuit_breaking_exception","durability":"TRANSIENT"},"status":429}`
+		permanent = `{"error":{"type":"circuit_breaking_exception","durability":"PERMANENT"},"status":429}`
+	)
+
+	response := func(code int, body string) *http.Response {
+		return &http.Response{StatusCode: code, Body: io.NopCloser(strings.NewReader(body))}
+	}
+
+	tests := []struct {
+		name string
+		resp *http.Response
+		err  error
+		want circuitbreaker.Outcome
+	}{
+		{"ok", response(200, "{}"), nil, circuitbreaker.OutcomeSuccess},
+		{"not found", response(404, "{}"), nil, circuitbreaker.OutcomeSuccess},
+		{"transient", response(429, transient), nil, circuitbreaker.OutcomeFailure},
+		{"permanent", response(429, permanent), nil, circuitbreaker.OutcomeIgnored},
+		{"unavailable", response(503, "{}"), nil, circuitbreaker.OutcomeFailure},
+		{"transport", nil, errors.New("connection refused"), circuitbreaker.OutcomeFailure},
+		{"canceled", nil, context.Canceled, circuitbreaker.OutcomeIgnored},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, DefaultClassifier(tt.resp, tt.err))
+		})
+	}
+
+	// the body is still readable
+	resp := response(429, permanent)
+	DefaultClassifier(resp, nil)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, permanent, string(body))
+}
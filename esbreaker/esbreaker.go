@@ -0,0 +1,87 @@
+// Package esbreaker provides an http.RoundTripper for Elasticsearch and OpenSearch clients that runs
+// requests through a circuit breaker per node.
+//
+// Set it as the Transport of the client's config:
+//
+//	client, err := elasticsearch.NewClient(elasticsearch.Config{
+//		Addresses: addresses,
+//		Transport: esbreaker.NewTransport(nil, group),
+//	})
+//
+// While a node's breaker is open, requests to it fail with the breaker's error before they are sent.
+// The client treats this as a connection error, marks the node dead, and retries the request on
+// another node, so it stops selecting the overloaded node until the client resurrects it.
+package esbreaker
+
+import (
+	"net/http"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+type options struct {
+	classifier Classifier
+}
+
+// Option sets esbreaker options.
+type Option func(*options)
+
+// WithClassifier sets the classifier used to record the outcome of requests.
+// Default is DefaultClassifier.
+func WithClassifier(c Classifier) Option {
+	return func(o *options) {
+		o.classifier = c
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		classifier: DefaultClassifier,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// Transport is an http.RoundTripper that runs each request through the breaker for its node.
+type Transport struct {
+	next    http.RoundTripper
+	group   *circuitbreaker.Group[string]
+	options options
+}
+
+// NewTransport creates a Transport that uses the breaker from g keyed by the host, including any port,
+// of the node a request is sent to. If next is nil, http.DefaultTransport is used.
+func NewTransport(next http.RoundTripper, g *circuitbreaker.Group[string], opts ...Option) *Transport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return &Transport{
+		next:    next,
+		group:   g,
+		options: newOptions(opts),
+	}
+}
+
+// RoundTrip implements http.RoundTripper. When the breaker does not allow a request,
+// the error from the breaker, such as a *circuitbreaker.OpenStateError, is returned.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	done, err := t.group.Get(req.URL.Host).AllowOutcome()
+	if err != nil {
+		if req.Body != nil {
+			_ = req.Body.Close()
+		}
+
+		return nil, err
+	}
+
+	resp, err := t.next.RoundTrip(req)
+
+	done(t.options.classifier(resp, err))
+
+	return resp, err
+}
@@ -0,0 +1,46 @@
+package esbreaker
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+func TestTransport(t *testing.T) {
+	overloaded := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"error":{"type":"es_rejected_execution_exception"},"status":429}`))
+	}))
+	defer overloaded.Close()
+
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer healthy.Close()
+
+	g, err := circuitbreaker.NewGroup[string](
+		circuitbreaker.WithWindow(time.Minute),
+		circuitbreaker.WithReadyToTrip(circuitbreaker.TripOnConsecutiveFailures(1)),
+	)
+	require.NoError(t, err)
+
+	client := &http.Client{Transport: NewTransport(nil, g)}
+
+	resp, err := client.Get(overloaded.URL)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	_, err = client.Get(overloaded.URL)
+	require.ErrorIs(t, err, circuitbreaker.ErrOpenState)
+
+	// other nodes are not affected
+	resp, err = client.Get(healthy.URL)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+}
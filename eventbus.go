@@ -0,0 +1,228 @@
+package circuitbreaker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// EventKind is the kind of an Event.
+type EventKind int
+
+// Event kinds
+const (
+	// EventAdmission is emitted when a request is allowed.
+	EventAdmission EventKind = iota
+	// EventRejection is emitted when a request is not allowed. Err is the error returned to the caller.
+	EventRejection
+	// EventOutcome is emitted when the outcome of an allowed request is recorded.
+	// Outcome and Latency are set.
+	EventOutcome
+	// EventTransition is emitted when the state changes. Transition is set.
+	EventTransition
+	// EventConfigChange is emitted when options are changed by UpdateOptions.
+	EventConfigChange
+)
+
+// String returns a string representation of the event kind.
+func (k EventKind) String() string {
+	switch k {
+	case EventAdmission:
+		return "admission"
+	case EventRejection:
+		return "rejection"
+	case EventOutcome:
+		return "outcome"
+	case EventTransition:
+		return "transition"
+	case EventConfigChange:
+		return "configChange"
+	default:
+		return fmt.Sprintf("unknown event kind: %d", k)
+	}
+}
+
+// Event is something that happened to a Breaker, delivered to the sinks added by WithEventSink.
+type Event struct {
+	Time time.Time
+	Err  error
+	// Labels are set by WithLabels. They are shared by every event and must not be modified.
+	Labels     map[string]string
+	Breaker    string
+	Transition Transition
+	Latency    time.Duration
+	Kind       EventKind
+	// State is the state of the Breaker when the event happened, or the new state of a transition.
+	State   State
+	Outcome Outcome
+}
+
+// Sink receives the events of Breakers. Sinks are called in the background, one event at a time and in order,
+// and per-request events are dropped if a sink falls too far behind, see WithObserverQueueSize. With WithSynchronousObservers,
+// sinks are called while requests are allowed and recorded, sometimes with the Breaker locked, so they must
+// be fast and must not call methods of the Breaker.
+type Sink interface {
+	Handle(Event)
+}
+
+// SinkFunc is a function that implements Sink.
+type SinkFunc func(Event)
+
+// Handle implements Sink.
+func (f SinkFunc) Handle(e Event) {
+	f(e)
+}
+
+// WithEventSink adds a sink for the events of the Breaker of the kinds given, or of every kind if none are given.
+// It may be used more than once, and the sinks receive events in the order they were added.
+// Events are only created if there is a sink for their kind, so unused kinds add no cost.
+// There is no default.
+func WithEventSink(sink Sink, kinds ...EventKind) Option {
+	return func(o *Options) {
+		var mask uint8
+
+		for _, k := range kinds {
+			mask |= 1 << k
+		}
+
+		if mask == 0 {
+			mask = allEvents
+		}
+
+		o.sinks = append(o.sinks, eventSink{sink: sink, kinds: mask})
+	}
+}
+
+const allEvents = 1<<(EventConfigChange+1) - 1
+
+type eventSink struct {
+	sink  Sink
+	kinds uint8
+}
+
+// eventBus fans out the events of a Breaker to its sinks.
+type eventBus struct {
+	sinks  []eventSink
+	labels map[string]string
+	// queue delivers events in the background, or is nil if they are delivered synchronously.
+	queue *observerQueue
+	name  string
+	// kinds has a bit set for each kind that has a sink.
+	kinds uint8
+}
+
+func newEventBus(name string, labels map[string]string, sinks []eventSink, queue *observerQueue) *eventBus {
+	bus := &eventBus{
+		sinks:  sinks,
+		labels: labels,
+		queue:  queue,
+		name:   name,
+	}
+
+	for _, s := range sinks {
+		bus.kinds |= s.kinds
+	}
+
+	return bus
+}
+
+// wants reports whether there is a sink for events of kind.
+func (bus *eventBus) wants(kind EventKind) bool {
+	return bus != nil && bus.kinds&(1<<kind) != 0
+}
+
+func (bus *eventBus) emit(e Event) {
+	e.Breaker = bus.name
+	e.Labels = bus.labels
+
+	if bus.queue != nil {
+		// only per-request events may be dropped
+		droppable := e.Kind == EventAdmission || e.Kind == EventRejection || e.Kind == EventOutcome
+
+		bus.queue.deliver(func() {
+			bus.dispatch(e)
+		}, droppable)
+
+		return
+	}
+
+	bus.dispatch(e)
+}
+
+func (bus *eventBus) dispatch(e Event) {
+	for _, s := range bus.sinks {
+		if s.kinds&(1<<e.Kind) != 0 {
+			s.sink.Handle(e)
+		}
+	}
+}
+
+// LogSink returns a Sink that logs events to logger: transitions and configuration changes at
+// slog.LevelInfo, rejections at slog.LevelDebug, and admissions and outcomes at slog.LevelDebug - 4,
+// as they are logged for every request.
+func LogSink(logger *slog.Logger) Sink {
+	return SinkFunc(func(e Event) {
+		level := slog.LevelDebug - 4
+		attrs := []slog.Attr{
+			slog.String("breaker", e.Breaker),
+			slog.String("state", e.State.String()),
+		}
+
+		switch e.Kind {
+		case EventRejection:
+			level = slog.LevelDebug
+			attrs = append(attrs, slog.String("error", e.Err.Error()))
+		case EventOutcome:
+			attrs = append(attrs, slog.String("outcome", e.Outcome.String()), slog.Duration("latency", e.Latency))
+		case EventTransition:
+			level = slog.LevelInfo
+			attrs = append(attrs,
+				slog.String("from", e.Transition.From.String()),
+				slog.String("reason", e.Transition.Reason.String()),
+				slog.String("condition", e.Transition.Condition),
+			)
+		case EventConfigChange:
+			level = slog.LevelInfo
+		}
+
+		logger.LogAttrs(context.Background(), level, "circuit breaker "+e.Kind.String(), attrs...)
+	})
+}
+
+// ChannelSink is a Sink that sends events to a channel without blocking. Events that do not fit
+// in the channel's buffer are dropped and counted.
+type ChannelSink struct {
+	ch      chan<- Event
+	dropped atomic.Uint64
+}
+
+// NewChannelSink creates a ChannelSink that sends to ch.
+func NewChannelSink(ch chan<- Event) *ChannelSink {
+	return &ChannelSink{ch: ch}
+}
+
+// Handle implements Sink.
+func (s *ChannelSink) Handle(e Event) {
+	select {
+	case s.ch <- e:
+	default:
+		s.dropped.Add(1)
+	}
+}
+
+// Dropped returns the number of events that were dropped because the channel was full.
+func (s *ChannelSink) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// NotifierSink returns a Sink that passes transitions to n, such as a WebhookNotifier,
+// so it may be used with WithEventSink rather than WithNotifier.
+func NotifierSink(n Notifier) Sink {
+	return SinkFunc(func(e Event) {
+		if e.Kind == EventTransition {
+			n.Notify(TransitionEvent{Name: e.Breaker, Transition: e.Transition, Labels: e.Labels})
+		}
+	})
+}
@@ -0,0 +1,112 @@
+package circuitbreaker
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventSink(t *testing.T) {
+	c := &testClock{
+		now: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	var events []Event
+
+	b, err := New(
+		WithName("db"),
+		WithClock(c),
+		WithLabels(map[string]string{"team": "storage"}),
+		WithReadyToTrip(func(Counts) bool { return true }),
+		WithEventSink(SinkFunc(func(e Event) { events = append(events, e) })),
+		WithSynchronousObservers(),
+	)
+	require.NoError(t, err)
+
+	done, err := b.Allow()
+	require.NoError(t, err)
+	c.advance(time.Millisecond)
+	done(false)
+
+	_, err = b.Allow()
+	require.ErrorIs(t, err, ErrOpenState)
+
+	require.NoError(t, b.UpdateOptions(WithMaxRequests(2)))
+
+	kinds := make([]EventKind, len(events))
+	for i, e := range events {
+		kinds[i] = e.Kind
+		require.Equal(t, "db", e.Breaker)
+		require.Equal(t, map[string]string{"team": "storage"}, e.Labels)
+	}
+
+	require.Equal(t, []EventKind{EventAdmission, EventOutcome, EventTransition, EventRejection, EventConfigChange}, kinds)
+	require.Equal(t, OutcomeFailure, events[1].Outcome)
+	require.Equal(t, time.Millisecond, events[1].Latency)
+	require.Equal(t, StateOpen, events[2].State)
+	require.Equal(t, StateOpen, events[2].Transition.To)
+	require.Error(t, events[3].Err)
+	require.Contains(t, b.Options().Hooks, "eventSink")
+}
+
+func TestEventSinkKinds(t *testing.T) {
+	ch := make(chan Event, 1)
+	sink := NewChannelSink(ch)
+
+	b, err := New(WithEventSink(sink, EventTransition, EventRejection), WithSynchronousObservers())
+	require.NoError(t, err)
+
+	// admissions and outcomes are not sent
+	done, err := b.Allow()
+	require.NoError(t, err)
+	done(true)
+	require.Empty(t, ch)
+
+	b.Trip()
+	_, err = b.Allow()
+	require.Error(t, err)
+
+	// the rejection did not fit in the channel
+	require.Equal(t, EventTransition, (<-ch).Kind)
+	require.Equal(t, uint64(1), sink.Dropped())
+}
+
+func TestLogSink(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	b, err := New(WithName("db"), WithEventSink(LogSink(logger)), WithSynchronousObservers())
+	require.NoError(t, err)
+
+	done, err := b.Allow()
+	require.NoError(t, err)
+	done(true)
+
+	// admissions and outcomes are below the debug level
+	require.Empty(t, buf.String())
+
+	b.Trip()
+	require.Contains(t, buf.String(), `level=INFO msg="circuit breaker transition" breaker=db state=open from=closed reason=trip`)
+
+	_, err = b.Allow()
+	require.Error(t, err)
+	require.Contains(t, buf.String(), `level=DEBUG msg="circuit breaker rejection"`)
+}
+
+func TestNotifierSink(t *testing.T) {
+	var events []TransitionEvent
+
+	b, err := New(WithName("db"), WithEventSink(NotifierSink(notifierFunc(func(e TransitionEvent) {
+		events = append(events, e)
+	}))), WithSynchronousObservers())
+	require.NoError(t, err)
+
+	b.Trip()
+	require.Len(t, events, 1)
+	require.Equal(t, "db", events[0].Name)
+	require.Equal(t, StateOpen, events[0].Transition.To)
+}
@@ -0,0 +1,140 @@
+package circuitbreaker
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// EventsHandler returns a handler that streams Server-Sent Events for the Breakers in a Registry.
+// A "transition" event is sent whenever a Breaker changes state and a "status" event is sent
+// for each Breaker periodically. The optional query parameters are:
+//
+//	name      only stream events for the named breaker
+//	interval  the interval between status events, such as "10s". Default is 5 seconds.
+func EventsHandler(reg *Registry) http.Handler {
+	return &eventsHandler{
+		registry: reg,
+	}
+}
+
+type eventsHandler struct {
+	registry *Registry
+}
+
+// TransitionEvent is the data of a "transition" event.
+type TransitionEvent struct {
+	Name       string     `json:"name"`
+	Transition Transition `json:"transition"`
+	// Labels are set by WithLabels.
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+func (e *eventsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming unsupported")
+		return
+	}
+
+	interval := 5 * time.Second
+
+	if v := r.URL.Query().Get("interval"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			writeError(w, http.StatusBadRequest, "invalid interval")
+			return
+		}
+
+		interval = d
+	}
+
+	breakers := e.breakers(r.URL.Query().Get("name"))
+	if breakers == nil {
+		writeError(w, http.StatusNotFound, "circuit breaker not found")
+		return
+	}
+
+	transitions := make(chan TransitionEvent, 64)
+
+	for _, b := range breakers {
+		name, labels := b.Name(), b.Labels()
+
+		unsubscribe := b.Subscribe(func(t Transition) {
+			// never block the breaker on a slow client
+			select {
+			case transitions <- TransitionEvent{Name: name, Labels: labels, Transition: t}:
+			default:
+			}
+		})
+
+		defer unsubscribe()
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	send := func(event string, v interface{}) bool {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return false
+		}
+
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data); err != nil {
+			return false
+		}
+
+		flusher.Flush()
+
+		return true
+	}
+
+	sendStatus := func() bool {
+		for _, b := range e.breakers(r.URL.Query().Get("name")) {
+			if !send("status", b.Status()) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	if !sendStatus() {
+		return
+	}
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case t := <-transitions:
+			if !send("transition", t) {
+				return
+			}
+		case <-ticker.C:
+			if !sendStatus() {
+				return
+			}
+		}
+	}
+}
+
+// breakers returns the named breaker or all breakers if name is empty.
+// It returns nil if the named breaker is not found.
+func (e *eventsHandler) breakers(name string) []*Breaker {
+	if name == "" {
+		return e.registry.Breakers()
+	}
+
+	b, ok := e.registry.Get(name)
+	if !ok {
+		return nil
+	}
+
+	return []*Breaker{b}
+}
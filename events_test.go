@@ -0,0 +1,81 @@
+package circuitbreaker
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventsHandler(t *testing.T) {
+	reg := NewRegistry()
+
+	b, err := New(WithName("test"))
+	require.NoError(t, err)
+	require.NoError(t, reg.Register(b))
+
+	svr := httptest.NewServer(EventsHandler(reg))
+	defer svr.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, svr.URL+"?name=test&interval=1h", nil)
+	require.NoError(t, err)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+
+	defer resp.Body.Close()
+
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	scanner := bufio.NewScanner(resp.Body)
+
+	next := func() (string, string) {
+		var event, data string
+
+		for scanner.Scan() {
+			line := scanner.Text()
+			if line == "" {
+				return event, data
+			}
+
+			switch {
+			case strings.HasPrefix(line, "event: "):
+				event = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				data = strings.TrimPrefix(line, "data: ")
+			}
+		}
+
+		require.NoError(t, scanner.Err())
+
+		return event, data
+	}
+
+	event, data := next()
+	require.Equal(t, "status", event)
+	require.Contains(t, data, `"state":"closed"`)
+
+	b.Trip()
+
+	event, data = next()
+	require.Equal(t, "transition", event)
+
+	var te map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(data), &te))
+	require.Equal(t, "test", te["name"])
+	require.Equal(t, "open", te["transition"].(map[string]interface{})["to"])
+}
+
+func TestEventsHandlerNotFound(t *testing.T) {
+	w := httptest.NewRecorder()
+	EventsHandler(NewRegistry()).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?name=missing", nil))
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
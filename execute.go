@@ -0,0 +1,168 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrCallTimeout is returned by Execute when a function runs longer than the call timeout.
+var ErrCallTimeout = errors.New("circuit breaker call timed out")
+
+// WithCallTimeout sets the maximum time a function run by Execute may run. The function's
+// context has this deadline. If the function ignores its context and does not return in time,
+// Execute abandons it, records a failure, and returns an error that matches both ErrCallTimeout
+// and context.DeadlineExceeded.
+// There is no default.
+func WithCallTimeout(d time.Duration) Option {
+	return func(o *Options) {
+		o.callTimeout = d
+	}
+}
+
+// WithHedgeDelay enables hedged requests in Execute: if the function has not returned after d,
+// it is run a second time, concurrently. The result of whichever attempt returns first is returned
+// and recorded, and the context of the other attempt is canceled. Only use hedging with idempotent functions.
+// There is no default.
+func WithHedgeDelay(d time.Duration) Option {
+	return func(o *Options) {
+		o.hedgeDelay = d
+	}
+}
+
+// WithAbandoned sets a function that is called with the result of a function abandoned by Execute,
+// once it returns. This may be used to log late errors or release resources.
+// There is no default.
+func WithAbandoned(fn func(err error)) Option {
+	return func(o *Options) {
+		o.onAbandoned = fn
+	}
+}
+
+// WithIgnoreCallerDeadlines ignores functions run by Execute that fail because the caller's deadline passed
+// before the call timeout, rather than recording them as failures, so callers with tight deadlines do not trip
+// the Breaker. Without a call timeout, every failure due to the caller's deadline is ignored.
+// Failures due to the call timeout are still recorded. Either way, they are counted in Stats.CallerDeadlines.
+func WithIgnoreCallerDeadlines() Option {
+	return func(o *Options) {
+		o.ignoreDeadlines = true
+	}
+}
+
+// Execute runs fn if the Breaker allows it and records the outcome: nil errors are
+// successes, functions canceled by the caller are ignored, and other errors are failures.
+// If the Breaker does not allow the request, the error from the Breaker is returned.
+func (b *Breaker) Execute(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := b.checkDeadline(ctx); err != nil {
+		return err
+	}
+
+	done, err := b.AllowOutcome()
+	if err != nil {
+		return err
+	}
+
+	// the caller's deadline is pressure on the call if it leaves less than the call's own budget
+	deadline, pressured := ctx.Deadline()
+	if pressured && b.options.callTimeout > 0 {
+		pressured = deadline.Sub(b.options.clock.Now()) < b.options.callTimeout
+	}
+
+	err = b.call(ctx, fn)
+
+	o := outcomeOf(ctx, err)
+
+	if o == OutcomeFailure && pressured && errors.Is(ctx.Err(), context.DeadlineExceeded) &&
+		errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, ErrCallTimeout) {
+		b.callerDeadlines.Add(1)
+
+		if b.options.ignoreDeadlines {
+			o = OutcomeIgnored
+		}
+	}
+
+	done(o)
+
+	return err
+}
+
+func (b *Breaker) call(ctx context.Context, fn func(ctx context.Context) error) error {
+	if b.options.callTimeout <= 0 && b.options.hedgeDelay <= 0 {
+		return fn(ctx)
+	}
+
+	var (
+		callCtx context.Context
+		cancel  context.CancelFunc
+	)
+
+	if b.options.callTimeout > 0 {
+		callCtx, cancel = context.WithTimeout(ctx, b.options.callTimeout)
+	} else {
+		callCtx, cancel = context.WithCancel(ctx)
+	}
+
+	// cancels the losing attempt, if any
+	defer cancel()
+
+	results := make(chan error, 2)
+	attempt := func() {
+		results <- fn(callCtx)
+	}
+
+	go attempt()
+	pending := 1
+
+	var hedge <-chan time.Time
+	if b.options.hedgeDelay > 0 {
+		hedge = b.options.clock.After(b.options.hedgeDelay)
+	}
+
+	for {
+		select {
+		case err := <-results:
+			return err
+		case <-hedge:
+			hedge = nil
+
+			go attempt()
+			pending++
+		case <-callCtx.Done():
+			b.abandon(results, pending)
+
+			// the caller's context is done, rather than the call timing out
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			return fmt.Errorf("%w: %w", ErrCallTimeout, context.DeadlineExceeded)
+		}
+	}
+}
+
+// abandon passes the results of pending attempts to the abandoned function, if any.
+func (b *Breaker) abandon(results <-chan error, pending int) {
+	fn := b.options.onAbandoned
+	if fn == nil {
+		return
+	}
+
+	go func() {
+		for i := 0; i < pending; i++ {
+			fn(<-results)
+		}
+	}()
+}
+
+// outcomeOf returns the outcome of a request made with ctx that returned err.
+func outcomeOf(ctx context.Context, err error) Outcome {
+	switch {
+	case err == nil:
+		return OutcomeSuccess
+	case errors.Is(err, context.Canceled) && ctx.Err() != nil:
+		return OutcomeIgnored
+	default:
+		return OutcomeFailure
+	}
+}
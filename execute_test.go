@@ -0,0 +1,156 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecute(t *testing.T) {
+	b, err := New(WithWindow(time.Minute))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	require.NoError(t, b.Execute(ctx, func(context.Context) error { return nil }))
+
+	fail := errors.New("fail")
+	require.ErrorIs(t, b.Execute(ctx, func(context.Context) error { return fail }), fail)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	err = b.Execute(canceled, func(ctx context.Context) error { return ctx.Err() })
+	require.ErrorIs(t, err, context.Canceled)
+
+	counts := b.counts()
+	require.Equal(t, uint64(1), counts.TotalSuccesses)
+	require.Equal(t, uint64(1), counts.TotalFailures)
+
+	b.Trip()
+
+	err = b.Execute(ctx, func(context.Context) error { return nil })
+	require.ErrorIs(t, err, ErrOpenState)
+}
+
+func TestExecuteCallTimeout(t *testing.T) {
+	abandoned := make(chan error, 1)
+
+	b, err := New(
+		WithCallTimeout(10*time.Millisecond),
+		WithWindow(time.Minute),
+		WithAbandoned(func(err error) { abandoned <- err }),
+	)
+	require.NoError(t, err)
+
+	release := make(chan struct{})
+	late := errors.New("late")
+
+	// ignores its context
+	err = b.Execute(context.Background(), func(context.Context) error {
+		<-release
+		return late
+	})
+	require.ErrorIs(t, err, ErrCallTimeout)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Equal(t, uint64(1), b.counts().TotalFailures)
+
+	close(release)
+	require.ErrorIs(t, <-abandoned, late)
+
+	err = b.Execute(context.Background(), func(ctx context.Context) error {
+		_, ok := ctx.Deadline()
+		require.True(t, ok)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), b.counts().TotalSuccesses)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(time.Millisecond, cancel)
+
+	err = b.Execute(ctx, func(context.Context) error {
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	})
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, uint64(1), b.counts().TotalFailures)
+}
+
+func TestExecuteHedge(t *testing.T) {
+	b, err := New(WithHedgeDelay(10*time.Millisecond), WithWindow(time.Minute))
+	require.NoError(t, err)
+
+	var attempts atomic.Int32
+	loserCanceled := make(chan struct{})
+
+	// the first attempt is slow, the hedged attempt fails fast
+	fail := errors.New("fail")
+
+	err = b.Execute(context.Background(), func(ctx context.Context) error {
+		if attempts.Add(1) == 1 {
+			<-ctx.Done()
+			close(loserCanceled)
+			return ctx.Err()
+		}
+
+		return fail
+	})
+	require.ErrorIs(t, err, fail)
+	require.Equal(t, int32(2), attempts.Load())
+
+	<-loserCanceled
+
+	counts := b.counts()
+	require.Equal(t, uint64(0), counts.TotalSuccesses)
+	require.Equal(t, uint64(1), counts.TotalFailures)
+
+	// fast attempts are not hedged
+	attempts.Store(0)
+
+	require.NoError(t, b.Execute(context.Background(), func(context.Context) error {
+		attempts.Add(1)
+		return nil
+	}))
+	require.Equal(t, int32(1), attempts.Load())
+}
+
+func TestExecuteCallerDeadline(t *testing.T) {
+	b, err := New(WithCallTimeout(time.Second), WithWindow(time.Minute), WithIgnoreCallerDeadlines())
+	require.NoError(t, err)
+
+	wait := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	// the caller's deadline is shorter than the call timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	require.ErrorIs(t, b.Execute(ctx, wait), context.DeadlineExceeded)
+	require.Equal(t, uint64(0), b.counts().TotalFailures)
+	require.Equal(t, uint64(1), b.Stats().CallerDeadlines)
+
+	// errors from the function are still failures
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	require.Error(t, b.Execute(ctx, func(context.Context) error { return errors.New("fail") }))
+	require.Equal(t, uint64(1), b.counts().TotalFailures)
+
+	// without the option, the caller's deadline is a failure, but still counted
+	b, err = New(WithWindow(time.Minute))
+	require.NoError(t, err)
+
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	require.ErrorIs(t, b.Execute(ctx, wait), context.DeadlineExceeded)
+	require.Equal(t, uint64(1), b.counts().TotalFailures)
+	require.Equal(t, uint64(1), b.Stats().CallerDeadlines)
+}
@@ -0,0 +1,214 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrExecutorClosed is returned when submitting a job to a closed Executor.
+	ErrExecutorClosed = errors.New("executor is closed")
+
+	// ErrQueueFull is returned by TrySubmit when the queue of an Executor is full.
+	ErrQueueFull = errors.New("executor queue is full")
+)
+
+// Job is a function run by an Executor.
+type Job func(ctx context.Context) error
+
+// Executor runs jobs on a bounded pool of workers. Jobs are only queued when the Breaker
+// allows them, and the outcome of each job is recorded: nil errors are successes,
+// jobs canceled by the caller are ignored, and other errors are failures.
+type Executor struct {
+	breaker *Breaker
+	options executorOptions
+	jobs    chan *executorJob
+	wg      sync.WaitGroup
+
+	lock   sync.RWMutex
+	closed bool
+}
+
+type executorOptions struct {
+	workers    int
+	queueSize  int
+	jobTimeout time.Duration
+}
+
+// ExecutorOption sets Executor options.
+type ExecutorOption func(*executorOptions)
+
+// WithWorkers sets the number of jobs that run concurrently.
+// Default is runtime.GOMAXPROCS(0).
+func WithWorkers(n int) ExecutorOption {
+	return func(o *executorOptions) {
+		o.workers = n
+	}
+}
+
+// WithQueueSize sets the number of jobs that may wait for a worker.
+// Default is the number of workers.
+func WithQueueSize(n int) ExecutorOption {
+	return func(o *executorOptions) {
+		o.queueSize = n
+	}
+}
+
+// WithJobTimeout sets the maximum time a job may run. A job that times out is a failure.
+// There is no default.
+func WithJobTimeout(d time.Duration) ExecutorOption {
+	return func(o *executorOptions) {
+		o.jobTimeout = d
+	}
+}
+
+type executorJob struct {
+	ctx    context.Context
+	fn     Job
+	done   func(Outcome)
+	result chan error
+}
+
+// NewExecutor creates an Executor and starts its workers.
+// Call Close to stop them.
+func NewExecutor(b *Breaker, options ...ExecutorOption) *Executor {
+	opts := executorOptions{
+		workers: runtime.GOMAXPROCS(0),
+	}
+
+	for _, o := range options {
+		o(&opts)
+	}
+
+	if opts.workers < 1 {
+		opts.workers = 1
+	}
+
+	if opts.queueSize <= 0 {
+		opts.queueSize = opts.workers
+	}
+
+	e := &Executor{
+		breaker: b,
+		options: opts,
+		jobs:    make(chan *executorJob, opts.queueSize),
+	}
+
+	e.wg.Add(opts.workers)
+
+	for i := 0; i < opts.workers; i++ {
+		go e.work()
+	}
+
+	return e
+}
+
+// Submit queues a job, waiting while the queue is full. It returns the error from the Breaker
+// if the Breaker does not allow the job, and the context's error if the context is done before the
+// job is queued. The returned channel receives the job's error once it has run.
+// The job's context is derived from ctx.
+func (e *Executor) Submit(ctx context.Context, fn Job) (<-chan error, error) {
+	return e.submit(ctx, fn, true)
+}
+
+// TrySubmit is like Submit, but returns ErrQueueFull rather than waiting when the queue is full.
+// A job that is not queued is not recorded by the Breaker.
+func (e *Executor) TrySubmit(ctx context.Context, fn Job) (<-chan error, error) {
+	return e.submit(ctx, fn, false)
+}
+
+// Do submits a job and waits for its result.
+func (e *Executor) Do(ctx context.Context, fn Job) error {
+	result, err := e.Submit(ctx, fn)
+	if err != nil {
+		return err
+	}
+
+	return <-result
+}
+
+func (e *Executor) submit(ctx context.Context, fn Job, wait bool) (<-chan error, error) {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	if e.closed {
+		return nil, ErrExecutorClosed
+	}
+
+	done, err := e.breaker.AllowOutcome()
+	if err != nil {
+		return nil, err
+	}
+
+	job := &executorJob{
+		ctx:    ctx,
+		fn:     fn,
+		done:   done,
+		result: make(chan error, 1),
+	}
+
+	if !wait {
+		select {
+		case e.jobs <- job:
+			return job.result, nil
+		default:
+			done(OutcomeIgnored)
+			return nil, ErrQueueFull
+		}
+	}
+
+	select {
+	case e.jobs <- job:
+		return job.result, nil
+	case <-ctx.Done():
+		done(OutcomeIgnored)
+		return nil, ctx.Err()
+	}
+}
+
+// Close stops accepting jobs and waits for queued jobs to finish.
+func (e *Executor) Close() {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if e.closed {
+		return
+	}
+
+	e.closed = true
+	close(e.jobs)
+
+	e.wg.Wait()
+}
+
+func (e *Executor) work() {
+	defer e.wg.Done()
+
+	for job := range e.jobs {
+		job.result <- e.run(job)
+	}
+}
+
+func (e *Executor) run(job *executorJob) error {
+	// the caller gave up while the job was queued
+	if err := job.ctx.Err(); err != nil {
+		job.done(OutcomeIgnored)
+		return err
+	}
+
+	ctx := job.ctx
+	if e.options.jobTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.options.jobTimeout)
+		defer cancel()
+	}
+
+	err := job.fn(ctx)
+
+	job.done(outcomeOf(job.ctx, err))
+
+	return err
+}
@@ -0,0 +1,116 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutor(t *testing.T) {
+	c := &testClock{
+		now: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	b, err := New(WithClock(c), WithReadyToTrip(func(counts Counts) bool { return counts.ConsecutiveFailures >= 2 }))
+	require.NoError(t, err)
+
+	e := NewExecutor(b, WithWorkers(2))
+	defer e.Close()
+
+	ctx := context.Background()
+
+	require.NoError(t, e.Do(ctx, func(context.Context) error { return nil }))
+
+	fail := errors.New("fail")
+
+	require.ErrorIs(t, e.Do(ctx, func(context.Context) error { return fail }), fail)
+	require.Equal(t, StateClosed, b.State())
+
+	require.ErrorIs(t, e.Do(ctx, func(context.Context) error { return fail }), fail)
+	require.Equal(t, StateOpen, b.State())
+
+	_, err = e.Submit(ctx, func(context.Context) error { return nil })
+	require.ErrorIs(t, err, ErrOpenState)
+
+	counts := b.counts()
+	require.Equal(t, uint64(1), counts.TotalSuccesses)
+	require.Equal(t, uint64(2), counts.TotalFailures)
+}
+
+func TestExecutorJobTimeout(t *testing.T) {
+	b, err := New(WithWindow(time.Minute))
+	require.NoError(t, err)
+
+	e := NewExecutor(b, WithWorkers(1), WithJobTimeout(10*time.Millisecond))
+	defer e.Close()
+
+	err = e.Do(context.Background(), func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Equal(t, uint64(1), b.counts().TotalFailures)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	err = e.Do(ctx, func(ctx context.Context) error {
+		cancel()
+		return ctx.Err()
+	})
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, uint64(1), b.counts().TotalFailures)
+}
+
+func TestExecutorBackpressure(t *testing.T) {
+	c := &testClock{
+		now: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	b, err := New(WithClock(c))
+	require.NoError(t, err)
+
+	e := NewExecutor(b, WithWorkers(1), WithQueueSize(1))
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	blocked := func(context.Context) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	}
+
+	ctx := context.Background()
+
+	first, err := e.Submit(ctx, blocked)
+	require.NoError(t, err)
+
+	<-started
+
+	second, err := e.Submit(ctx, func(context.Context) error { return nil })
+	require.NoError(t, err)
+
+	_, err = e.TrySubmit(ctx, func(context.Context) error { return nil })
+	require.ErrorIs(t, err, ErrQueueFull)
+
+	timeout, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+
+	_, err = e.Submit(timeout, func(context.Context) error { return nil })
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	close(release)
+
+	require.NoError(t, <-first)
+	require.NoError(t, <-second)
+
+	e.Close()
+
+	_, err = e.Submit(ctx, func(context.Context) error { return nil })
+	require.ErrorIs(t, err, ErrExecutorClosed)
+
+	require.Equal(t, uint64(2), b.counts().TotalSuccesses)
+}
@@ -0,0 +1,39 @@
+// Package ginbreaker provides gin middleware that runs requests through circuit breakers.
+package ginbreaker
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/bakins/circuitbreaker"
+	"github.com/bakins/circuitbreaker/httpbreaker"
+)
+
+// RouteKey returns the method and route of a request, such as "GET /users/:id".
+func RouteKey(c *gin.Context) string {
+	return c.Request.Method + " " + c.FullPath()
+}
+
+// Middleware returns gin middleware that runs each request through the breaker from g keyed by RouteKey.
+// It behaves like httpbreaker.Middleware and accepts the same options, such as httpbreaker.WithRejectHandler.
+func Middleware(g *circuitbreaker.Group[string], opts ...httpbreaker.Option) gin.HandlerFunc {
+	in := httpbreaker.NewGroupInbound(g, opts...)
+
+	return func(c *gin.Context) {
+		done, ok := in.Allow(c.Writer, c.Request, RouteKey(c))
+		if !ok {
+			c.Abort()
+			return
+		}
+
+		defer func() {
+			if p := recover(); p != nil {
+				done.Panic()
+				panic(p)
+			}
+		}()
+
+		c.Next()
+
+		done.Status(c.Writer.Status())
+	}
+}
@@ -0,0 +1,44 @@
+package ginbreaker
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	g, err := circuitbreaker.NewGroup[string](
+		circuitbreaker.WithWindow(time.Minute),
+		circuitbreaker.WithReadyToTrip(circuitbreaker.TripOnConsecutiveFailures(1)),
+	)
+	require.NoError(t, err)
+
+	r := gin.New()
+	r.Use(Middleware(g))
+	r.GET("/users/:id", func(c *gin.Context) {
+		c.Status(http.StatusInternalServerError)
+	})
+	r.GET("/orders", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	serve := func(path string) int {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+		return w.Code
+	}
+
+	require.Equal(t, http.StatusInternalServerError, serve("/users/1"))
+	require.Equal(t, circuitbreaker.StateOpen, g.Get("GET /users/:id").State())
+	require.Equal(t, http.StatusServiceUnavailable, serve("/users/2"))
+	require.Equal(t, http.StatusOK, serve("/orders"))
+}
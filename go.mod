@@ -1,10 +1,16 @@
 module github.com/bakins/circuitbreaker
 
-go 1.15
+go 1.22
 
 require (
-	github.com/asecurityteam/rolling v0.0.0-20201116160842-fe8c9d18d9ce
-	github.com/davecgh/go-spew v1.1.1 // indirect
-	github.com/stretchr/testify v1.6.1
-	gopkg.in/yaml.v3 v3.0.0-20210106172901-c476de37821d // indirect
+	github.com/stretchr/testify v1.12.1
+	golang.org/x/time v0.10.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/kr/pretty v0.3.1 // indirect
+	github.com/rogpeppe/go-internal v1.10.0 // indirect
+	go.yaml.in/yaml/v3 v3.0.5 // indirect
+	gopkg.in/check.v1 v1.0.0-20201130134442-10cb98267c6c // indirect
 )
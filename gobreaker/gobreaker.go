@@ -0,0 +1,264 @@
+// Package gobreaker provides the API of github.com/sony/gobreaker backed by a circuitbreaker.Breaker,
+// so code written for gobreaker can switch by changing its import path.
+//
+// The Breaker counts requests in a rolling window rather than clearing its counts every Interval,
+// and timeouts are measured in whole seconds.
+package gobreaker
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+var (
+	// ErrTooManyRequests is returned when the breaker is half-open and the number of requests exceeds MaxRequests.
+	ErrTooManyRequests = errors.New("too many requests")
+	// ErrOpenState is returned when the breaker is open.
+	ErrOpenState = errors.New("circuit breaker is open")
+)
+
+// State is the state of a circuit breaker.
+type State int
+
+// States
+const (
+	StateClosed State = iota
+	StateHalfOpen
+	StateOpen
+)
+
+// String returns a string representation of the state.
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateHalfOpen:
+		return "half-open"
+	case StateOpen:
+		return "open"
+	default:
+		return fmt.Sprintf("unknown state: %d", s)
+	}
+}
+
+func fromState(s circuitbreaker.State) State {
+	switch s {
+	case circuitbreaker.StateHalfOpen:
+		return StateHalfOpen
+	case circuitbreaker.StateOpen:
+		return StateOpen
+	default:
+		return StateClosed
+	}
+}
+
+// Counts holds the numbers of requests and their successes and failures in the window.
+type Counts struct {
+	Requests             uint32
+	TotalSuccesses       uint32
+	TotalFailures        uint32
+	ConsecutiveSuccesses uint32
+	ConsecutiveFailures  uint32
+}
+
+func fromCounts(c circuitbreaker.Counts) Counts {
+	return Counts{
+		Requests:             uint32(c.Requests),
+		TotalSuccesses:       uint32(c.TotalSuccesses),
+		TotalFailures:        uint32(c.TotalFailures),
+		ConsecutiveSuccesses: uint32(c.ConsecutiveSuccesses),
+		ConsecutiveFailures:  uint32(c.ConsecutiveFailures),
+	}
+}
+
+// Settings configures a CircuitBreaker.
+//
+// Name is the name of the breaker.
+//
+// MaxRequests is the maximum number of requests allowed when the breaker is half-open.
+// If zero, one request is allowed.
+//
+// Interval is the rolling window of the counts, rounded up to whole seconds.
+// If zero, which never clears the counts in gobreaker, the window is one minute.
+//
+// Timeout is how long the breaker is open before it becomes half-open, rounded up to whole seconds.
+// If zero, the timeout is 60 seconds.
+//
+// ReadyToTrip is called with the counts when a request fails while the breaker is closed,
+// and the breaker opens if it returns true. If nil, the breaker opens after more than 5 consecutive failures.
+//
+// OnStateChange is called when the state changes.
+//
+// IsSuccessful reports whether the error returned by a request is a success.
+// If nil, only nil errors are successes.
+type Settings struct {
+	Name          string
+	MaxRequests   uint32
+	Interval      time.Duration
+	Timeout       time.Duration
+	ReadyToTrip   func(counts Counts) bool
+	OnStateChange func(name string, from State, to State)
+	IsSuccessful  func(err error) bool
+}
+
+// CircuitBreaker runs requests with Execute.
+type CircuitBreaker struct {
+	breaker      *circuitbreaker.Breaker
+	isSuccessful func(err error) bool
+}
+
+// TwoStepCircuitBreaker allows requests with Allow and records their results in a second step.
+type TwoStepCircuitBreaker struct {
+	cb *CircuitBreaker
+}
+
+// NewCircuitBreaker creates a CircuitBreaker.
+func NewCircuitBreaker(st Settings) *CircuitBreaker {
+	opts := []circuitbreaker.Option{
+		circuitbreaker.WithName(st.Name),
+		circuitbreaker.WithMaxRequests(max(uint64(st.MaxRequests), 1)),
+		circuitbreaker.WithWindow(seconds(st.Interval, time.Minute)),
+		circuitbreaker.WithTimeout(seconds(st.Timeout, 60*time.Second)),
+		// gobreaker computes the state when it is read
+		circuitbreaker.WithLegacyState(),
+	}
+
+	readyToTrip := st.ReadyToTrip
+	if readyToTrip == nil {
+		readyToTrip = defaultReadyToTrip
+	}
+
+	opts = append(opts, circuitbreaker.WithReadyToTrip(func(c circuitbreaker.Counts) bool {
+		return readyToTrip(fromCounts(c))
+	}))
+
+	if st.OnStateChange != nil {
+		opts = append(opts, circuitbreaker.WithOnStateChange(func(from circuitbreaker.State, to circuitbreaker.State) {
+			st.OnStateChange(st.Name, fromState(from), fromState(to))
+		}), circuitbreaker.WithSynchronousObservers())
+	}
+
+	b, err := circuitbreaker.New(opts...)
+	if err != nil {
+		// the options are always valid
+		panic(err)
+	}
+
+	cb := &CircuitBreaker{
+		breaker:      b,
+		isSuccessful: st.IsSuccessful,
+	}
+
+	if cb.isSuccessful == nil {
+		cb.isSuccessful = defaultIsSuccessful
+	}
+
+	return cb
+}
+
+// NewTwoStepCircuitBreaker creates a TwoStepCircuitBreaker.
+func NewTwoStepCircuitBreaker(st Settings) *TwoStepCircuitBreaker {
+	return &TwoStepCircuitBreaker{cb: NewCircuitBreaker(st)}
+}
+
+func defaultReadyToTrip(counts Counts) bool {
+	return counts.ConsecutiveFailures > 5
+}
+
+func defaultIsSuccessful(err error) bool {
+	return err == nil
+}
+
+// seconds rounds d up to whole seconds, or returns def if d is not positive.
+func seconds(d time.Duration, def time.Duration) time.Duration {
+	if d <= 0 {
+		return def
+	}
+
+	return (d + time.Second - 1).Truncate(time.Second)
+}
+
+// Name returns the name of the CircuitBreaker.
+func (cb *CircuitBreaker) Name() string {
+	return cb.breaker.Name()
+}
+
+// State returns the current state of the CircuitBreaker.
+func (cb *CircuitBreaker) State() State {
+	return fromState(cb.breaker.State())
+}
+
+// Counts returns the counts in the window.
+func (cb *CircuitBreaker) Counts() Counts {
+	return fromCounts(cb.breaker.Status().Counts)
+}
+
+// Breaker returns the circuitbreaker.Breaker that backs the CircuitBreaker, to use features
+// that gobreaker does not have.
+func (cb *CircuitBreaker) Breaker() *circuitbreaker.Breaker {
+	return cb.breaker
+}
+
+// Execute runs req if the CircuitBreaker allows it and records its result. If the CircuitBreaker
+// does not allow the request, it returns ErrOpenState or ErrTooManyRequests. A panic in req is recorded
+// as a failure and then continues.
+func (cb *CircuitBreaker) Execute(req func() (interface{}, error)) (interface{}, error) {
+	done, err := cb.allow()
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if e := recover(); e != nil {
+			done(false)
+			panic(e)
+		}
+	}()
+
+	result, err := req()
+	done(cb.isSuccessful(err))
+
+	return result, err
+}
+
+func (cb *CircuitBreaker) allow() (func(bool), error) {
+	done, err := cb.breaker.Allow()
+
+	switch {
+	case errors.Is(err, circuitbreaker.ErrTooManyRequests):
+		return nil, ErrTooManyRequests
+	case err != nil:
+		return nil, ErrOpenState
+	}
+
+	return done, nil
+}
+
+// Name returns the name of the TwoStepCircuitBreaker.
+func (tscb *TwoStepCircuitBreaker) Name() string {
+	return tscb.cb.Name()
+}
+
+// State returns the current state of the TwoStepCircuitBreaker.
+func (tscb *TwoStepCircuitBreaker) State() State {
+	return tscb.cb.State()
+}
+
+// Counts returns the counts in the window.
+func (tscb *TwoStepCircuitBreaker) Counts() Counts {
+	return tscb.cb.Counts()
+}
+
+// Breaker returns the circuitbreaker.Breaker that backs the TwoStepCircuitBreaker.
+func (tscb *TwoStepCircuitBreaker) Breaker() *circuitbreaker.Breaker {
+	return tscb.cb.breaker
+}
+
+// Allow checks if a request can proceed. It returns a callback that records whether the request
+// succeeded, or ErrOpenState or ErrTooManyRequests if the request is not allowed.
+func (tscb *TwoStepCircuitBreaker) Allow() (done func(success bool), err error) {
+	return tscb.cb.allow()
+}
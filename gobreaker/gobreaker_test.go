@@ -0,0 +1,87 @@
+package gobreaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	var changes []State
+
+	cb := NewCircuitBreaker(Settings{
+		Name:    "test",
+		Timeout: time.Millisecond,
+		OnStateChange: func(name string, from State, to State) {
+			require.Equal(t, "test", name)
+			changes = append(changes, to)
+		},
+	})
+	require.Equal(t, "test", cb.Name())
+
+	result, err := cb.Execute(func() (interface{}, error) { return "ok", nil })
+	require.NoError(t, err)
+	require.Equal(t, "ok", result)
+
+	fail := errors.New("fail")
+
+	for i := 0; i < 6; i++ {
+		_, err = cb.Execute(func() (interface{}, error) { return nil, fail })
+		require.ErrorIs(t, err, fail)
+	}
+
+	require.Equal(t, StateOpen, cb.State())
+	require.Equal(t, Counts{Requests: 7, TotalSuccesses: 1, TotalFailures: 6, ConsecutiveFailures: 6}, cb.Counts())
+
+	_, err = cb.Execute(func() (interface{}, error) { return nil, nil })
+	require.Equal(t, ErrOpenState, err)
+
+	// the timeout is rounded up to one second
+	time.Sleep(1100 * time.Millisecond)
+	require.Equal(t, StateHalfOpen, cb.State())
+
+	require.Panics(t, func() {
+		_, _ = cb.Execute(func() (interface{}, error) { panic("boom") })
+	})
+
+	require.Equal(t, StateOpen, cb.State())
+	require.Equal(t, []State{StateOpen, StateHalfOpen, StateOpen}, changes)
+}
+
+func TestTwoStepCircuitBreaker(t *testing.T) {
+	cb := NewTwoStepCircuitBreaker(Settings{
+		Name:        "test",
+		ReadyToTrip: func(c Counts) bool { return c.TotalFailures >= 2 },
+		IsSuccessful: func(err error) bool {
+			return err == nil
+		},
+	})
+
+	done, err := cb.Allow()
+	require.NoError(t, err)
+	done(false)
+	require.Equal(t, StateClosed, cb.State())
+
+	done, err = cb.Allow()
+	require.NoError(t, err)
+	done(false)
+	require.Equal(t, StateOpen, cb.State())
+
+	_, err = cb.Allow()
+	require.Equal(t, ErrOpenState, err)
+}
+
+func TestIsSuccessful(t *testing.T) {
+	notFound := errors.New("not found")
+
+	cb := NewCircuitBreaker(Settings{
+		ReadyToTrip:  func(c Counts) bool { return c.ConsecutiveFailures >= 1 },
+		IsSuccessful: func(err error) bool { return err == nil || errors.Is(err, notFound) },
+	})
+
+	_, err := cb.Execute(func() (interface{}, error) { return nil, notFound })
+	require.ErrorIs(t, err, notFound)
+	require.Equal(t, StateClosed, cb.State())
+}
@@ -0,0 +1,337 @@
+// Package gossip shares circuit breaker transitions between processes over hashicorp/memberlist,
+// for environments without a shared store such as Redis.
+//
+// Each process reports when its breakers open and close. When a quorum of peers report that
+// a breaker is open, the local breaker with the same name is tripped before it has spent its own failure budget.
+package gossip
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/memberlist"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+type options struct {
+	quorum float64
+}
+
+// Option sets Cluster options.
+type Option func(*options)
+
+// WithQuorum sets the fraction of peers that must report a breaker open before the local breaker is tripped.
+// Default is 0.5.
+func WithQuorum(fraction float64) Option {
+	return func(o *options) {
+		o.quorum = fraction
+	}
+}
+
+// Cluster shares the transitions of breakers with peers.
+type Cluster struct {
+	members  *memberlist.Memberlist
+	queue    *memberlist.TransmitLimitedQueue
+	breakers map[string]*circuitbreaker.Breaker
+	remove   map[string]func()
+	// reports is the latest report of each peer, by breaker name and peer.
+	reports map[string]map[string]message
+	// local is the latest report of each local breaker.
+	local   map[string]message
+	node    string
+	options options
+	lock    sync.Mutex
+}
+
+type message struct {
+	Time    time.Time `json:"time"`
+	Node    string    `json:"node"`
+	Breaker string    `json:"breaker"`
+	Open    bool      `json:"open"`
+}
+
+// New creates a Cluster using conf, which is typically memberlist.DefaultLANConfig with a unique Name.
+// The Delegate and Events of conf are replaced. Call Join to join peers.
+func New(conf *memberlist.Config, opts ...Option) (*Cluster, error) {
+	o := options{
+		quorum: 0.5,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	c := &Cluster{
+		breakers: make(map[string]*circuitbreaker.Breaker),
+		remove:   make(map[string]func()),
+		reports:  make(map[string]map[string]message),
+		local:    make(map[string]message),
+		node:     conf.Name,
+		options:  o,
+	}
+
+	c.queue = &memberlist.TransmitLimitedQueue{
+		NumNodes:       c.numMembers,
+		RetransmitMult: conf.RetransmitMult,
+	}
+
+	conf.Delegate = &delegate{cluster: c}
+	conf.Events = &events{cluster: c}
+
+	members, err := memberlist.Create(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	c.lock.Lock()
+	c.members = members
+	c.lock.Unlock()
+
+	return c, nil
+}
+
+func (c *Cluster) numMembers() int {
+	c.lock.Lock()
+	members := c.members
+	c.lock.Unlock()
+
+	if members == nil {
+		return 1
+	}
+
+	return members.NumMembers()
+}
+
+// Join joins the peers at the addresses and returns the number of peers contacted.
+func (c *Cluster) Join(addrs []string) (int, error) {
+	return c.members.Join(addrs)
+}
+
+// Close leaves the cluster, waiting up to timeout for peers to be notified, and stops the Cluster.
+func (c *Cluster) Close(timeout time.Duration) error {
+	c.lock.Lock()
+	remove := c.remove
+	c.remove = make(map[string]func())
+	c.lock.Unlock()
+
+	for _, fn := range remove {
+		fn()
+	}
+
+	if err := c.members.Leave(timeout); err != nil {
+		_ = c.members.Shutdown()
+		return err
+	}
+
+	return c.members.Shutdown()
+}
+
+// Add shares the transitions of b with peers, and trips b when a quorum of peers report their breaker
+// with the same name is open. b must have a name.
+func (c *Cluster) Add(b *circuitbreaker.Breaker) error {
+	name := b.Name()
+	if name == "" {
+		return circuitbreaker.ErrNoName
+	}
+
+	// subscribe before locking the cluster, since the subscriber locks the cluster while b is locked
+	unsubscribe := b.Subscribe(func(t circuitbreaker.Transition) {
+		c.transition(name, t)
+	})
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if fn, ok := c.remove[name]; ok {
+		fn()
+	}
+
+	c.breakers[name] = b
+	c.remove[name] = unsubscribe
+
+	return nil
+}
+
+// Remove stops sharing the transitions of the named breaker.
+func (c *Cluster) Remove(name string) {
+	c.lock.Lock()
+	fn, ok := c.remove[name]
+	delete(c.remove, name)
+	delete(c.breakers, name)
+	delete(c.local, name)
+	c.lock.Unlock()
+
+	if ok {
+		fn()
+	}
+}
+
+// transition is called while the breaker is locked.
+func (c *Cluster) transition(name string, t circuitbreaker.Transition) {
+	var open bool
+
+	switch {
+	case t.To == circuitbreaker.StateClosed:
+		open = false
+	// manual transitions, including trips caused by peers, are not reported so they do not count towards a quorum
+	case t.To == circuitbreaker.StateOpen && !t.Reason.Manual() && t.Reason != circuitbreaker.ReasonRestore:
+		open = true
+	default:
+		return
+	}
+
+	m := message{
+		Time:    t.Time,
+		Node:    c.node,
+		Breaker: name,
+		Open:    open,
+	}
+
+	data, err := json.Marshal(m)
+	if err != nil {
+		return
+	}
+
+	c.lock.Lock()
+	c.local[name] = m
+	c.lock.Unlock()
+
+	c.queue.QueueBroadcast(&broadcast{name: c.node + "/" + name, msg: data})
+}
+
+// receive records a peer's report and trips the local breaker if a quorum of peers report it open.
+func (c *Cluster) receive(m message) {
+	if m.Node == c.node {
+		return
+	}
+
+	c.lock.Lock()
+
+	reports, ok := c.reports[m.Breaker]
+	if !ok {
+		reports = make(map[string]message)
+		c.reports[m.Breaker] = reports
+	}
+
+	if prev, ok := reports[m.Node]; ok && prev.Time.After(m.Time) {
+		c.lock.Unlock()
+		return
+	}
+
+	reports[m.Node] = m
+
+	open := 0
+
+	for _, r := range reports {
+		if r.Open {
+			open++
+		}
+	}
+
+	peers := 0
+	if c.members != nil {
+		peers = c.members.NumMembers() - 1
+	}
+
+	b := c.breakers[m.Breaker]
+
+	c.lock.Unlock()
+
+	if b == nil || !m.Open || open == 0 || float64(open) < c.options.quorum*float64(peers) {
+		return
+	}
+
+	if s := b.State(); s == circuitbreaker.StateClosed || s == circuitbreaker.StateDegraded {
+		b.Trip()
+	}
+}
+
+func (c *Cluster) leave(node string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for _, reports := range c.reports {
+		delete(reports, node)
+	}
+}
+
+type broadcast struct {
+	name string
+	msg  []byte
+}
+
+func (b *broadcast) Invalidates(other memberlist.Broadcast) bool {
+	o, ok := other.(*broadcast)
+	return ok && o.name == b.name
+}
+
+func (b *broadcast) Name() string {
+	return b.name
+}
+
+func (b *broadcast) Message() []byte {
+	return b.msg
+}
+
+func (b *broadcast) Finished() {}
+
+type delegate struct {
+	cluster *Cluster
+}
+
+func (d *delegate) NodeMeta(int) []byte {
+	return nil
+}
+
+func (d *delegate) NotifyMsg(data []byte) {
+	var m message
+	if err := json.Unmarshal(data, &m); err != nil {
+		return
+	}
+
+	d.cluster.receive(m)
+}
+
+func (d *delegate) GetBroadcasts(overhead, limit int) [][]byte {
+	return d.cluster.queue.GetBroadcasts(overhead, limit)
+}
+
+func (d *delegate) LocalState(bool) []byte {
+	d.cluster.lock.Lock()
+
+	local := make([]message, 0, len(d.cluster.local))
+	for _, m := range d.cluster.local {
+		local = append(local, m)
+	}
+
+	d.cluster.lock.Unlock()
+
+	data, _ := json.Marshal(local)
+
+	return data
+}
+
+func (d *delegate) MergeRemoteState(data []byte, _ bool) {
+	var remote []message
+	if err := json.Unmarshal(data, &remote); err != nil {
+		return
+	}
+
+	for _, m := range remote {
+		d.cluster.receive(m)
+	}
+}
+
+type events struct {
+	cluster *Cluster
+}
+
+func (e *events) NotifyJoin(*memberlist.Node) {}
+
+func (e *events) NotifyLeave(n *memberlist.Node) {
+	e.cluster.leave(n.Name)
+}
+
+func (e *events) NotifyUpdate(*memberlist.Node) {}
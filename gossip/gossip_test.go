@@ -0,0 +1,147 @@
+package gossip
+
+import (
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/memberlist"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+func alwaysTrip(circuitbreaker.Counts) bool {
+	return true
+}
+
+func newCluster(t *testing.T, i int, opts ...Option) *Cluster {
+	conf := memberlist.DefaultLocalConfig()
+	conf.Name = fmt.Sprintf("node-%d", i)
+	conf.BindAddr = "127.0.0.1"
+	conf.BindPort = 0
+	conf.AdvertisePort = 0
+	conf.LogOutput = io.Discard
+	// exchange full state often, so a report whose broadcast was lost still reaches every peer
+	conf.PushPullInterval = 100 * time.Millisecond
+
+	c, err := New(conf, opts...)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = c.Close(time.Second)
+	})
+
+	return c
+}
+
+func newBreaker(t *testing.T) *circuitbreaker.Breaker {
+	b, err := circuitbreaker.New(
+		circuitbreaker.WithName("backend"),
+		circuitbreaker.WithReadyToTrip(alwaysTrip),
+		circuitbreaker.WithTimeout(time.Minute),
+	)
+	require.NoError(t, err)
+
+	return b
+}
+
+// waitForMembers waits until every cluster sees every other as alive, since reports are only
+// gossiped to members that are alive and may otherwise be lost.
+func waitForMembers(t *testing.T, clusters []*Cluster) {
+	require.Eventually(t, func() bool {
+		for _, c := range clusters {
+			members := c.members.Members()
+			if len(members) != len(clusters) {
+				return false
+			}
+
+			for _, m := range members {
+				if m.State != memberlist.StateAlive {
+					return false
+				}
+			}
+		}
+
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func fail(t *testing.T, b *circuitbreaker.Breaker) {
+	done, err := b.Allow()
+	require.NoError(t, err)
+	done(false)
+}
+
+func TestCluster(t *testing.T) {
+	clusters := make([]*Cluster, 3)
+	breakers := make([]*circuitbreaker.Breaker, 3)
+
+	for i := range clusters {
+		clusters[i] = newCluster(t, i)
+		breakers[i] = newBreaker(t)
+		require.NoError(t, clusters[i].Add(breakers[i]))
+	}
+
+	addr := clusters[0].members.LocalNode().Address()
+
+	for _, c := range clusters[1:] {
+		_, err := c.Join([]string{addr})
+		require.NoError(t, err)
+	}
+
+	waitForMembers(t, clusters)
+
+	// one of two peers is a quorum
+	fail(t, breakers[0])
+	require.Equal(t, circuitbreaker.StateOpen, breakers[0].State())
+
+	require.Eventually(t, func() bool {
+		return breakers[1].State() == circuitbreaker.StateOpen &&
+			breakers[2].State() == circuitbreaker.StateOpen
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestClusterQuorum(t *testing.T) {
+	clusters := make([]*Cluster, 3)
+	breakers := make([]*circuitbreaker.Breaker, 3)
+
+	for i := range clusters {
+		clusters[i] = newCluster(t, i, WithQuorum(1))
+		breakers[i] = newBreaker(t)
+		require.NoError(t, clusters[i].Add(breakers[i]))
+	}
+
+	addr := clusters[0].members.LocalNode().Address()
+
+	for _, c := range clusters[1:] {
+		_, err := c.Join([]string{addr})
+		require.NoError(t, err)
+	}
+
+	// every peer must know the others, as reports are only sent to known members
+	waitForMembers(t, clusters)
+
+	fail(t, breakers[0])
+
+	// a single peer is not a quorum, and peer-induced trips are not reported
+	require.Never(t, func() bool {
+		return breakers[2].State() != circuitbreaker.StateClosed
+	}, 500*time.Millisecond, 10*time.Millisecond)
+
+	fail(t, breakers[1])
+
+	require.Eventually(t, func() bool {
+		return breakers[2].State() == circuitbreaker.StateOpen
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestAddWithoutName(t *testing.T) {
+	c := newCluster(t, 0)
+
+	b, err := circuitbreaker.New()
+	require.NoError(t, err)
+
+	require.ErrorIs(t, c.Add(b), circuitbreaker.ErrNoName)
+}
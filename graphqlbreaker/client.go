@@ -0,0 +1,200 @@
+package graphqlbreaker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+// clientErrorCodes are error codes that mean the operation, rather than the server, is at fault.
+var clientErrorCodes = map[string]bool{
+	"GRAPHQL_PARSE_FAILED":      true,
+	"GRAPHQL_VALIDATION_FAILED": true,
+	"BAD_USER_INPUT":            true,
+	"UNAUTHENTICATED":           true,
+	"FORBIDDEN":                 true,
+	"PERSISTED_QUERY_NOT_FOUND": true,
+}
+
+// Error is an error in a GraphQL response.
+type Error struct {
+	Message    string         `json:"message"`
+	Path       []any          `json:"path,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+// Code returns the "code" extension of the error, if any.
+func (e Error) Code() string {
+	code, _ := e.Extensions["code"].(string)
+	return code
+}
+
+// Errors are the errors in a GraphQL response. They are returned by Client.Do.
+type Errors []Error
+
+// Error implements error.
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Message
+	}
+
+	return "graphql: " + strings.Join(messages, "; ")
+}
+
+// Response is a GraphQL response.
+type Response struct {
+	Data   json.RawMessage `json:"data"`
+	Errors Errors          `json:"errors,omitempty"`
+}
+
+// ResponseClassifier returns the outcome of an operation. resp is nil if err is a transport error.
+type ResponseClassifier func(status int, resp *Response, err error) circuitbreaker.Outcome
+
+// DefaultResponseClassifier classifies transport errors, 5xx and 429 responses, and responses without data
+// whose errors are not all client errors as failures. Client errors, such as validation failures and
+// BAD_USER_INPUT, and responses with partial data are successes since the server handled the operation.
+// Operations canceled by the caller are ignored.
+func DefaultResponseClassifier(status int, resp *Response, err error) circuitbreaker.Outcome {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return circuitbreaker.OutcomeIgnored
+	case resp == nil, status >= 500, status == http.StatusTooManyRequests:
+		return circuitbreaker.OutcomeFailure
+	}
+
+	if len(resp.Errors) == 0 || (len(resp.Data) > 0 && !bytes.Equal(resp.Data, []byte("null"))) {
+		return circuitbreaker.OutcomeSuccess
+	}
+
+	for _, e := range resp.Errors {
+		if !clientErrorCodes[e.Code()] {
+			return circuitbreaker.OutcomeFailure
+		}
+	}
+
+	return circuitbreaker.OutcomeSuccess
+}
+
+type clientOptions struct {
+	httpClient *http.Client
+	classifier ResponseClassifier
+}
+
+// ClientOption sets Client options.
+type ClientOption func(*clientOptions)
+
+// WithHTTPClient sets the HTTP client used to send operations. Default is http.DefaultClient.
+func WithHTTPClient(c *http.Client) ClientOption {
+	return func(o *clientOptions) {
+		o.httpClient = c
+	}
+}
+
+// WithResponseClassifier sets the classifier used to record the outcome of operations.
+// Default is DefaultResponseClassifier.
+func WithResponseClassifier(c ResponseClassifier) ClientOption {
+	return func(o *clientOptions) {
+		o.classifier = c
+	}
+}
+
+// Client sends GraphQL operations over HTTP, running each through the breaker from a Group
+// keyed by its operation name.
+type Client struct {
+	endpoint string
+	group    *circuitbreaker.Group[string]
+	options  clientOptions
+}
+
+// NewClient creates a Client that sends operations to endpoint using breakers from g.
+func NewClient(endpoint string, g *circuitbreaker.Group[string], opts ...ClientOption) *Client {
+	o := clientOptions{
+		httpClient: http.DefaultClient,
+		classifier: DefaultResponseClassifier,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return &Client{
+		endpoint: endpoint,
+		group:    g,
+		options:  o,
+	}
+}
+
+// Do sends an operation and decodes its data into out, which may be nil. If the response has errors,
+// they are returned as Errors after any data is decoded. When the breaker does not allow the operation,
+// the error from the breaker, such as a *circuitbreaker.OpenStateError, is returned.
+func (c *Client) Do(ctx context.Context, operationName, query string, variables map[string]any, out any) error {
+	done, err := c.group.Get(operationName).AllowOutcome()
+	if err != nil {
+		return err
+	}
+
+	status, resp, err := c.send(ctx, operationName, query, variables)
+	done(c.options.classifier(status, resp, err))
+
+	if err != nil {
+		return err
+	}
+
+	if out != nil && len(resp.Data) > 0 {
+		if err := json.Unmarshal(resp.Data, out); err != nil {
+			return fmt.Errorf("graphql: decoding data: %w", err)
+		}
+	}
+
+	if len(resp.Errors) > 0 {
+		return resp.Errors
+	}
+
+	return nil
+}
+
+func (c *Client) send(ctx context.Context, operationName, query string, variables map[string]any) (int, *Response, error) {
+	body, err := json.Marshal(map[string]any{
+		"operationName": operationName,
+		"query":         query,
+		"variables":     variables,
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/graphql-response+json, application/json")
+
+	res, err := c.options.httpClient.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return res.StatusCode, nil, err
+	}
+
+	var resp Response
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return res.StatusCode, nil, fmt.Errorf("graphql: %s: %w", res.Status, err)
+	}
+
+	return res.StatusCode, &resp, nil
+}
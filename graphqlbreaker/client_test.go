@@ -0,0 +1,98 @@
+package graphqlbreaker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+func TestDefaultResponseClassifier(t *testing.T) {
+	response := func(data string, codes ...string) *Response {
+		r := &Response{Data: json.RawMessage(data)}
+		for _, code := range codes {
+			r.Errors = append(r.Errors, Error{Message: code, Extensions: map[string]any{"code": code}})
+		}
+
+		return r
+	}
+
+	tests := []struct {
+		name   string
+		status int
+		resp   *Response
+		err    error
+		want   circuitbreaker.Outcome
+	}{
+		{"ok", 200, response(`{"user":{}}`), nil, circuitbreaker.OutcomeSuccess},
+		{"partial", 200, response(`{"user":null}`, "INTERNAL_SERVER_ERROR"), nil, circuitbreaker.OutcomeSuccess},
+		{"no data", 200, response(`null`, "INTERNAL_SERVER_ERROR"), nil, circuitbreaker.OutcomeFailure},
+		{"validation", 400, response(``, "GRAPHQL_VALIDATION_FAILED"), nil, circuitbreaker.OutcomeSuccess},
+		{"unavailable", 503, response(``), nil, circuitbreaker.OutcomeFailure},
+		{"transport", 0, nil, http.ErrHandlerTimeout, circuitbreaker.OutcomeFailure},
+		{"canceled", 0, nil, context.Canceled, circuitbreaker.OutcomeIgnored},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, DefaultResponseClassifier(tt.status, tt.resp, tt.err))
+		})
+	}
+}
+
+func TestClient(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			OperationName string `json:"operationName"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		if req.OperationName == "GetUser" {
+			_, _ = w.Write([]byte(`{"data":{"user":{"name":"a"}}}`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"data":null,"errors":[{"message":"orders unavailable"}]}`))
+	}))
+	defer svr.Close()
+
+	g, err := circuitbreaker.NewGroup[string](
+		circuitbreaker.WithWindow(time.Minute),
+		circuitbreaker.WithReadyToTrip(circuitbreaker.TripOnConsecutiveFailures(1)),
+	)
+	require.NoError(t, err)
+
+	c := NewClient(svr.URL, g)
+
+	var out struct {
+		User struct {
+			Name string `json:"name"`
+		} `json:"user"`
+	}
+
+	require.NoError(t, c.Do(context.Background(), "GetUser", "query GetUser { user { name } }", nil, &out))
+	require.Equal(t, "a", out.User.Name)
+
+	var errs Errors
+
+	err = c.Do(context.Background(), "GetOrders", "query GetOrders { orders { id } }", nil, nil)
+	require.ErrorAs(t, err, &errs)
+	require.Equal(t, "orders unavailable", errs[0].Message)
+
+	err = c.Do(context.Background(), "GetOrders", "query GetOrders { orders { id } }", nil, nil)
+	require.ErrorIs(t, err, circuitbreaker.ErrOpenState)
+
+	require.NoError(t, c.Do(context.Background(), "GetUser", "query GetUser { user { name } }", nil, nil))
+}
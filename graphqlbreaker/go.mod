@@ -0,0 +1,22 @@
+module github.com/bakins/circuitbreaker/graphqlbreaker
+
+go 1.26
+
+require (
+	github.com/99designs/gqlgen v0.17.95
+	github.com/bakins/circuitbreaker v0.0.0
+	github.com/stretchr/testify v1.12.1
+	github.com/vektah/gqlparser/v2 v2.5.37
+)
+
+require (
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/kr/text v0.2.0 // indirect
+	github.com/sosodev/duration v1.4.0 // indirect
+	go.yaml.in/yaml/v3 v3.0.5 // indirect
+	golang.org/x/sync v0.22.0 // indirect
+	golang.org/x/time v0.10.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/bakins/circuitbreaker => ../
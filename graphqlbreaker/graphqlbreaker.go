@@ -0,0 +1,106 @@
+// Package graphqlbreaker provides circuit breakers for GraphQL servers and clients.
+//
+// GraphQL serves every operation from a single endpoint, so a breaker keyed by URL opens for every operation
+// when one resolver's dependency fails. Extension is a gqlgen extension that runs each resolver through a
+// breaker keyed by its field, such as "Query.user", and Client runs each operation through a breaker keyed
+// by its operation name.
+package graphqlbreaker
+
+import (
+	"context"
+	"errors"
+
+	"github.com/99designs/gqlgen/graphql"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+// Classifier returns the outcome of a resolver that returned err.
+type Classifier func(err error) circuitbreaker.Outcome
+
+// DefaultClassifier classifies resolver errors as failures. Resolvers canceled by the caller are ignored.
+func DefaultClassifier(err error) circuitbreaker.Outcome {
+	switch {
+	case err == nil:
+		return circuitbreaker.OutcomeSuccess
+	case errors.Is(err, context.Canceled):
+		return circuitbreaker.OutcomeIgnored
+	default:
+		return circuitbreaker.OutcomeFailure
+	}
+}
+
+type options struct {
+	classifier Classifier
+}
+
+// Option sets Extension options.
+type Option func(*options)
+
+// WithClassifier sets the classifier used to record the outcome of resolvers.
+// Default is DefaultClassifier.
+func WithClassifier(c Classifier) Option {
+	return func(o *options) {
+		o.classifier = c
+	}
+}
+
+// Extension is a gqlgen extension that runs resolvers through the breaker from a Group keyed by
+// "Object.field". Fields without a resolver are not run through a breaker. When a breaker does not allow
+// a resolver, the breaker's error is returned as the field's error.
+//
+//	srv := handler.New(schema)
+//	srv.Use(graphqlbreaker.NewExtension(group))
+type Extension struct {
+	group   *circuitbreaker.Group[string]
+	options options
+}
+
+var (
+	_ graphql.HandlerExtension = &Extension{}
+	_ graphql.FieldInterceptor = &Extension{}
+)
+
+// NewExtension creates an Extension that uses breakers from g.
+func NewExtension(g *circuitbreaker.Group[string], opts ...Option) *Extension {
+	o := options{
+		classifier: DefaultClassifier,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return &Extension{
+		group:   g,
+		options: o,
+	}
+}
+
+// ExtensionName implements graphql.HandlerExtension.
+func (e *Extension) ExtensionName() string {
+	return "CircuitBreaker"
+}
+
+// Validate implements graphql.HandlerExtension.
+func (e *Extension) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptField implements graphql.FieldInterceptor.
+func (e *Extension) InterceptField(ctx context.Context, next graphql.Resolver) (any, error) {
+	fc := graphql.GetFieldContext(ctx)
+	if fc == nil || !fc.IsResolver {
+		return next(ctx)
+	}
+
+	done, err := e.group.Get(fc.Object + "." + fc.Field.Name).AllowOutcome()
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := next(ctx)
+	done(e.options.classifier(err))
+
+	return res, err
+}
@@ -0,0 +1,53 @@
+package graphqlbreaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2/ast"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+func fieldContext(object, field string, resolver bool) context.Context {
+	return graphql.WithFieldContext(context.Background(), &graphql.FieldContext{
+		Object:     object,
+		Field:      graphql.CollectedField{Field: &ast.Field{Name: field}},
+		IsResolver: resolver,
+	})
+}
+
+func TestExtension(t *testing.T) {
+	g, err := circuitbreaker.NewGroup[string](
+		circuitbreaker.WithWindow(time.Minute),
+		circuitbreaker.WithReadyToTrip(circuitbreaker.TripOnConsecutiveFailures(1)),
+	)
+	require.NoError(t, err)
+
+	e := NewExtension(g)
+
+	fail := errors.New("fail")
+	failing := func(context.Context) (any, error) { return nil, fail }
+	ok := func(context.Context) (any, error) { return "ok", nil }
+
+	_, err = e.InterceptField(fieldContext("Query", "user", true), failing)
+	require.ErrorIs(t, err, fail)
+	require.Equal(t, circuitbreaker.StateOpen, g.Get("Query.user").State())
+
+	_, err = e.InterceptField(fieldContext("Query", "user", true), ok)
+	require.ErrorIs(t, err, circuitbreaker.ErrOpenState)
+
+	// other resolvers are not affected
+	res, err := e.InterceptField(fieldContext("Query", "orders", true), ok)
+	require.NoError(t, err)
+	require.Equal(t, "ok", res)
+
+	// fields without a resolver are not run through a breaker
+	_, err = e.InterceptField(fieldContext("User", "name", false), failing)
+	require.ErrorIs(t, err, fail)
+	require.Equal(t, circuitbreaker.StateClosed, g.Get("User.name").State())
+}
@@ -0,0 +1,137 @@
+package circuitbreaker
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Group holds a Breaker per key, such as a host name. Breakers are created on first use.
+type Group[K comparable] struct {
+	breakers map[K]*Breaker
+	clock    Clock
+	name     string
+	options  []Option
+	adjusted Signal
+	lock     sync.RWMutex
+}
+
+// NewGroup creates a Group. Each Breaker is created using options and is named after its key.
+// If options include WithName, the name is used as a prefix, such as "name/key".
+// If options include WithExternalSignal, each signal is applied to every Breaker in the Group.
+// Breakers created later use the thresholds adjusted by earlier signals, but not their actions.
+func NewGroup[K comparable](options ...Option) (*Group[K], error) {
+	opts := Options{}
+
+	for _, o := range options {
+		o(&opts)
+	}
+
+	// validate the options once so Get does not need to return an error. A Breaker is not created,
+	// as options such as WithStore and WithExternalSignal start goroutines.
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	if opts.clock == nil {
+		opts.clock = realClock{}
+	}
+
+	g := &Group[K]{
+		breakers: make(map[K]*Breaker),
+		clock:    opts.clock,
+		name:     opts.name,
+		options:  options,
+	}
+
+	if opts.signals != nil {
+		go g.receiveSignals(opts.signals)
+	}
+
+	return g, nil
+}
+
+// receiveSignals fans out signals, as each Breaker receiving from the channel would only see some of them.
+func (g *Group[K]) receiveSignals(ch <-chan Signal) {
+	for s := range ch {
+		g.lock.Lock()
+
+		if s.ReadyToTrip != nil {
+			g.adjusted.ReadyToTrip = s.ReadyToTrip
+		}
+
+		if s.Timeout > 0 {
+			g.adjusted.Timeout = s.Timeout
+		}
+
+		if s.MaxRequests > 0 {
+			g.adjusted.MaxRequests = s.MaxRequests
+		}
+
+		g.lock.Unlock()
+
+		g.Range(func(_ K, b *Breaker) bool {
+			b.applySignal(s)
+			return true
+		})
+	}
+}
+
+// Get returns the Breaker for key, creating it if needed.
+func (g *Group[K]) Get(key K) *Breaker {
+	g.lock.RLock()
+	b, ok := g.breakers[key]
+	g.lock.RUnlock()
+
+	if ok {
+		return b
+	}
+
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if b, ok := g.breakers[key]; ok {
+		return b
+	}
+
+	name := fmt.Sprint(key)
+	if g.name != "" {
+		name = g.name + "/" + name
+	}
+
+	// the Group receives the signals for its Breakers
+	options := append(append([]Option{}, g.options...), WithName(name), WithExternalSignal(nil))
+
+	// options were validated in NewGroup
+	b, _ = New(options...)
+	b.adjust(g.adjusted)
+
+	g.breakers[key] = b
+
+	return b
+}
+
+// Delete removes the Breaker for key.
+func (g *Group[K]) Delete(key K) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	delete(g.breakers, key)
+}
+
+// Range calls fn for each Breaker in the Group until fn returns false.
+func (g *Group[K]) Range(fn func(key K, b *Breaker) bool) {
+	g.lock.RLock()
+
+	breakers := make(map[K]*Breaker, len(g.breakers))
+	for k, b := range g.breakers {
+		breakers[k] = b
+	}
+
+	g.lock.RUnlock()
+
+	for k, b := range breakers {
+		if !fn(k, b) {
+			return
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package circuitbreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroup(t *testing.T) {
+	g, err := NewGroup[string](WithName("hosts"))
+	require.NoError(t, err)
+
+	a := g.Get("a")
+	require.Equal(t, "hosts/a", a.Name())
+	require.Same(t, a, g.Get("a"))
+
+	b := g.Get("b")
+	require.NotSame(t, a, b)
+
+	a.Trip()
+	require.Equal(t, StateOpen, a.State())
+	require.Equal(t, StateClosed, b.State())
+
+	keys := map[string]bool{}
+	g.Range(func(k string, _ *Breaker) bool {
+		keys[k] = true
+		return true
+	})
+	require.Equal(t, map[string]bool{"a": true, "b": true}, keys)
+
+	g.Delete("a")
+	require.NotSame(t, a, g.Get("a"))
+
+	ints, err := NewGroup[int]()
+	require.NoError(t, err)
+	require.Equal(t, "42", ints.Get(42).Name())
+}
+
+func TestGroupSignal(t *testing.T) {
+	signals := make(chan Signal)
+	defer close(signals)
+
+	g, err := NewGroup[string](WithExternalSignal(signals))
+	require.NoError(t, err)
+
+	// thresholds adjusted before a Breaker is created are used by it
+	signals <- Signal{MaxRequests: 7}
+
+	a := g.Get("a")
+	b := g.Get("b")
+
+	require.Equal(t, uint64(7), a.Status().Options.MaxRequests)
+
+	// every signal reaches every Breaker
+	for range 3 {
+		signals <- Signal{Action: SignalTrip}
+
+		require.Eventually(t, func() bool {
+			return a.State() == StateOpen && b.State() == StateOpen
+		}, time.Second, time.Millisecond)
+
+		signals <- Signal{Action: SignalReset}
+
+		require.Eventually(t, func() bool {
+			return a.State() == StateClosed && b.State() == StateClosed
+		}, time.Second, time.Millisecond)
+	}
+}
@@ -0,0 +1,89 @@
+package grpcbreaker
+
+import (
+	"sort"
+	"sync/atomic"
+
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/status"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+// NewBalancerBuilder returns a round robin balancer.Builder that uses a breaker from the group per address,
+// keyed by the address. Addresses whose breakers do not allow an RPC are skipped, and the outcome of each RPC
+// is recorded in the breaker for the address it was sent to. When no address is allowed, the RPC fails
+// with the code set by WithRejectCode.
+//
+// Register the builder using balancer.Register and select it by name in the service config, such as
+//
+//	grpc.WithDefaultServiceConfig(`{"loadBalancingConfig": [{"circuit_breaker_round_robin": {}}]}`)
+func NewBalancerBuilder(name string, g *circuitbreaker.Group[string], opts ...Option) balancer.Builder {
+	return base.NewBalancerBuilder(name, &pickerBuilder{group: g, options: newOptions(opts)}, base.Config{HealthCheck: true})
+}
+
+type pickerBuilder struct {
+	group   *circuitbreaker.Group[string]
+	options options
+}
+
+type pickerEndpoint struct {
+	subConn balancer.SubConn
+	breaker *circuitbreaker.Breaker
+	addr    string
+}
+
+func (p *pickerBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
+	if len(info.ReadySCs) == 0 {
+		return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
+	}
+
+	endpoints := make([]pickerEndpoint, 0, len(info.ReadySCs))
+
+	for sc, sci := range info.ReadySCs {
+		endpoints = append(endpoints, pickerEndpoint{
+			subConn: sc,
+			breaker: p.group.Get(sci.Address.Addr),
+			addr:    sci.Address.Addr,
+		})
+	}
+
+	sort.Slice(endpoints, func(i, j int) bool {
+		return endpoints[i].addr < endpoints[j].addr
+	})
+
+	return &picker{
+		endpoints: endpoints,
+		options:   p.options,
+	}
+}
+
+type picker struct {
+	endpoints []pickerEndpoint
+	options   options
+	next      atomic.Uint32
+}
+
+func (p *picker) Pick(balancer.PickInfo) (balancer.PickResult, error) {
+	n := uint32(len(p.endpoints))
+	start := p.next.Add(1)
+
+	for i := uint32(0); i < n; i++ {
+		e := p.endpoints[(start+i)%n]
+
+		done, err := e.breaker.AllowOutcome()
+		if err != nil {
+			continue
+		}
+
+		return balancer.PickResult{
+			SubConn: e.subConn,
+			Done: func(info balancer.DoneInfo) {
+				done(p.options.classifier.classify(info.Err))
+			},
+		}, nil
+	}
+
+	return balancer.PickResult{}, status.Error(p.options.rejectCode, "circuit breakers do not allow requests to any address")
+}
@@ -0,0 +1,88 @@
+package grpcbreaker
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+func TestBalancer(t *testing.T) {
+	listeners := map[string]*bufconn.Listener{}
+
+	for addr, impl := range map[string]*testServer{
+		"good": {},
+		"bad":  {err: status.Error(codes.Unavailable, "unavailable")},
+	} {
+		svr := grpc.NewServer()
+		healthpb.RegisterHealthServer(svr, impl)
+
+		lis := bufconn.Listen(1024 * 1024)
+		listeners[addr] = lis
+
+		go func() {
+			_ = svr.Serve(lis)
+		}()
+
+		t.Cleanup(svr.Stop)
+	}
+
+	g, err := circuitbreaker.NewGroup[string](circuitbreaker.WithReadyToTrip(alwaysTrip))
+	require.NoError(t, err)
+
+	balancer.Register(NewBalancerBuilder("circuit_breaker_test", g))
+
+	r := manual.NewBuilderWithScheme("test")
+	r.InitialState(resolver.State{
+		Addresses: []resolver.Address{{Addr: "good"}, {Addr: "bad"}},
+	})
+
+	conn, err := grpc.NewClient("test:///svc",
+		grpc.WithResolvers(r),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultServiceConfig(`{"loadBalancingConfig": [{"circuit_breaker_test": {}}]}`),
+		grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			return listeners[addr].DialContext(ctx)
+		}),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	client := healthpb.NewHealthClient(conn)
+
+	ctx := context.Background()
+
+	// wait for both addresses to be ready
+	require.Eventually(t, func() bool {
+		_, err := client.Check(ctx, &healthpb.HealthCheckRequest{}, grpc.WaitForReady(true))
+		return err == nil && g.Get("bad").State() == circuitbreaker.StateOpen
+	}, 5*time.Second, time.Millisecond)
+
+	// all RPCs are routed to the good address
+	for i := 0; i < 10; i++ {
+		_, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+		require.NoError(t, err)
+	}
+
+	g.Get("good").Trip()
+
+	_, err = client.Check(ctx, &healthpb.HealthCheckRequest{})
+	require.Equal(t, codes.Unavailable, status.Code(err))
+	require.Contains(t, err.Error(), "do not allow requests")
+}
@@ -0,0 +1,50 @@
+package grpcbreaker
+
+import (
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+// Classifier returns the outcome of an RPC that completed with the status code.
+type Classifier func(codes.Code) circuitbreaker.Outcome
+
+// DefaultClassifier records Unavailable, DeadlineExceeded, ResourceExhausted, Internal, and Unknown as failures.
+// Canceled and InvalidArgument are ignored. All other codes, including OK, are successes.
+func DefaultClassifier(code codes.Code) circuitbreaker.Outcome {
+	switch code {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Internal, codes.Unknown:
+		return circuitbreaker.OutcomeFailure
+	case codes.Canceled, codes.InvalidArgument:
+		return circuitbreaker.OutcomeIgnored
+	default:
+		return circuitbreaker.OutcomeSuccess
+	}
+}
+
+// Codes returns a Classifier that records the failure codes as failures and the ignored
+// codes as ignored. All other codes are successes.
+func Codes(failures []codes.Code, ignored []codes.Code) Classifier {
+	outcomes := make(map[codes.Code]circuitbreaker.Outcome, len(failures)+len(ignored))
+
+	for _, c := range failures {
+		outcomes[c] = circuitbreaker.OutcomeFailure
+	}
+
+	for _, c := range ignored {
+		outcomes[c] = circuitbreaker.OutcomeIgnored
+	}
+
+	return func(code codes.Code) circuitbreaker.Outcome {
+		if o, ok := outcomes[code]; ok {
+			return o
+		}
+
+		return circuitbreaker.OutcomeSuccess
+	}
+}
+
+func (c Classifier) classify(err error) circuitbreaker.Outcome {
+	return c(status.Code(err))
+}
@@ -0,0 +1,208 @@
+// Package grpcbreaker provides gRPC interceptors for circuit breakers.
+package grpcbreaker
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+type options struct {
+	classifier Classifier
+	keyFunc    KeyFunc
+	rejectCode codes.Code
+}
+
+// KeyFunc returns the key used to choose the breaker for an RPC from a Group.
+type KeyFunc func(ctx context.Context, method string, cc *grpc.ClientConn) string
+
+// MethodKey returns the full method name, such as "/package.Service/Method".
+func MethodKey(_ context.Context, method string, _ *grpc.ClientConn) string {
+	return method
+}
+
+// TargetMethodKey returns the target of the connection and the full method name, such as
+// "dns:///example.com:443/package.Service/Method".
+func TargetMethodKey(_ context.Context, method string, cc *grpc.ClientConn) string {
+	return cc.Target() + method
+}
+
+// Option sets interceptor options.
+type Option func(*options)
+
+// WithClassifier sets the classifier used to record the outcome of RPCs.
+// Default is DefaultClassifier.
+func WithClassifier(c Classifier) Option {
+	return func(o *options) {
+		o.classifier = c
+	}
+}
+
+// WithKeyFunc sets the function used to choose the breaker for an RPC when using a Group.
+// Default is MethodKey.
+func WithKeyFunc(fn KeyFunc) Option {
+	return func(o *options) {
+		o.keyFunc = fn
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		classifier: DefaultClassifier,
+		keyFunc:    MethodKey,
+		rejectCode: codes.Unavailable,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// UnaryClientInterceptor returns an interceptor that runs each RPC through the breaker.
+// When the breaker does not allow an RPC, an Unavailable error is returned, see Error.
+func UnaryClientInterceptor(b *circuitbreaker.Breaker, opts ...Option) grpc.UnaryClientInterceptor {
+	return unaryClientInterceptor(func(context.Context, string, *grpc.ClientConn) *circuitbreaker.Breaker {
+		return b
+	}, newOptions(opts))
+}
+
+// UnaryClientInterceptorGroup returns an interceptor that runs each RPC through a breaker from the group,
+// so that a failing method does not open the breaker for every method. See WithKeyFunc.
+// Otherwise, it behaves like UnaryClientInterceptor.
+func UnaryClientInterceptorGroup(g *circuitbreaker.Group[string], opts ...Option) grpc.UnaryClientInterceptor {
+	o := newOptions(opts)
+
+	return unaryClientInterceptor(func(ctx context.Context, method string, cc *grpc.ClientConn) *circuitbreaker.Breaker {
+		return g.Get(o.keyFunc(ctx, method, cc))
+	}, o)
+}
+
+func unaryClientInterceptor(breaker func(context.Context, string, *grpc.ClientConn) *circuitbreaker.Breaker, o options) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		b := breaker(ctx, method, cc)
+
+		done, err := b.AllowOutcome()
+		if err != nil {
+			return Error(b, err)
+		}
+
+		err = invoker(ctx, method, req, reply, cc, callOpts...)
+
+		done(o.classifier.classify(err))
+
+		return err
+	}
+}
+
+// StreamClientInterceptor returns an interceptor that runs each stream through the breaker.
+// The outcome is recorded when the stream ends: when RecvMsg returns io.EOF or an error.
+// When the breaker does not allow a stream, an Unavailable error is returned, see Error.
+func StreamClientInterceptor(b *circuitbreaker.Breaker, opts ...Option) grpc.StreamClientInterceptor {
+	return streamClientInterceptor(func(context.Context, string, *grpc.ClientConn) *circuitbreaker.Breaker {
+		return b
+	}, newOptions(opts))
+}
+
+// StreamClientInterceptorGroup returns an interceptor that runs each stream through a breaker from the group,
+// so that a failing method does not open the breaker for every method. See WithKeyFunc.
+// Otherwise, it behaves like StreamClientInterceptor.
+func StreamClientInterceptorGroup(g *circuitbreaker.Group[string], opts ...Option) grpc.StreamClientInterceptor {
+	o := newOptions(opts)
+
+	return streamClientInterceptor(func(ctx context.Context, method string, cc *grpc.ClientConn) *circuitbreaker.Breaker {
+		return g.Get(o.keyFunc(ctx, method, cc))
+	}, o)
+}
+
+func streamClientInterceptor(breaker func(context.Context, string, *grpc.ClientConn) *circuitbreaker.Breaker, o options) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+		b := breaker(ctx, method, cc)
+
+		done, err := b.AllowOutcome()
+		if err != nil {
+			return nil, Error(b, err)
+		}
+
+		cs, err := streamer(ctx, desc, cc, method, callOpts...)
+		if err != nil {
+			done(o.classifier.classify(err))
+			return nil, err
+		}
+
+		return &clientStream{
+			ClientStream: cs,
+			done:         done,
+			classifier:   o.classifier,
+		}, nil
+	}
+}
+
+type clientStream struct {
+	grpc.ClientStream
+	done       func(circuitbreaker.Outcome)
+	classifier Classifier
+	once       sync.Once
+}
+
+func (s *clientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+
+	switch {
+	case err == nil:
+	case errors.Is(err, io.EOF):
+		s.once.Do(func() { s.done(circuitbreaker.OutcomeSuccess) })
+	default:
+		s.once.Do(func() { s.done(s.classifier.classify(err)) })
+	}
+
+	return err
+}
+
+// Error converts an error returned by the breaker into a gRPC status error with the code
+// Unavailable. The status includes an ErrorInfo detail with the reason "CIRCUIT_BREAKER_OPEN"
+// or "CIRCUIT_BREAKER_TOO_MANY_REQUESTS" and, when the breaker is open,
+// a RetryInfo detail with the time remaining until it becomes half-open.
+func Error(b *circuitbreaker.Breaker, err error) error {
+	return breakerStatus(b, codes.Unavailable, err).Err()
+}
+
+func breakerStatus(b *circuitbreaker.Breaker, code codes.Code, err error) *status.Status {
+	s := status.New(code, err.Error())
+
+	reason := "CIRCUIT_BREAKER_OPEN"
+	if errors.Is(err, circuitbreaker.ErrTooManyRequests) {
+		reason = "CIRCUIT_BREAKER_TOO_MANY_REQUESTS"
+	}
+
+	details := []protoadapt.MessageV1{
+		&errdetails.ErrorInfo{
+			Reason: reason,
+			Domain: "circuitbreaker",
+			Metadata: map[string]string{
+				"breaker": b.Name(),
+			},
+		},
+	}
+
+	if d := b.Status().RetryAfter; d > 0 {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(d)})
+	}
+
+	if withDetails, err := s.WithDetails(details...); err == nil {
+		return withDetails
+	}
+
+	return s
+}
@@ -0,0 +1,214 @@
+package grpcbreaker
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+func alwaysTrip(circuitbreaker.Counts) bool {
+	return true
+}
+
+// testServer returns err from every RPC.
+type testServer struct {
+	healthpb.UnimplementedHealthServer
+	err error
+}
+
+func (s *testServer) Check(context.Context, *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
+
+func (s *testServer) Watch(_ *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	if s.err != nil {
+		return s.err
+	}
+
+	return stream.Send(&healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING})
+}
+
+func dial(t *testing.T, svr *grpc.Server, opts ...grpc.DialOption) *grpc.ClientConn {
+	t.Helper()
+
+	lis := bufconn.Listen(1024 * 1024)
+
+	go func() {
+		_ = svr.Serve(lis)
+	}()
+
+	t.Cleanup(svr.Stop)
+
+	opts = append(opts,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+	)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet", opts...)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	return conn
+}
+
+func newTestClient(t *testing.T, impl *testServer, opts ...grpc.DialOption) healthpb.HealthClient {
+	svr := grpc.NewServer()
+	healthpb.RegisterHealthServer(svr, impl)
+
+	return healthpb.NewHealthClient(dial(t, svr, opts...))
+}
+
+func TestUnaryClientInterceptor(t *testing.T) {
+	b, err := circuitbreaker.New(circuitbreaker.WithName("test"), circuitbreaker.WithReadyToTrip(alwaysTrip), circuitbreaker.WithTimeout(time.Minute))
+	require.NoError(t, err)
+
+	impl := &testServer{}
+	client := newTestClient(t, impl, grpc.WithUnaryInterceptor(UnaryClientInterceptor(b)))
+
+	ctx := context.Background()
+
+	_, err = client.Check(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+
+	impl.err = status.Error(codes.InvalidArgument, "bad")
+
+	_, err = client.Check(ctx, &healthpb.HealthCheckRequest{})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	require.Equal(t, circuitbreaker.StateClosed, b.State())
+
+	impl.err = status.Error(codes.Unavailable, "down")
+
+	_, err = client.Check(ctx, &healthpb.HealthCheckRequest{})
+	require.Equal(t, codes.Unavailable, status.Code(err))
+	require.Equal(t, circuitbreaker.StateOpen, b.State())
+
+	_, err = client.Check(ctx, &healthpb.HealthCheckRequest{})
+
+	s := status.Convert(err)
+	require.Equal(t, codes.Unavailable, s.Code())
+
+	var (
+		info  *errdetails.ErrorInfo
+		retry *errdetails.RetryInfo
+	)
+
+	for _, d := range s.Details() {
+		switch d := d.(type) {
+		case *errdetails.ErrorInfo:
+			info = d
+		case *errdetails.RetryInfo:
+			retry = d
+		}
+	}
+
+	require.NotNil(t, info)
+	require.Equal(t, "CIRCUIT_BREAKER_OPEN", info.Reason)
+	require.Equal(t, "test", info.Metadata["breaker"])
+	require.NotNil(t, retry)
+	require.InDelta(t, time.Minute, retry.RetryDelay.AsDuration(), float64(time.Second))
+}
+
+func TestStreamClientInterceptor(t *testing.T) {
+	b, err := circuitbreaker.New(circuitbreaker.WithReadyToTrip(alwaysTrip))
+	require.NoError(t, err)
+
+	impl := &testServer{}
+	client := newTestClient(t, impl, grpc.WithStreamInterceptor(StreamClientInterceptor(b)))
+
+	ctx := context.Background()
+
+	recvAll := func() error {
+		stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{})
+		if err != nil {
+			return err
+		}
+
+		for {
+			if _, err := stream.Recv(); err != nil {
+				return err
+			}
+		}
+	}
+
+	err = recvAll()
+	require.ErrorIs(t, err, io.EOF)
+	require.Equal(t, uint64(1), b.Status().Counts.TotalSuccesses)
+
+	impl.err = status.Error(codes.DeadlineExceeded, "slow")
+
+	err = recvAll()
+	require.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	require.Equal(t, circuitbreaker.StateOpen, b.State())
+
+	err = recvAll()
+	require.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func TestCodes(t *testing.T) {
+	c := Codes([]codes.Code{codes.NotFound}, []codes.Code{codes.Unavailable})
+
+	require.Equal(t, circuitbreaker.OutcomeFailure, c(codes.NotFound))
+	require.Equal(t, circuitbreaker.OutcomeIgnored, c(codes.Unavailable))
+	require.Equal(t, circuitbreaker.OutcomeSuccess, c(codes.Internal))
+}
+
+func TestClientInterceptorGroup(t *testing.T) {
+	g, err := circuitbreaker.NewGroup[string](circuitbreaker.WithReadyToTrip(alwaysTrip))
+	require.NoError(t, err)
+
+	impl := &testServer{err: status.Error(codes.Unavailable, "down")}
+	client := newTestClient(t, impl,
+		grpc.WithUnaryInterceptor(UnaryClientInterceptorGroup(g)),
+		grpc.WithStreamInterceptor(StreamClientInterceptorGroup(g)),
+	)
+
+	ctx := context.Background()
+
+	_, err = client.Check(ctx, &healthpb.HealthCheckRequest{})
+	require.Equal(t, codes.Unavailable, status.Code(err))
+
+	check := g.Get(healthpb.Health_Check_FullMethodName)
+	require.Equal(t, circuitbreaker.StateOpen, check.State())
+
+	impl.err = nil
+
+	stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+
+	_, err = stream.Recv()
+	require.NoError(t, err)
+
+	watch := g.Get(healthpb.Health_Watch_FullMethodName)
+	require.Equal(t, circuitbreaker.StateClosed, watch.State())
+}
+
+func TestTargetMethodKey(t *testing.T) {
+	conn, err := grpc.NewClient("dns:///example.com:443", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+
+	defer conn.Close()
+
+	require.Equal(t, "dns:///example.com:443/pkg.Service/Method", TargetMethodKey(context.Background(), "/pkg.Service/Method", conn))
+}
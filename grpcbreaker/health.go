@@ -0,0 +1,123 @@
+package grpcbreaker
+
+import (
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+// Health is a grpc_health_v1 health server whose serving status for each service mirrors the state of
+// the breakers for its critical dependencies. A service is NOT_SERVING while any of its breakers is open,
+// so load balancers and mesh sidecars stop routing to an instance whose dependencies are down.
+// Call Close to stop watching the breakers.
+type Health struct {
+	server *health.Server
+
+	lock        sync.Mutex
+	states      map[*circuitbreaker.Breaker]circuitbreaker.State
+	services    map[string][]*circuitbreaker.Breaker
+	unsubscribe []func()
+}
+
+// NewHealth creates a Health that sets the serving status of server, or of a new health.Server if server is nil.
+func NewHealth(server *health.Server) *Health {
+	if server == nil {
+		server = health.NewServer()
+	}
+
+	return &Health{
+		server:   server,
+		states:   make(map[*circuitbreaker.Breaker]circuitbreaker.State),
+		services: make(map[string][]*circuitbreaker.Breaker),
+	}
+}
+
+// Server returns the health server.
+func (h *Health) Server() *health.Server {
+	return h.server
+}
+
+// Register registers the health server with s.
+func (h *Health) Register(s grpc.ServiceRegistrar) {
+	healthpb.RegisterHealthServer(s, h.server)
+}
+
+// Add sets the serving status of service from breakers. The empty service name is the status of the server.
+func (h *Health) Add(service string, breakers ...*circuitbreaker.Breaker) {
+	for _, b := range breakers {
+		h.watch(b)
+	}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.services[service] = append(h.services[service], breakers...)
+	h.update(service)
+}
+
+// Close stops watching the breakers. Serving statuses are left as they are.
+func (h *Health) Close() {
+	h.lock.Lock()
+	unsubscribe := h.unsubscribe
+	h.unsubscribe = nil
+	h.lock.Unlock()
+
+	for _, fn := range unsubscribe {
+		fn()
+	}
+}
+
+func (h *Health) watch(b *circuitbreaker.Breaker) {
+	h.lock.Lock()
+	_, ok := h.states[b]
+	h.lock.Unlock()
+
+	if ok {
+		return
+	}
+
+	// subscribe before reading the state so a transition is not missed
+	unsubscribe := b.Subscribe(func(t circuitbreaker.Transition) {
+		h.lock.Lock()
+		defer h.lock.Unlock()
+
+		h.states[b] = t.To
+
+		for service, breakers := range h.services {
+			for _, sb := range breakers {
+				if sb == b {
+					h.update(service)
+					break
+				}
+			}
+		}
+	})
+
+	state := b.State()
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if _, ok := h.states[b]; !ok {
+		h.states[b] = state
+	}
+
+	h.unsubscribe = append(h.unsubscribe, unsubscribe)
+}
+
+func (h *Health) update(service string) {
+	status := healthpb.HealthCheckResponse_SERVING
+
+	for _, b := range h.services[service] {
+		if h.states[b] == circuitbreaker.StateOpen {
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+			break
+		}
+	}
+
+	h.server.SetServingStatus(service, status)
+}
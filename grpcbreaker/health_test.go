@@ -0,0 +1,53 @@
+package grpcbreaker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+func TestHealth(t *testing.T) {
+	db, err := circuitbreaker.New(circuitbreaker.WithName("db"))
+	require.NoError(t, err)
+
+	cache, err := circuitbreaker.New(circuitbreaker.WithName("cache"))
+	require.NoError(t, err)
+
+	h := NewHealth(nil)
+	defer h.Close()
+
+	h.Add("users", db, cache)
+	h.Add("orders", db)
+
+	check := func(service string) healthpb.HealthCheckResponse_ServingStatus {
+		resp, err := h.Server().Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+		require.NoError(t, err)
+
+		return resp.GetStatus()
+	}
+
+	// transitions are delivered in the background
+	eventually := func(service string, status healthpb.HealthCheckResponse_ServingStatus) {
+		require.Eventually(t, func() bool { return check(service) == status }, 5*time.Second, time.Millisecond)
+	}
+
+	require.Equal(t, healthpb.HealthCheckResponse_SERVING, check("users"))
+
+	cache.Trip()
+	eventually("users", healthpb.HealthCheckResponse_NOT_SERVING)
+	require.Equal(t, healthpb.HealthCheckResponse_SERVING, check("orders"))
+
+	db.Trip()
+	cache.Reset()
+	eventually("orders", healthpb.HealthCheckResponse_NOT_SERVING)
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, check("users"))
+
+	db.Reset()
+	eventually("users", healthpb.HealthCheckResponse_SERVING)
+	eventually("orders", healthpb.HealthCheckResponse_SERVING)
+}
@@ -0,0 +1,76 @@
+package grpcbreaker
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+// WithRejectCode sets the status code returned by server interceptors when the breaker
+// does not allow an RPC, such as codes.ResourceExhausted.
+// Default is codes.Unavailable.
+func WithRejectCode(code codes.Code) Option {
+	return func(o *options) {
+		o.rejectCode = code
+	}
+}
+
+// UnaryServerInterceptor returns an interceptor that checks the breaker before calling the handler,
+// so that a server can shed load while a dependency it relies on is failing. The breaker
+// is typically shared with the client of that dependency.
+// The outcome of the handler is recorded using the Classifier, see WithClassifier.
+// A handler that panics is recorded as a failure.
+// When the breaker does not allow an RPC, an error is returned with the code set by
+// WithRejectCode and the details described in Error.
+func UnaryServerInterceptor(b *circuitbreaker.Breaker, opts ...Option) grpc.UnaryServerInterceptor {
+	o := newOptions(opts)
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		done, err := b.AllowOutcome()
+		if err != nil {
+			return nil, breakerStatus(b, o.rejectCode, err).Err()
+		}
+
+		defer func() {
+			if p := recover(); p != nil {
+				done(circuitbreaker.OutcomeFailure)
+				panic(p)
+			}
+		}()
+
+		resp, err := handler(ctx, req)
+
+		done(o.classifier.classify(err))
+
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns an interceptor that checks the breaker before calling the handler.
+// Otherwise, it behaves like UnaryServerInterceptor.
+func StreamServerInterceptor(b *circuitbreaker.Breaker, opts ...Option) grpc.StreamServerInterceptor {
+	o := newOptions(opts)
+
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		done, err := b.AllowOutcome()
+		if err != nil {
+			return breakerStatus(b, o.rejectCode, err).Err()
+		}
+
+		defer func() {
+			if p := recover(); p != nil {
+				done(circuitbreaker.OutcomeFailure)
+				panic(p)
+			}
+		}()
+
+		err = handler(srv, ss)
+
+		done(o.classifier.classify(err))
+
+		return err
+	}
+}
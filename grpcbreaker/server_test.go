@@ -0,0 +1,65 @@
+package grpcbreaker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+func TestServerInterceptors(t *testing.T) {
+	b, err := circuitbreaker.New(circuitbreaker.WithReadyToTrip(alwaysTrip), circuitbreaker.WithTimeout(time.Minute))
+	require.NoError(t, err)
+
+	impl := &testServer{}
+
+	svr := grpc.NewServer(
+		grpc.UnaryInterceptor(UnaryServerInterceptor(b, WithRejectCode(codes.ResourceExhausted))),
+		grpc.StreamInterceptor(StreamServerInterceptor(b)),
+	)
+	healthpb.RegisterHealthServer(svr, impl)
+
+	client := healthpb.NewHealthClient(dial(t, svr))
+
+	ctx := context.Background()
+
+	_, err = client.Check(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+
+	impl.err = status.Error(codes.Unavailable, "database is down")
+
+	_, err = client.Check(ctx, &healthpb.HealthCheckRequest{})
+	require.Equal(t, codes.Unavailable, status.Code(err))
+	require.Equal(t, circuitbreaker.StateOpen, b.State())
+
+	impl.err = nil
+
+	_, err = client.Check(ctx, &healthpb.HealthCheckRequest{})
+
+	s := status.Convert(err)
+	require.Equal(t, codes.ResourceExhausted, s.Code())
+
+	var retry *errdetails.RetryInfo
+
+	for _, d := range s.Details() {
+		if d, ok := d.(*errdetails.RetryInfo); ok {
+			retry = d
+		}
+	}
+
+	require.NotNil(t, retry)
+
+	stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+
+	_, err = stream.Recv()
+	require.Equal(t, codes.Unavailable, status.Code(err))
+}
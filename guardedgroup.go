@@ -0,0 +1,72 @@
+package circuitbreaker
+
+import (
+	"context"
+	"sync"
+)
+
+// GuardedGroup runs tasks in goroutines, like errgroup.Group, but only launches tasks the Breaker allows.
+// The first task error or rejection cancels the group's context, after which no further tasks are launched.
+type GuardedGroup struct {
+	breaker *Breaker
+	ctx     context.Context
+	cancel  context.CancelCauseFunc
+	wg      sync.WaitGroup
+	once    sync.Once
+	err     error
+}
+
+// NewGuardedGroup creates a GuardedGroup that checks b before launching each task. The returned context
+// is passed to tasks and is canceled when a task fails, a task is rejected, or Wait returns.
+func NewGuardedGroup(ctx context.Context, b *Breaker) (*GuardedGroup, context.Context) {
+	ctx, cancel := context.WithCancelCause(ctx)
+
+	return &GuardedGroup{
+		breaker: b,
+		ctx:     ctx,
+		cancel:  cancel,
+	}, ctx
+}
+
+// Go calls fn in a new goroutine if the Breaker allows it and records its outcome as Execute would.
+// If the Breaker rejects the task, fn is not called and the rejection is returned by Wait.
+// Go does nothing once the group's context is done.
+func (g *GuardedGroup) Go(fn func(ctx context.Context) error) {
+	if g.ctx.Err() != nil {
+		return
+	}
+
+	done, err := g.breaker.AllowOutcome()
+	if err != nil {
+		g.fail(err)
+		return
+	}
+
+	g.wg.Add(1)
+
+	go func() {
+		defer g.wg.Done()
+
+		err := fn(g.ctx)
+		done(outcomeOf(g.ctx, err))
+
+		if err != nil {
+			g.fail(err)
+		}
+	}()
+}
+
+// Wait waits for launched tasks to return and returns the first task error or rejection, if any.
+func (g *GuardedGroup) Wait() error {
+	g.wg.Wait()
+	g.cancel(g.err)
+
+	return g.err
+}
+
+func (g *GuardedGroup) fail(err error) {
+	g.once.Do(func() {
+		g.err = err
+		g.cancel(err)
+	})
+}
@@ -0,0 +1,64 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGuardedGroup(t *testing.T) {
+	b, err := New(WithWindow(time.Minute))
+	require.NoError(t, err)
+
+	g, ctx := NewGuardedGroup(context.Background(), b)
+
+	for i := 0; i < 5; i++ {
+		g.Go(func(context.Context) error { return nil })
+	}
+
+	require.NoError(t, g.Wait())
+	require.Error(t, ctx.Err())
+	require.Equal(t, uint64(5), b.Status().Counts.TotalSuccesses)
+
+	// a failure cancels the context of the remaining tasks
+	fail := errors.New("fail")
+
+	g, ctx = NewGuardedGroup(context.Background(), b)
+
+	g.Go(func(context.Context) error { return fail })
+	g.Go(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	require.ErrorIs(t, g.Wait(), fail)
+	require.ErrorIs(t, context.Cause(ctx), fail)
+
+	counts := b.Status().Counts
+	require.Equal(t, uint64(1), counts.TotalFailures)
+	require.Equal(t, uint64(5), counts.TotalSuccesses)
+}
+
+func TestGuardedGroupOpen(t *testing.T) {
+	b, err := New(WithWindow(time.Minute))
+	require.NoError(t, err)
+
+	b.Trip()
+
+	g, _ := NewGuardedGroup(context.Background(), b)
+
+	var called bool
+
+	for i := 0; i < 5; i++ {
+		g.Go(func(context.Context) error {
+			called = true
+			return nil
+		})
+	}
+
+	require.ErrorIs(t, g.Wait(), ErrOpenState)
+	require.False(t, called)
+}
@@ -0,0 +1,15 @@
+package circuitbreaker
+
+// Health returns a score between 0.0 and 1.0 describing the health of the Breaker.
+// It is the ratio of successes to outcomes in the rolling window, smoothed with a
+// single prior success so that a Breaker with few requests is not scored at an extreme.
+// An open Breaker has a health of 0.0.
+func (b *Breaker) Health() float64 {
+	if b.State() == StateOpen {
+		return 0
+	}
+
+	c := b.counts()
+
+	return float64(c.TotalSuccesses+1) / float64(c.TotalSuccesses+c.TotalFailures+1)
+}
@@ -0,0 +1,29 @@
+package circuitbreaker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealth(t *testing.T) {
+	b, err := New()
+	require.NoError(t, err)
+
+	require.Equal(t, 1.0, b.Health())
+
+	for i := 0; i < 3; i++ {
+		cb, err := b.Allow()
+		require.NoError(t, err)
+		cb(true)
+	}
+
+	cb, err := b.Allow()
+	require.NoError(t, err)
+	cb(false)
+
+	require.Equal(t, 0.8, b.Health())
+
+	b.Trip()
+	require.Equal(t, 0.0, b.Health())
+}
@@ -0,0 +1,186 @@
+package circuitbreaker
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Reason describes what triggered a state transition.
+type Reason int
+
+// Transition reasons
+const (
+	ReasonReadyToTrip Reason = iota
+	ReasonTimeout
+	ReasonHalfOpenFailure
+	ReasonHalfOpenSuccess
+	ReasonForceOpen
+	ReasonForceClose
+	ReasonReset
+	ReasonTrip
+	ReasonRestore
+	// ReasonChaos is the reason in the OpenStateError of a request rejected by WithChaos.
+	// It is not used for transitions.
+	ReasonChaos
+	// ReasonDegraded is the reason for a transition to StateDegraded.
+	ReasonDegraded
+	// ReasonRecovered is the reason for a transition from StateDegraded to StateClosed.
+	ReasonRecovered
+)
+
+// String returns a string representation of the reason.
+func (r Reason) String() string {
+	switch r {
+	case ReasonReadyToTrip:
+		return "ready to trip"
+	case ReasonTimeout:
+		return "timeout"
+	case ReasonHalfOpenFailure:
+		return "half-open failure"
+	case ReasonHalfOpenSuccess:
+		return "half-open success"
+	case ReasonForceOpen:
+		return "force open"
+	case ReasonForceClose:
+		return "force close"
+	case ReasonReset:
+		return "reset"
+	case ReasonTrip:
+		return "trip"
+	case ReasonRestore:
+		return "restore"
+	case ReasonChaos:
+		return "chaos"
+	case ReasonDegraded:
+		return "degraded"
+	case ReasonRecovered:
+		return "recovered"
+	default:
+		return fmt.Sprintf("unknown reason: %d", r)
+	}
+}
+
+// Manual returns true if the transition was requested by calling
+// Trip, ForceOpen, ForceClose, or Reset.
+func (r Reason) Manual() bool {
+	switch r {
+	case ReasonForceOpen, ReasonForceClose, ReasonReset, ReasonTrip:
+		return true
+	default:
+		return false
+	}
+}
+
+// Transition records a change of the Breaker state.
+type Transition struct {
+	Time   time.Time
+	From   State
+	To     State
+	Counts Counts
+	Reason Reason
+	// Condition is the name of the trip condition that fired when Reason is ReasonReadyToTrip.
+	Condition string
+}
+
+type jsonTransition struct {
+	Time      time.Time  `json:"time"`
+	From      string     `json:"from"`
+	To        string     `json:"to"`
+	Reason    string     `json:"reason"`
+	Condition string     `json:"condition,omitempty"`
+	Counts    jsonCounts `json:"counts"`
+	Manual    bool       `json:"manual"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (t Transition) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonTransition{
+		Time:      t.Time,
+		From:      t.From.String(),
+		To:        t.To.String(),
+		Reason:    t.Reason.String(),
+		Condition: t.Condition,
+		Counts:    jsonCounts(t.Counts),
+		Manual:    t.Reason.Manual(),
+	})
+}
+
+// WithHistorySize sets the number of state transitions kept by the Breaker.
+// Default is 10.
+func WithHistorySize(size int) Option {
+	return func(o *Options) {
+		o.historySize = size
+	}
+}
+
+// History returns the most recent state transitions, oldest first.
+func (b *Breaker) History() []Transition {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.history.list()
+}
+
+// Subscribe registers a function that is called whenever the state of the Breaker changes.
+// Like the function set by WithOnTransition, it is called in the background and never dropped, see
+// WithSynchronousObservers, and it may be called for a transition that happened before it unsubscribed.
+// Call the returned function to unsubscribe.
+func (b *Breaker) Subscribe(fn OnTransition) func() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.subscribers == nil {
+		b.subscribers = make(map[uint64]OnTransition)
+	}
+
+	id := b.nextSubscriber
+	b.nextSubscriber++
+
+	b.subscribers[id] = fn
+
+	return func() {
+		b.lock.Lock()
+		defer b.lock.Unlock()
+
+		delete(b.subscribers, id)
+	}
+}
+
+// history is a fixed size ring of transitions.
+type history struct {
+	transitions []Transition
+	next        int
+	full        bool
+}
+
+func newHistory(size int) *history {
+	return &history{
+		transitions: make([]Transition, size),
+	}
+}
+
+func (h *history) add(t Transition) {
+	h.transitions[h.next] = t
+
+	h.next++
+	if h.next == len(h.transitions) {
+		h.next = 0
+		h.full = true
+	}
+}
+
+func (h *history) list() []Transition {
+	if !h.full {
+		out := make([]Transition, h.next)
+		copy(out, h.transitions[:h.next])
+
+		return out
+	}
+
+	out := make([]Transition, 0, len(h.transitions))
+	out = append(out, h.transitions[h.next:]...)
+	out = append(out, h.transitions[:h.next]...)
+
+	return out
+}
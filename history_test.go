@@ -0,0 +1,88 @@
+package circuitbreaker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistory(t *testing.T) {
+	b, err := New(WithHistorySize(2))
+	require.NoError(t, err)
+
+	require.Empty(t, b.History())
+
+	b.ForceOpen()
+
+	h := b.History()
+	require.Len(t, h, 1)
+	require.Equal(t, StateClosed, h[0].From)
+	require.Equal(t, StateOpen, h[0].To)
+	require.Equal(t, ReasonForceOpen, h[0].Reason)
+
+	b.ForceClose()
+	b.ForceOpen()
+
+	h = b.History()
+	require.Len(t, h, 2)
+	require.Equal(t, ReasonForceClose, h[0].Reason)
+	require.Equal(t, ReasonForceOpen, h[1].Reason)
+	require.False(t, h[1].Time.Before(h[0].Time))
+}
+
+func TestHistoryReadyToTrip(t *testing.T) {
+	readyToTrip := func(c Counts) bool {
+		return true
+	}
+
+	b, err := New(WithReadyToTrip(readyToTrip))
+	require.NoError(t, err)
+
+	cb, err := b.Allow()
+	require.NoError(t, err)
+
+	cb(false)
+
+	h := b.History()
+	require.Len(t, h, 1)
+	require.Equal(t, ReasonReadyToTrip, h[0].Reason)
+	require.Equal(t, uint64(1), h[0].Counts.TotalFailures)
+}
+
+func TestTripReason(t *testing.T) {
+	var transitions []Transition
+
+	b, err := New(
+		WithTripCondition("always", func(c Counts) bool { return true }),
+		WithOnTransition(func(t Transition) { transitions = append(transitions, t) }),
+		WithSynchronousObservers(),
+	)
+	require.NoError(t, err)
+
+	cb, err := b.Allow()
+	require.NoError(t, err)
+
+	cb(false)
+
+	require.Len(t, transitions, 1)
+	require.Equal(t, ReasonReadyToTrip, transitions[0].Reason)
+	require.Equal(t, "always", transitions[0].Condition)
+	require.False(t, transitions[0].Reason.Manual())
+
+	_, err = b.Allow()
+	require.ErrorIs(t, err, ErrOpenState)
+
+	var openErr *OpenStateError
+	require.ErrorAs(t, err, &openErr)
+	require.Equal(t, "always", openErr.Transition.Condition)
+	require.Equal(t, uint64(1), openErr.Transition.Counts.TotalFailures)
+
+	b.Reset()
+	b.Trip()
+
+	_, err = b.Allow()
+	require.ErrorAs(t, err, &openErr)
+	require.Equal(t, ReasonTrip, openErr.Transition.Reason)
+	require.True(t, openErr.Transition.Reason.Manual())
+	require.Equal(t, b.History(), transitions)
+}
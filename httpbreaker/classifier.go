@@ -0,0 +1,90 @@
+package httpbreaker
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+// StatusClassifier classifies the result of an HTTP request as a circuitbreaker.Outcome.
+// The zero value uses the defaults described on each field.
+type StatusClassifier struct {
+	// FailureStatus reports whether a response status code is a failure.
+	// Default is DefaultFailureStatus.
+	FailureStatus func(code int) bool
+	// IgnoreStatus reports whether a response status code is ignored rather than
+	// recorded as a success or failure. It is checked before FailureStatus.
+	// Default is to ignore no status codes.
+	IgnoreStatus func(code int) bool
+	// IgnoreTransportErrors causes transport errors, such as connection refused,
+	// to be ignored rather than recorded as failures.
+	IgnoreTransportErrors bool
+	// IgnoreDeadlineExceeded causes requests that fail because their context deadline
+	// was exceeded to be ignored rather than recorded as failures.
+	IgnoreDeadlineExceeded bool
+}
+
+// DefaultFailureStatus returns true for 5xx and 429 Too Many Requests status codes.
+func DefaultFailureStatus(code int) bool {
+	return code >= 500 || code == http.StatusTooManyRequests
+}
+
+// StatusCodes returns a function that returns true for any of the status codes.
+// It may be used for StatusClassifier.FailureStatus and StatusClassifier.IgnoreStatus.
+func StatusCodes(codes ...int) func(int) bool {
+	set := make(map[int]bool, len(codes))
+	for _, c := range codes {
+		set[c] = true
+	}
+
+	return func(code int) bool {
+		return set[code]
+	}
+}
+
+// Classify returns the outcome of a request. Requests canceled by the caller are always ignored.
+func (c StatusClassifier) Classify(resp *http.Response, err error) circuitbreaker.Outcome {
+	if err != nil {
+		switch {
+		case errors.Is(err, context.Canceled):
+			return circuitbreaker.OutcomeIgnored
+		case errors.Is(err, context.DeadlineExceeded):
+			if c.IgnoreDeadlineExceeded {
+				return circuitbreaker.OutcomeIgnored
+			}
+		case c.IgnoreTransportErrors:
+			return circuitbreaker.OutcomeIgnored
+		}
+
+		return circuitbreaker.OutcomeFailure
+	}
+
+	return c.classifyStatus(resp.StatusCode)
+}
+
+func (c StatusClassifier) classifyStatus(code int) circuitbreaker.Outcome {
+	if c.IgnoreStatus != nil && c.IgnoreStatus(code) {
+		return circuitbreaker.OutcomeIgnored
+	}
+
+	failure := c.FailureStatus
+	if failure == nil {
+		failure = DefaultFailureStatus
+	}
+
+	if failure(code) {
+		return circuitbreaker.OutcomeFailure
+	}
+
+	return circuitbreaker.OutcomeSuccess
+}
+
+// WithStatusClassifier sets the classifier used to record the outcome of requests.
+// Default is the zero StatusClassifier.
+func WithStatusClassifier(c StatusClassifier) Option {
+	return func(o *options) {
+		o.classifier = c
+	}
+}
@@ -0,0 +1,51 @@
+package httpbreaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+func TestStatusClassifier(t *testing.T) {
+	response := func(code int) *http.Response {
+		return &http.Response{StatusCode: code}
+	}
+
+	transportErr := errors.New("connection refused")
+	deadlineErr := fmt.Errorf("get: %w", context.DeadlineExceeded)
+	canceledErr := fmt.Errorf("get: %w", context.Canceled)
+
+	tests := []struct {
+		name       string
+		classifier StatusClassifier
+		resp       *http.Response
+		err        error
+		want       circuitbreaker.Outcome
+	}{
+		{"ok", StatusClassifier{}, response(200), nil, circuitbreaker.OutcomeSuccess},
+		{"not found", StatusClassifier{}, response(404), nil, circuitbreaker.OutcomeSuccess},
+		{"too many requests", StatusClassifier{}, response(429), nil, circuitbreaker.OutcomeFailure},
+		{"server error", StatusClassifier{}, response(500), nil, circuitbreaker.OutcomeFailure},
+		{"transport error", StatusClassifier{}, nil, transportErr, circuitbreaker.OutcomeFailure},
+		{"deadline", StatusClassifier{}, nil, deadlineErr, circuitbreaker.OutcomeFailure},
+		{"canceled", StatusClassifier{}, nil, canceledErr, circuitbreaker.OutcomeIgnored},
+		{"ignored status", StatusClassifier{IgnoreStatus: StatusCodes(501)}, response(501), nil, circuitbreaker.OutcomeIgnored},
+		{"custom failure", StatusClassifier{FailureStatus: StatusCodes(404)}, response(404), nil, circuitbreaker.OutcomeFailure},
+		{"custom success", StatusClassifier{FailureStatus: StatusCodes(404)}, response(500), nil, circuitbreaker.OutcomeSuccess},
+		{"ignore transport", StatusClassifier{IgnoreTransportErrors: true}, nil, transportErr, circuitbreaker.OutcomeIgnored},
+		{"ignore transport deadline", StatusClassifier{IgnoreTransportErrors: true}, nil, deadlineErr, circuitbreaker.OutcomeFailure},
+		{"ignore deadline", StatusClassifier{IgnoreDeadlineExceeded: true}, nil, deadlineErr, circuitbreaker.OutcomeIgnored},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.classifier.Classify(tt.resp, tt.err))
+		})
+	}
+}
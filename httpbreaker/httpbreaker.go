@@ -0,0 +1,255 @@
+// Package httpbreaker provides net/http integrations for circuit breakers.
+package httpbreaker
+
+import (
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+type options struct {
+	classifier         StatusClassifier
+	keyFunc            func(*http.Request) string
+	rejectHandler      func(w http.ResponseWriter, r *http.Request, err error)
+	writeBreaker       *circuitbreaker.Breaker
+	maxRetryAfter      time.Duration
+	retryAfter         bool
+	serviceUnavailable bool
+}
+
+// Option sets httpbreaker options.
+type Option func(*options)
+
+// WithServiceUnavailable causes a RoundTripper to return a synthesized
+// 503 Service Unavailable response, rather than an error, when the breaker
+// does not allow the request.
+func WithServiceUnavailable() Option {
+	return func(o *options) {
+		o.serviceUnavailable = true
+	}
+}
+
+// WithRetryAfter causes a RoundTripper to open the breaker when a 429 or 503 response
+// includes a Retry-After header. The breaker stays open for the duration requested by the
+// server, limited to max, or the breaker timeout if it is longer. A max of zero means no limit.
+func WithRetryAfter(max time.Duration) Option {
+	return func(o *options) {
+		o.retryAfter = true
+		o.maxRetryAfter = max
+	}
+}
+
+// WithKeyFunc sets the function used to choose the breaker for a request
+// when using a Group. Default is the host of the request URL.
+func WithKeyFunc(fn func(*http.Request) string) Option {
+	return func(o *options) {
+		o.keyFunc = fn
+	}
+}
+
+// WithRejectHandler sets the function that writes the response when middleware does not allow a request,
+// such as one that writes a JSON body. The Retry-After header is set before it is called. The handler should
+// respond with 503 Service Unavailable. Default is a plain text body with the breaker's error.
+func WithRejectHandler(fn func(w http.ResponseWriter, r *http.Request, err error)) Option {
+	return func(o *options) {
+		o.rejectHandler = fn
+	}
+}
+
+// WithWriteBreaker runs requests with methods that are not safe, such as POST and DELETE, through b rather than
+// the breaker passed to NewRoundTripper or NewInbound, so writes can have stricter thresholds than reads.
+// GET, HEAD, OPTIONS, and TRACE requests are reads. It is ignored by NewGroupRoundTripper and NewGroupInbound,
+// which choose breakers by key.
+func WithWriteBreaker(b *circuitbreaker.Breaker) Option {
+	return func(o *options) {
+		o.writeBreaker = b
+	}
+}
+
+// HostKey returns the host, including any port, of the request URL.
+func HostKey(req *http.Request) string {
+	return req.URL.Host
+}
+
+// readWrite returns a function that chooses b for reads and the write breaker, if any, for writes.
+func (o options) readWrite(b *circuitbreaker.Breaker) func(*http.Request) *circuitbreaker.Breaker {
+	return func(req *http.Request) *circuitbreaker.Breaker {
+		if o.writeBreaker != nil && !safeMethod(req.Method) {
+			return o.writeBreaker
+		}
+
+		return b
+	}
+}
+
+// safeMethod reports whether method is a read.
+func safeMethod(method string) bool {
+	switch method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	default:
+		return false
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		keyFunc: HostKey,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// RoundTripper is an http.RoundTripper that runs requests through a circuit breaker.
+type RoundTripper struct {
+	next    http.RoundTripper
+	breaker func(*http.Request) *circuitbreaker.Breaker
+	options options
+}
+
+// NewRoundTripper creates a RoundTripper that runs each request through the breaker before
+// passing it to next. If next is nil, http.DefaultTransport is used.
+// Outcomes are recorded using the StatusClassifier, see WithStatusClassifier.
+// When the breaker does not allow a request, the error from the breaker, such as
+// a *circuitbreaker.OpenStateError, is returned.
+func NewRoundTripper(next http.RoundTripper, b *circuitbreaker.Breaker, opts ...Option) *RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	o := newOptions(opts)
+
+	return &RoundTripper{
+		next:    next,
+		breaker: o.readWrite(b),
+		options: o,
+	}
+}
+
+// NewGroupRoundTripper creates a RoundTripper that uses a breaker per key from the group,
+// so that a failing upstream does not open the breaker for all requests.
+// See WithKeyFunc. Otherwise, it behaves like NewRoundTripper.
+func NewGroupRoundTripper(next http.RoundTripper, g *circuitbreaker.Group[string], opts ...Option) *RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	o := newOptions(opts)
+
+	return &RoundTripper{
+		next: next,
+		breaker: func(req *http.Request) *circuitbreaker.Breaker {
+			return g.Get(o.keyFunc(req))
+		},
+		options: o,
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (rt *RoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	b := rt.breaker(req)
+
+	done, err := b.AllowOutcome()
+	if err != nil {
+		if req.Body != nil {
+			_ = req.Body.Close()
+		}
+
+		if rt.options.serviceUnavailable {
+			return serviceUnavailable(req, b, err), nil
+		}
+
+		return nil, err
+	}
+
+	resp, err := rt.next.RoundTrip(req)
+
+	done(rt.options.classifier.Classify(resp, err))
+
+	if err == nil && rt.options.retryAfter {
+		rt.honorRetryAfter(b, resp)
+	}
+
+	return resp, err
+}
+
+func (rt *RoundTripper) honorRetryAfter(b *circuitbreaker.Breaker, resp *http.Response) {
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return
+	}
+
+	d, ok := parseRetryAfter(resp.Header.Get("Retry-After"), b.Clock().Now())
+	if !ok {
+		return
+	}
+
+	if rt.options.maxRetryAfter > 0 && d > rt.options.maxRetryAfter {
+		d = rt.options.maxRetryAfter
+	}
+
+	b.TripFor(d)
+}
+
+// parseRetryAfter parses a Retry-After header, which is either a number of seconds or an HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds <= 0 {
+			return 0, false
+		}
+
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	t, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+
+	d := t.Sub(now)
+	if d <= 0 {
+		return 0, false
+	}
+
+	return d, true
+}
+
+func serviceUnavailable(req *http.Request, b *circuitbreaker.Breaker, err error) *http.Response {
+	body := err.Error()
+
+	header := make(http.Header)
+	header.Set("Content-Type", "text/plain; charset=utf-8")
+
+	if d := b.Status().RetryAfter; d > 0 {
+		header.Set("Retry-After", retryAfter(d))
+	}
+
+	return &http.Response{
+		Status:        strconv.Itoa(http.StatusServiceUnavailable) + " " + http.StatusText(http.StatusServiceUnavailable),
+		StatusCode:    http.StatusServiceUnavailable,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// retryAfter formats d as a Retry-After value in whole seconds, rounding up.
+func retryAfter(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
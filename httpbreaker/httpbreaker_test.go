@@ -0,0 +1,223 @@
+package httpbreaker
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bakins/circuitbreaker"
+	"github.com/bakins/circuitbreaker/breakertest"
+)
+
+func alwaysTrip(circuitbreaker.Counts) bool {
+	return true
+}
+
+func TestRoundTripper(t *testing.T) {
+	code := http.StatusOK
+
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(code)
+	}))
+	defer svr.Close()
+
+	b, err := circuitbreaker.New(circuitbreaker.WithReadyToTrip(alwaysTrip))
+	require.NoError(t, err)
+
+	client := &http.Client{Transport: NewRoundTripper(nil, b)}
+
+	resp, err := client.Get(svr.URL)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, circuitbreaker.StateClosed, b.State())
+
+	code = http.StatusInternalServerError
+
+	resp, err = client.Get(svr.URL)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	require.Equal(t, circuitbreaker.StateOpen, b.State())
+
+	_, err = client.Get(svr.URL)
+	require.ErrorIs(t, err, circuitbreaker.ErrOpenState)
+}
+
+func TestRoundTripperWriteBreaker(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer svr.Close()
+
+	reads, err := circuitbreaker.New(circuitbreaker.WithReadyToTrip(alwaysTrip))
+	require.NoError(t, err)
+
+	writes, err := circuitbreaker.New(circuitbreaker.WithReadyToTrip(alwaysTrip))
+	require.NoError(t, err)
+
+	client := &http.Client{Transport: NewRoundTripper(nil, reads, WithWriteBreaker(writes))}
+
+	resp, err := client.Post(svr.URL, "text/plain", nil)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, circuitbreaker.StateOpen, writes.State())
+
+	_, err = client.Post(svr.URL, "text/plain", nil)
+	require.ErrorIs(t, err, circuitbreaker.ErrOpenState)
+
+	// reads are still allowed
+	resp, err = client.Get(svr.URL)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, circuitbreaker.StateClosed, reads.State())
+}
+
+func TestRoundTripperTransportError(t *testing.T) {
+	svr := httptest.NewServer(http.NotFoundHandler())
+	svr.Close()
+
+	b, err := circuitbreaker.New(circuitbreaker.WithReadyToTrip(alwaysTrip))
+	require.NoError(t, err)
+
+	client := &http.Client{Transport: NewRoundTripper(nil, b)}
+
+	_, err = client.Get(svr.URL)
+	require.Error(t, err)
+	require.Equal(t, circuitbreaker.StateOpen, b.State())
+}
+
+func TestRoundTripperServiceUnavailable(t *testing.T) {
+	b, err := circuitbreaker.New()
+	require.NoError(t, err)
+
+	b.Trip()
+
+	client := &http.Client{Transport: NewRoundTripper(nil, b, WithServiceUnavailable())}
+
+	resp, err := client.Get("http://example.invalid")
+	require.NoError(t, err)
+
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	require.NotEmpty(t, resp.Header.Get("Retry-After"))
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Contains(t, string(body), "circuit breaker is open")
+}
+
+func TestGroupRoundTripper(t *testing.T) {
+	bad := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer bad.Close()
+
+	good := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer good.Close()
+
+	g, err := circuitbreaker.NewGroup[string](circuitbreaker.WithReadyToTrip(alwaysTrip))
+	require.NoError(t, err)
+
+	client := &http.Client{Transport: NewGroupRoundTripper(nil, g)}
+
+	resp, err := client.Get(bad.URL)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	_, err = client.Get(bad.URL)
+	require.ErrorIs(t, err, circuitbreaker.ErrOpenState)
+
+	resp, err = client.Get(good.URL)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	require.Equal(t, circuitbreaker.StateOpen, g.Get(bad.Listener.Addr().String()).State())
+	require.Equal(t, circuitbreaker.StateClosed, g.Get(good.Listener.Addr().String()).State())
+}
+
+func TestGroupRoundTripperKeyFunc(t *testing.T) {
+	g, err := circuitbreaker.NewGroup[string]()
+	require.NoError(t, err)
+
+	g.Get("api").Trip()
+
+	rt := NewGroupRoundTripper(nil, g, WithKeyFunc(func(req *http.Request) string {
+		return req.Header.Get("X-Service")
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "http://example.invalid", nil)
+	req.Header.Set("X-Service", "api")
+
+	_, err = rt.RoundTrip(req)
+	require.ErrorIs(t, err, circuitbreaker.ErrOpenState)
+}
+
+func TestRoundTripperRetryAfter(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer svr.Close()
+
+	b, err := circuitbreaker.New(circuitbreaker.WithTimeout(time.Second))
+	require.NoError(t, err)
+
+	client := &http.Client{Transport: NewRoundTripper(nil, b, WithRetryAfter(time.Minute))}
+
+	resp, err := client.Get(svr.URL)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	status := b.Status()
+	require.Equal(t, circuitbreaker.StateOpen, status.State)
+	require.InDelta(t, time.Minute, status.RetryAfter, float64(time.Second))
+}
+
+func TestRoundTripperRetryAfterClock(t *testing.T) {
+	c := breakertest.NewClock(time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", c.Now().Add(30*time.Second).Format(http.TimeFormat))
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer svr.Close()
+
+	b, err := circuitbreaker.New(circuitbreaker.WithClock(c))
+	require.NoError(t, err)
+
+	client := &http.Client{Transport: NewRoundTripper(nil, b, WithRetryAfter(time.Minute))}
+
+	resp, err := client.Get(svr.URL)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	// the date is compared with the time of the Breaker's Clock
+	status := b.Status()
+	require.Equal(t, circuitbreaker.StateOpen, status.State)
+	require.Equal(t, 30*time.Second, status.RetryAfter)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	d, ok := parseRetryAfter("30", now)
+	require.True(t, ok)
+	require.Equal(t, 30*time.Second, d)
+
+	d, ok = parseRetryAfter(now.Add(time.Minute).Format(http.TimeFormat), now)
+	require.True(t, ok)
+	require.Equal(t, time.Minute, d)
+
+	for _, v := range []string{"", "0", "-1", "soon", now.Add(-time.Minute).Format(http.TimeFormat)} {
+		_, ok = parseRetryAfter(v, now)
+		require.False(t, ok, v)
+	}
+}
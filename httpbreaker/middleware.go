@@ -0,0 +1,162 @@
+package httpbreaker
+
+import (
+	"net/http"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+// Middleware returns server middleware that runs each request through the breaker.
+// When the breaker does not allow a request, it responds with 503 Service Unavailable
+// and, if the breaker is open, a Retry-After header with the time remaining until it becomes half-open.
+// See WithRejectHandler. Outcomes are recorded from the response status code using the
+// StatusClassifier, see WithStatusClassifier. A handler that panics is recorded as a failure.
+func Middleware(b *circuitbreaker.Breaker, opts ...Option) func(http.Handler) http.Handler {
+	in := NewInbound(b, opts...)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			done, ok := in.Allow(w, r, "")
+			if !ok {
+				return
+			}
+
+			rec := &statusRecorder{ResponseWriter: w}
+
+			defer func() {
+				if p := recover(); p != nil {
+					done.Panic()
+					panic(p)
+				}
+			}()
+
+			next.ServeHTTP(rec, r)
+
+			done.Status(rec.status())
+		})
+	}
+}
+
+// Inbound runs inbound requests through breakers. It implements Middleware for adapters
+// to the middleware of other frameworks, such as ginbreaker.
+type Inbound struct {
+	breaker func(r *http.Request, key string) *circuitbreaker.Breaker
+	options options
+}
+
+// NewInbound creates an Inbound that runs every request through b.
+func NewInbound(b *circuitbreaker.Breaker, opts ...Option) *Inbound {
+	o := newOptions(opts)
+	readWrite := o.readWrite(b)
+
+	return &Inbound{
+		breaker: func(r *http.Request, _ string) *circuitbreaker.Breaker {
+			return readWrite(r)
+		},
+		options: o,
+	}
+}
+
+// NewGroupInbound creates an Inbound that runs each request through the breaker from g
+// for the key passed to Allow, such as the request's route.
+func NewGroupInbound(g *circuitbreaker.Group[string], opts ...Option) *Inbound {
+	return &Inbound{
+		breaker: func(_ *http.Request, key string) *circuitbreaker.Breaker {
+			return g.Get(key)
+		},
+		options: newOptions(opts),
+	}
+}
+
+// Done records the outcome of a request allowed by Inbound. Exactly one of its methods must be called.
+type Done struct {
+	done       func(circuitbreaker.Outcome)
+	classifier StatusClassifier
+}
+
+// Status records the outcome of a response with the status code using the StatusClassifier.
+func (d Done) Status(code int) {
+	d.done(d.classifier.classifyStatus(code))
+}
+
+// Panic records a handler that panicked as a failure.
+func (d Done) Panic() {
+	d.done(circuitbreaker.OutcomeFailure)
+}
+
+// Allow runs a request through the breaker for key. If the breaker does not allow the request,
+// Allow responds as Middleware does and returns false.
+func (in *Inbound) Allow(w http.ResponseWriter, r *http.Request, key string) (Done, bool) {
+	b := in.breaker(r, key)
+
+	done, err := b.AllowOutcome()
+	if err != nil {
+		in.reject(w, r, b, err)
+		return Done{}, false
+	}
+
+	return Done{done: done, classifier: in.options.classifier}, true
+}
+
+func (in *Inbound) reject(w http.ResponseWriter, r *http.Request, b *circuitbreaker.Breaker, err error) {
+	writeServiceUnavailable(w, r, b, err, in.options.rejectHandler)
+}
+
+func writeServiceUnavailable(w http.ResponseWriter, r *http.Request, b *circuitbreaker.Breaker, err error, handler func(http.ResponseWriter, *http.Request, error)) {
+	if d := b.Status().RetryAfter; d > 0 {
+		w.Header().Set("Retry-After", retryAfter(d))
+	}
+
+	if handler != nil {
+		handler(w, r, err)
+		return
+	}
+
+	http.Error(w, err.Error(), http.StatusServiceUnavailable)
+}
+
+// statusRecorder records the status code written by a handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	code int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	if r.code == 0 {
+		r.code = code
+	}
+
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Write(p []byte) (int, error) {
+	if r.code == 0 {
+		r.code = http.StatusOK
+	}
+
+	return r.ResponseWriter.Write(p)
+}
+
+// Flush implements http.Flusher.
+func (r *statusRecorder) Flush() {
+	if r.code == 0 {
+		r.code = http.StatusOK
+	}
+
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap allows http.ResponseController to access the underlying ResponseWriter.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+func (r *statusRecorder) status() int {
+	if r.code == 0 {
+		return http.StatusOK
+	}
+
+	return r.code
+}
@@ -0,0 +1,109 @@
+package httpbreaker
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+func TestMiddleware(t *testing.T) {
+	code := http.StatusOK
+
+	b, err := circuitbreaker.New(circuitbreaker.WithReadyToTrip(alwaysTrip), circuitbreaker.WithTimeout(time.Minute))
+	require.NoError(t, err)
+
+	h := Middleware(b)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(code)
+	}))
+
+	serve := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		return w
+	}
+
+	require.Equal(t, http.StatusOK, serve().Code)
+	require.Equal(t, circuitbreaker.StateClosed, b.State())
+
+	code = http.StatusInternalServerError
+	require.Equal(t, http.StatusInternalServerError, serve().Code)
+	require.Equal(t, circuitbreaker.StateOpen, b.State())
+
+	w := serve()
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	seconds, err := strconv.Atoi(w.Header().Get("Retry-After"))
+	require.NoError(t, err)
+	require.InDelta(t, 60, seconds, 1)
+}
+
+func TestMiddlewareWriteBreaker(t *testing.T) {
+	reads, err := circuitbreaker.New(circuitbreaker.WithReadyToTrip(alwaysTrip), circuitbreaker.WithTimeout(time.Minute))
+	require.NoError(t, err)
+
+	writes, err := circuitbreaker.New(circuitbreaker.WithReadyToTrip(alwaysTrip), circuitbreaker.WithTimeout(time.Minute))
+	require.NoError(t, err)
+
+	writes.Trip()
+
+	h := Middleware(reads, WithWriteBreaker(writes))(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/", nil))
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestMiddlewarePanic(t *testing.T) {
+	b, err := circuitbreaker.New(circuitbreaker.WithReadyToTrip(alwaysTrip))
+	require.NoError(t, err)
+
+	h := Middleware(b)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	require.Panics(t, func() {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+
+	require.Equal(t, circuitbreaker.StateOpen, b.State())
+}
+
+func TestGroupInbound(t *testing.T) {
+	g, err := circuitbreaker.NewGroup[string](circuitbreaker.WithReadyToTrip(alwaysTrip))
+	require.NoError(t, err)
+
+	in := NewGroupInbound(g, WithRejectHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"error":"unavailable"}`))
+	}))
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+
+	done, ok := in.Allow(w, r, "GET /users/{id}")
+	require.True(t, ok)
+	done.Status(http.StatusServiceUnavailable)
+
+	_, ok = in.Allow(w, r, "GET /users/{id}")
+	require.False(t, ok)
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	require.JSONEq(t, `{"error":"unavailable"}`, w.Body.String())
+	require.NotEmpty(t, w.Header().Get("Retry-After"))
+
+	// other routes are not affected
+	done, ok = in.Allow(httptest.NewRecorder(), r, "GET /orders")
+	require.True(t, ok)
+	done.Status(http.StatusOK)
+}
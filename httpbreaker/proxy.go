@@ -0,0 +1,76 @@
+package httpbreaker
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sync/atomic"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+// NewReverseProxy creates a reverse proxy that balances requests among targets using a
+// breaker per target host from the group. Requests are sent to targets in turn, skipping targets
+// whose breaker is open. If every breaker is open, or a breaker otherwise rejects the request,
+// the client receives 503 Service Unavailable.
+// Transport errors respond with 502 Bad Gateway.
+//
+// The proxy's Transport is created by NewGroupRoundTripper with opts and http.DefaultTransport.
+// The returned proxy may be further customized before use.
+func NewReverseProxy(targets []*url.URL, g *circuitbreaker.Group[string], opts ...Option) (*httputil.ReverseProxy, error) {
+	if len(targets) == 0 {
+		return nil, errors.New("no targets")
+	}
+
+	p := &proxy{
+		targets:       targets,
+		group:         g,
+		rejectHandler: newOptions(opts).rejectHandler,
+	}
+
+	// targets are keyed by the host of the rewritten request
+	opts = append(opts, WithKeyFunc(HostKey))
+
+	return &httputil.ReverseProxy{
+		Rewrite:      p.rewrite,
+		Transport:    NewGroupRoundTripper(nil, g, opts...),
+		ErrorHandler: p.errorHandler,
+	}, nil
+}
+
+type proxy struct {
+	group         *circuitbreaker.Group[string]
+	targets       []*url.URL
+	next          uint64
+	rejectHandler func(http.ResponseWriter, *http.Request, error)
+}
+
+func (p *proxy) rewrite(r *httputil.ProxyRequest) {
+	r.SetURL(p.pick())
+	r.SetXForwarded()
+}
+
+// pick returns the next target whose breaker is not open. If all breakers are
+// open, it returns the next target so that the transport rejects the request.
+func (p *proxy) pick() *url.URL {
+	start := atomic.AddUint64(&p.next, 1)
+
+	for i := range p.targets {
+		target := p.targets[(start+uint64(i))%uint64(len(p.targets))]
+		if p.group.Get(target.Host).State() != circuitbreaker.StateOpen {
+			return target
+		}
+	}
+
+	return p.targets[start%uint64(len(p.targets))]
+}
+
+func (p *proxy) errorHandler(w http.ResponseWriter, r *http.Request, err error) {
+	if circuitbreaker.IsRejected(err) {
+		writeServiceUnavailable(w, r, p.group.Get(r.URL.Host), err, p.rejectHandler)
+		return
+	}
+
+	w.WriteHeader(http.StatusBadGateway)
+}
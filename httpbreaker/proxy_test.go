@@ -0,0 +1,100 @@
+package httpbreaker
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+func TestReverseProxy(t *testing.T) {
+	bad := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer bad.Close()
+
+	good := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "good")
+	}))
+	defer good.Close()
+
+	badURL, err := url.Parse(bad.URL)
+	require.NoError(t, err)
+
+	goodURL, err := url.Parse(good.URL)
+	require.NoError(t, err)
+
+	g, err := circuitbreaker.NewGroup[string](circuitbreaker.WithReadyToTrip(alwaysTrip))
+	require.NoError(t, err)
+
+	p, err := NewReverseProxy([]*url.URL{badURL, goodURL}, g)
+	require.NoError(t, err)
+
+	serve := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		p.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		return w
+	}
+
+	codes := map[int]int{}
+	for i := 0; i < 10; i++ {
+		codes[serve().Code]++
+	}
+
+	// the bad target fails once before its breaker opens
+	require.Equal(t, map[int]int{http.StatusInternalServerError: 1, http.StatusOK: 9}, codes)
+	require.Equal(t, circuitbreaker.StateOpen, g.Get(badURL.Host).State())
+
+	g.Get(goodURL.Host).Trip()
+
+	w := serve()
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	require.NotEmpty(t, w.Header().Get("Retry-After"))
+}
+
+func TestReverseProxyRejected(t *testing.T) {
+	target, err := url.Parse("http://example.com")
+	require.NoError(t, err)
+
+	g, err := circuitbreaker.NewGroup[string]()
+	require.NoError(t, err)
+
+	p, err := NewReverseProxy([]*url.URL{target}, g)
+	require.NoError(t, err)
+
+	tests := []struct {
+		err  error
+		code int
+	}{
+		{err: circuitbreaker.ErrOpenState, code: http.StatusServiceUnavailable},
+		{err: circuitbreaker.ErrTooManyRequests, code: http.StatusServiceUnavailable},
+		{err: circuitbreaker.ErrRateLimited, code: http.StatusServiceUnavailable},
+		{err: circuitbreaker.ErrLimitExceeded, code: http.StatusServiceUnavailable},
+		{err: circuitbreaker.ErrInsufficientDeadline, code: http.StatusServiceUnavailable},
+		{err: errors.New("connection refused"), code: http.StatusBadGateway},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			w := httptest.NewRecorder()
+			p.ErrorHandler(w, httptest.NewRequest(http.MethodGet, target.String(), nil), fmt.Errorf("transport: %w", tt.err))
+			require.Equal(t, tt.code, w.Code)
+		})
+	}
+}
+
+func TestReverseProxyNoTargets(t *testing.T) {
+	g, err := circuitbreaker.NewGroup[string]()
+	require.NoError(t, err)
+
+	_, err = NewReverseProxy(nil, g)
+	require.Error(t, err)
+}
@@ -0,0 +1,228 @@
+// Package ring implements rolling windows of counters, kept in a fixed-size ring of buckets
+// that is advanced by timestamp.
+package ring
+
+import (
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// each counter packs the low bits of its bucket's epoch, the number of bucket durations since
+	// the Unix epoch, with its count, so a counter can be reset and added to atomically.
+	countBits = 40
+	countMask = 1<<countBits - 1
+	epochMask = 1<<(64-countBits) - 1
+
+	// cacheLine is the number of counters in a cache line, so shards of a bucket do not share a line.
+	cacheLine = 8
+)
+
+// Ring is a rolling window with a bucket per bucket duration. Each bucket holds the same number of counters,
+// stored together. Add and Sum do not lock. Counts in a bucket are limited to 2^40.
+//
+// A bucket may be split into shards, each with its own copy of the counters on separate cache lines.
+// Add picks a shard at random, so concurrent adds from many cores rarely contend, and sums add up every shard.
+type Ring struct {
+	buckets        atomic.Pointer[buckets]
+	bucketDuration time.Duration
+	counters       int
+	shards         int
+	// stride is the number of values in each shard, including padding.
+	stride int
+	// lock serializes TryAdd and Resize.
+	lock sync.Mutex
+}
+
+type buckets struct {
+	values []atomic.Uint64
+	n      uint64
+}
+
+// New creates a Ring of numBuckets buckets of bucketDuration, each with counters counters split into shards shards.
+func New(numBuckets int, counters int, shards int, bucketDuration time.Duration) *Ring {
+	r := &Ring{
+		bucketDuration: bucketDuration,
+		counters:       counters,
+		shards:         max(shards, 1),
+		stride:         counters,
+	}
+
+	if r.shards > 1 {
+		r.stride = (counters + cacheLine - 1) / cacheLine * cacheLine
+	}
+
+	r.buckets.Store(r.newBuckets(numBuckets))
+
+	return r
+}
+
+func (r *Ring) newBuckets(n int) *buckets {
+	return &buckets{
+		values: make([]atomic.Uint64, n*r.shards*r.stride),
+		n:      uint64(n),
+	}
+}
+
+func (r *Ring) epoch(now time.Time) uint64 {
+	return uint64(now.UnixNano() / int64(r.bucketDuration))
+}
+
+// counter returns the counter in a shard of the bucket for epoch.
+func (r *Ring) counter(b *buckets, epoch uint64, shard int, counter int) *atomic.Uint64 {
+	return &b.values[(int(epoch%b.n)*r.shards+shard)*r.stride+counter]
+}
+
+func (r *Ring) shard() int {
+	if r.shards == 1 {
+		return 0
+	}
+
+	return rand.IntN(r.shards)
+}
+
+// Add adds n to the counter in the bucket for now. If the bucket has been reused for a later time,
+// now is no longer in the window and n is dropped, so a count can be added at the time an operation started.
+func (r *Ring) Add(now time.Time, counter int, n uint64) {
+	e := r.epoch(now)
+	add(r.counter(r.buckets.Load(), e, r.shard(), counter), e, n)
+}
+
+func add(c *atomic.Uint64, e uint64, n uint64) {
+	for {
+		old := c.Load()
+
+		v := (e&epochMask)<<countBits | n
+
+		switch epoch := old >> countBits; {
+		case epoch == e&epochMask:
+			v = old + n
+		case old != 0 && (e-epoch)&epochMask > epochMask/2:
+			// the bucket is for a later time
+			return
+		}
+
+		if c.CompareAndSwap(old, v) {
+			return
+		}
+	}
+}
+
+// value returns the count of v if it is in the window ending at epoch e.
+func value(v uint64, e uint64, n uint64) uint64 {
+	// buckets from before the window, or from the future if the clock moved backwards, are skipped
+	if age := (e - v>>countBits) & epochMask; age < n {
+		return v & countMask
+	}
+
+	return 0
+}
+
+// Sum returns the sum of the counter over the window ending at now.
+func (r *Ring) Sum(now time.Time, counter int) uint64 {
+	e := r.epoch(now)
+	b := r.buckets.Load()
+
+	var sum uint64
+
+	for i := counter; i < len(b.values); i += r.stride {
+		sum += value(b.values[i].Load(), e, b.n)
+	}
+
+	return sum
+}
+
+// Sums sets sums, which must have a length of at least the number of counters,
+// to the sum of each counter over the window ending at now.
+// The counters of each bucket are read from the last to the first, across every shard, so if every addition
+// to a counter follows an addition to an earlier counter in the same bucket, its sum does not exceed the earlier sum.
+func (r *Ring) Sums(now time.Time, sums []uint64) {
+	e := r.epoch(now)
+	b := r.buckets.Load()
+	size := r.shards * r.stride
+
+	clear(sums[:r.counters])
+
+	for i := 0; i < len(b.values); i += size {
+		for c := r.counters - 1; c >= 0; c-- {
+			for s := i + c; s < i+size; s += r.stride {
+				sums[c] += value(b.values[s].Load(), e, b.n)
+			}
+		}
+	}
+}
+
+// Values appends the value of the counter in each bucket of the window ending at now to values, oldest first,
+// and returns the result.
+func (r *Ring) Values(now time.Time, counter int, values []uint64) []uint64 {
+	e := r.epoch(now)
+	b := r.buckets.Load()
+
+	for age := b.n; age > 0; age-- {
+		var v uint64
+
+		for s := range r.shards {
+			v += value(r.counter(b, e-age+1, s, counter).Load(), e, b.n)
+		}
+
+		values = append(values, v)
+	}
+
+	return values
+}
+
+// TryAdd adds n to the counter in the bucket for now if the sum of the counter would not exceed limit,
+// and reports whether it did. It is only exact if the counter is not changed using Add.
+func (r *Ring) TryAdd(now time.Time, counter int, n uint64, limit uint64) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.Sum(now, counter)+n > limit {
+		return false
+	}
+
+	r.Add(now, counter, n)
+
+	return true
+}
+
+// Reset clears the counters.
+func (r *Ring) Reset() {
+	b := r.buckets.Load()
+
+	for i := range b.values {
+		b.values[i].Store(0)
+	}
+}
+
+// Resize changes the number of buckets, keeping the most recent buckets.
+// Counts added while resizing may be lost.
+func (r *Ring) Resize(now time.Time, numBuckets int) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	old := r.buckets.Load()
+	if uint64(numBuckets) == old.n {
+		return
+	}
+
+	e := r.epoch(now)
+	b := r.newBuckets(numBuckets)
+
+	for age := range min(uint64(numBuckets), old.n) {
+		for s := range r.shards {
+			for c := range r.counters {
+				r.counter(b, e-age, s, c).Store(r.counter(old, e-age, s, c).Load())
+			}
+		}
+	}
+
+	r.buckets.Store(b)
+}
+
+// Window returns the duration of the window.
+func (r *Ring) Window() time.Duration {
+	return time.Duration(r.buckets.Load().n) * r.bucketDuration
+}
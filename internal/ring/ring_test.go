@@ -0,0 +1,180 @@
+package ring
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRing(t *testing.T) {
+	now := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	r := New(3, 1, 1, time.Second)
+
+	r.Add(now, 0, 1)
+	r.Add(now, 0, 2)
+	require.Equal(t, uint64(3), r.Sum(now, 0))
+
+	now = now.Add(time.Second)
+	r.Add(now, 0, 4)
+	require.Equal(t, uint64(7), r.Sum(now, 0))
+
+	now = now.Add(2 * time.Second)
+	require.Equal(t, uint64(4), r.Sum(now, 0))
+
+	// the bucket is reused
+	r.Add(now, 0, 8)
+	require.Equal(t, uint64(12), r.Sum(now, 0))
+
+	// the clock moved backwards
+	require.Equal(t, uint64(0), r.Sum(now.Add(-time.Hour), 0))
+
+	now = now.Add(time.Hour)
+	require.Equal(t, uint64(0), r.Sum(now, 0))
+
+	r.Add(now, 0, 1)
+	r.Reset()
+	require.Equal(t, uint64(0), r.Sum(now, 0))
+}
+
+func TestRingCounters(t *testing.T) {
+	now := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	r := New(3, 3, 1, time.Second)
+
+	r.Add(now, 0, 1)
+	r.Add(now, 1, 2)
+	now = now.Add(time.Second)
+	r.Add(now, 2, 3)
+	r.Add(now, 0, 1)
+
+	sums := make([]uint64, 3)
+	r.Sums(now, sums)
+	require.Equal(t, []uint64{2, 2, 3}, sums)
+	require.Equal(t, uint64(2), r.Sum(now, 1))
+
+	// a count for a time that is no longer in the window is dropped
+	r.Add(now.Add(-3*time.Second), 1, 5)
+	require.Equal(t, uint64(2), r.Sum(now, 1))
+
+	// but is added if the time is still in the window
+	r.Add(now.Add(-time.Second), 1, 5)
+	require.Equal(t, uint64(7), r.Sum(now, 1))
+}
+
+func TestRingValues(t *testing.T) {
+	now := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	r := New(3, 2, 2, time.Second)
+
+	r.Add(now, 0, 1)
+	r.Add(now, 0, 1)
+	r.Add(now, 1, 4)
+	now = now.Add(2 * time.Second)
+	r.Add(now, 0, 3)
+
+	require.Equal(t, []uint64{2, 0, 3}, r.Values(now, 0, nil))
+	require.Equal(t, []uint64{4, 0, 0}, r.Values(now, 1, nil))
+
+	now = now.Add(time.Second)
+	require.Equal(t, []uint64{0, 3, 0}, r.Values(now, 0, nil))
+}
+
+func TestRingTryAdd(t *testing.T) {
+	now := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	r := New(3, 1, 1, time.Second)
+
+	require.True(t, r.TryAdd(now, 0, 1, 2))
+	require.True(t, r.TryAdd(now, 0, 1, 2))
+	require.False(t, r.TryAdd(now, 0, 1, 2))
+
+	now = now.Add(3 * time.Second)
+	require.True(t, r.TryAdd(now, 0, 1, 2))
+}
+
+func TestRingResize(t *testing.T) {
+	now := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	r := New(5, 2, 1, time.Second)
+
+	for i := 0; i < 5; i++ {
+		r.Add(now, 0, 1)
+		r.Add(now, 1, 2)
+		now = now.Add(time.Second)
+	}
+
+	now = now.Add(-time.Second)
+
+	r.Resize(now, 10)
+	require.Equal(t, 10*time.Second, r.Window())
+	require.Equal(t, uint64(5), r.Sum(now, 0))
+	require.Equal(t, uint64(10), r.Sum(now, 1))
+
+	r.Resize(now, 2)
+	require.Equal(t, uint64(2), r.Sum(now, 0))
+	require.Equal(t, uint64(4), r.Sum(now, 1))
+}
+
+func TestRingConcurrent(t *testing.T) {
+	r := New(60, 1, 1, time.Second)
+	now := time.Now()
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 1000; j++ {
+				r.Add(now, 0, 1)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	require.Equal(t, uint64(8000), r.Sum(now, 0))
+}
+
+func TestRingShards(t *testing.T) {
+	now := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	r := New(3, 2, 4, time.Second)
+
+	for i := 0; i < 100; i++ {
+		r.Add(now, 0, 1)
+		r.Add(now, 1, 2)
+	}
+
+	sums := make([]uint64, 2)
+	r.Sums(now, sums)
+	require.Equal(t, []uint64{100, 200}, sums)
+	require.Equal(t, uint64(200), r.Sum(now, 1))
+
+	r.Resize(now, 5)
+	require.Equal(t, uint64(100), r.Sum(now, 0))
+
+	now = now.Add(5 * time.Second)
+	require.Zero(t, r.Sum(now, 0))
+}
+
+func BenchmarkRingAdd(b *testing.B) {
+	for _, shards := range []int{1, 8} {
+		b.Run(fmt.Sprintf("shards=%d", shards), func(b *testing.B) {
+			r := New(10, 3, shards, time.Second)
+			now := time.Now()
+
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					r.Add(now, 0, 1)
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package circuitbreaker
+
+import "context"
+
+// JobGuard runs a periodic job, such as one started by a cron scheduler, through a Breaker.
+// Runs are skipped while the Breaker is open, so a scheduled job does not add load to a dependency that is down.
+// Outcomes are recorded as Execute would.
+type JobGuard struct {
+	breaker *Breaker
+	job     func(ctx context.Context) error
+	options jobOptions
+}
+
+type jobOptions struct {
+	probe     func(ctx context.Context) error
+	onSkipped func(err error)
+}
+
+// JobOption sets JobGuard options.
+type JobOption func(*jobOptions)
+
+// WithJobProbe sets a reduced version of the job that is run in place of the job while the Breaker is half-open,
+// such as one that processes a single item. The default is to run the job.
+func WithJobProbe(fn func(ctx context.Context) error) JobOption {
+	return func(o *jobOptions) {
+		o.probe = fn
+	}
+}
+
+// WithOnJobSkipped sets a function that is called with the rejection error when a run is skipped.
+// There is no default.
+func WithOnJobSkipped(fn func(err error)) JobOption {
+	return func(o *jobOptions) {
+		o.onSkipped = fn
+	}
+}
+
+// NewJobGuard creates a JobGuard that runs job through b.
+func NewJobGuard(b *Breaker, job func(ctx context.Context) error, options ...JobOption) *JobGuard {
+	var opts jobOptions
+
+	for _, o := range options {
+		o(&opts)
+	}
+
+	return &JobGuard{
+		breaker: b,
+		job:     job,
+		options: opts,
+	}
+}
+
+// Run runs the job, or the probe if the Breaker is half-open. If the Breaker does not allow the run,
+// it is skipped and the rejection error, such as an OpenStateError, is returned.
+func (g *JobGuard) Run(ctx context.Context) error {
+	a, done, err := g.breaker.AllowAdmission()
+	if err != nil {
+		if g.options.onSkipped != nil {
+			g.options.onSkipped(err)
+		}
+
+		return err
+	}
+
+	fn := g.job
+	if a.State == StateHalfOpen && g.options.probe != nil {
+		fn = g.options.probe
+	}
+
+	err = fn(ctx)
+	done(outcomeOf(ctx, err))
+
+	return err
+}
+
+// Func returns a function that calls Run with ctx, for schedulers that run a func().
+func (g *JobGuard) Func(ctx context.Context) func() {
+	return func() {
+		_ = g.Run(ctx)
+	}
+}
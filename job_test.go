@@ -0,0 +1,51 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobGuard(t *testing.T) {
+	c := &testClock{
+		now: time.Now(),
+	}
+
+	b, err := New(WithClock(c), WithReadyToTrip(TripOnConsecutiveFailures(1)), WithTimeout(time.Second))
+	require.NoError(t, err)
+
+	var (
+		runs, probes int
+		skipped      []error
+		fail         = errors.New("fail")
+	)
+
+	g := NewJobGuard(b,
+		func(context.Context) error {
+			runs++
+			return fail
+		},
+		WithJobProbe(func(context.Context) error {
+			probes++
+			return nil
+		}),
+		WithOnJobSkipped(func(err error) { skipped = append(skipped, err) }),
+	)
+
+	require.ErrorIs(t, g.Run(context.Background()), fail)
+	require.Equal(t, StateOpen, b.State())
+
+	g.Func(context.Background())()
+	require.Equal(t, 1, runs)
+	require.Len(t, skipped, 1)
+	require.ErrorIs(t, skipped[0], ErrOpenState)
+
+	c.advance(time.Minute)
+
+	require.NoError(t, g.Run(context.Background()))
+	require.Equal(t, 1, probes)
+	require.Equal(t, StateClosed, b.State())
+}
@@ -0,0 +1,18 @@
+module github.com/bakins/circuitbreaker/kitbreaker
+
+go 1.22
+
+require (
+	github.com/bakins/circuitbreaker v0.0.0
+	github.com/go-kit/kit v0.13.0
+	github.com/stretchr/testify v1.12.1
+)
+
+require (
+	github.com/kr/text v0.2.0 // indirect
+	go.yaml.in/yaml/v3 v3.0.5 // indirect
+	golang.org/x/time v0.10.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/bakins/circuitbreaker => ../
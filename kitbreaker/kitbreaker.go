@@ -0,0 +1,72 @@
+// Package kitbreaker provides go-kit endpoint middleware that runs requests through a circuit breaker.
+package kitbreaker
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-kit/kit/endpoint"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+// Classifier returns the outcome of an endpoint that returned err.
+type Classifier func(err error) circuitbreaker.Outcome
+
+// DefaultClassifier classifies nil errors as successes, requests canceled by the caller
+// as ignored, and other errors as failures.
+func DefaultClassifier(err error) circuitbreaker.Outcome {
+	switch {
+	case err == nil:
+		return circuitbreaker.OutcomeSuccess
+	case errors.Is(err, context.Canceled):
+		return circuitbreaker.OutcomeIgnored
+	default:
+		return circuitbreaker.OutcomeFailure
+	}
+}
+
+type options struct {
+	classifier Classifier
+}
+
+// Option sets kitbreaker options.
+type Option func(*options)
+
+// WithClassifier sets the classifier used to record the outcome of requests.
+// Default is DefaultClassifier.
+func WithClassifier(c Classifier) Option {
+	return func(o *options) {
+		o.classifier = c
+	}
+}
+
+// EndpointMiddleware returns an endpoint.Middleware that runs requests through the breaker.
+// When the breaker does not allow a request, the error from the breaker is returned.
+//
+// Errors returned by the endpoint are classified. Business logic errors in responses that
+// implement endpoint.Failer are not, since the service responded.
+func EndpointMiddleware(b *circuitbreaker.Breaker, opts ...Option) endpoint.Middleware {
+	o := options{
+		classifier: DefaultClassifier,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			done, err := b.AllowOutcome()
+			if err != nil {
+				return nil, err
+			}
+
+			response, err := next(ctx, request)
+
+			done(o.classifier(err))
+
+			return response, err
+		}
+	}
+}
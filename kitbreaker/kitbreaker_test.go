@@ -0,0 +1,56 @@
+package kitbreaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+type response struct {
+	err error
+}
+
+func (r response) Failed() error {
+	return r.err
+}
+
+func TestEndpointMiddleware(t *testing.T) {
+	b, err := circuitbreaker.New(circuitbreaker.WithReadyToTrip(func(circuitbreaker.Counts) bool { return true }))
+	require.NoError(t, err)
+
+	var endpointErr error
+
+	e := EndpointMiddleware(b)(func(ctx context.Context, request interface{}) (interface{}, error) {
+		if endpointErr != nil {
+			return nil, endpointErr
+		}
+
+		return response{err: errors.New("not found")}, nil
+	})
+
+	ctx := context.Background()
+
+	resp, err := e(ctx, "request")
+	require.NoError(t, err)
+	require.Error(t, resp.(response).Failed())
+	require.Equal(t, circuitbreaker.StateClosed, b.State())
+
+	endpointErr = context.Canceled
+
+	_, err = e(ctx, "request")
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, circuitbreaker.StateClosed, b.State())
+
+	endpointErr = errors.New("unavailable")
+
+	_, err = e(ctx, "request")
+	require.ErrorIs(t, err, endpointErr)
+	require.Equal(t, circuitbreaker.StateOpen, b.State())
+
+	_, err = e(ctx, "request")
+	require.ErrorIs(t, err, circuitbreaker.ErrOpenState)
+}
@@ -0,0 +1,22 @@
+package circuitbreaker
+
+import "maps"
+
+// WithLabels adds labels, such as a team, dependency tier, or region, that are included in the Status and
+// TransitionEvents of the Breaker, and so in metrics and the admin API, so breakers can be grouped by more than
+// their name. It may be used more than once.
+// There is no default.
+func WithLabels(labels map[string]string) Option {
+	return func(o *Options) {
+		if o.labels == nil {
+			o.labels = make(map[string]string, len(labels))
+		}
+
+		maps.Copy(o.labels, labels)
+	}
+}
+
+// Labels returns a copy of the labels set by WithLabels, or nil if there are none.
+func (b *Breaker) Labels() map[string]string {
+	return maps.Clone(b.options.labels)
+}
@@ -0,0 +1,54 @@
+package circuitbreaker
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLabels(t *testing.T) {
+	var events []TransitionEvent
+
+	b, err := New(
+		WithName("db"),
+		WithLabels(map[string]string{"team": "storage"}),
+		WithLabels(map[string]string{"region": "us"}),
+		WithNotifier(notifierFunc(func(e TransitionEvent) { events = append(events, e) })),
+		WithSynchronousObservers(),
+	)
+	require.NoError(t, err)
+
+	labels := map[string]string{"team": "storage", "region": "us"}
+	require.Equal(t, labels, b.Labels())
+	require.Equal(t, labels, b.Options().Labels)
+
+	// the labels cannot be changed through a copy
+	b.Labels()["team"] = "other"
+	require.Equal(t, labels, b.Labels())
+
+	status := b.Status()
+	require.Equal(t, labels, status.Labels)
+
+	data, err := json.Marshal(status)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"labels":{"region":"us","team":"storage"}`)
+
+	b.Trip()
+	require.Len(t, events, 1)
+	require.Equal(t, labels, events[0].Labels)
+
+	b, err = New()
+	require.NoError(t, err)
+	require.Nil(t, b.Labels())
+
+	data, err = json.Marshal(b.Status())
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "labels")
+}
+
+type notifierFunc func(TransitionEvent)
+
+func (f notifierFunc) Notify(e TransitionEvent) {
+	f(e)
+}
@@ -0,0 +1,160 @@
+package circuitbreaker
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maxLatencySamples bounds the latencies kept by a Breaker. Older samples are overwritten first.
+const maxLatencySamples = 4096
+
+// WithLatencySampling records the latency of 1 in n successful requests and of every failed request,
+// so latency percentiles can be estimated cheaply at high request rates. Ignored requests are not recorded.
+// Use Latencies to read the samples of the window.
+// There is no default. Must not be negative.
+func WithLatencySampling(n int) Option {
+	return func(o *Options) {
+		o.latencySampling = n
+	}
+}
+
+// LatencySample is the latency of a request sampled by WithLatencySampling.
+type LatencySample struct {
+	Latency time.Duration
+	Failure bool
+	// Weight is the number of requests the sample stands for: the sampling rate for successes and 1 for failures.
+	Weight float64
+}
+
+type latencySample struct {
+	LatencySample
+	at time.Time
+}
+
+// latencySamples is a ring of the most recent sampled latencies.
+type latencySamples struct {
+	rate    uint64
+	count   atomic.Uint64
+	lock    sync.Mutex
+	samples []latencySample
+	next    int
+	// p95 is the 95th percentile latency when it was estimated at estimated.
+	p95       time.Duration
+	estimated time.Time
+}
+
+func newLatencySamples(rate int) *latencySamples {
+	return &latencySamples{
+		rate:    uint64(rate),
+		samples: make([]latencySample, 0, maxLatencySamples),
+	}
+}
+
+func (l *latencySamples) add(now time.Time, latency time.Duration, o Outcome) {
+	weight := 1.0
+
+	switch o {
+	case OutcomeSuccess:
+		if l.count.Add(1)%l.rate != 0 {
+			return
+		}
+
+		weight = float64(l.rate)
+	case OutcomeFailure:
+	default:
+		return
+	}
+
+	s := latencySample{
+		LatencySample: LatencySample{Latency: latency, Failure: o == OutcomeFailure, Weight: weight},
+		at:            now,
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if len(l.samples) < cap(l.samples) {
+		l.samples = append(l.samples, s)
+		return
+	}
+
+	l.samples[l.next] = s
+	l.next = (l.next + 1) % len(l.samples)
+}
+
+// since returns the samples recorded after from, oldest first.
+func (l *latencySamples) since(from time.Time) []LatencySample {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	out := make([]LatencySample, 0, len(l.samples))
+
+	for i := range l.samples {
+		s := l.samples[(l.next+i)%len(l.samples)]
+		if s.at.After(from) {
+			out = append(out, s.LatencySample)
+		}
+	}
+
+	return out
+}
+
+// percentile95 returns the 95th percentile latency of the samples recorded after from,
+// estimated at most once a second.
+func (l *latencySamples) percentile95(now time.Time, from time.Time) time.Duration {
+	l.lock.Lock()
+	if !l.estimated.IsZero() && now.Sub(l.estimated) < time.Second {
+		defer l.lock.Unlock()
+		return l.p95
+	}
+	l.lock.Unlock()
+
+	p95 := LatencyPercentile(l.since(from), 95)
+
+	l.lock.Lock()
+	l.p95, l.estimated = p95, now
+	l.lock.Unlock()
+
+	return p95
+}
+
+// Latencies returns the latencies sampled in the window, oldest first.
+// It returns nil unless WithLatencySampling is used.
+func (b *Breaker) Latencies() []LatencySample {
+	if b.latency == nil {
+		return nil
+	}
+
+	return b.latency.since(b.options.clock.Now().Add(-b.buckets.Window()))
+}
+
+// LatencyPercentile returns the latency at or below which p percent of the requests fell,
+// weighting each sample by the number of requests it stands for. It returns zero if there are no samples.
+func LatencyPercentile(samples []LatencySample, p float64) time.Duration {
+	if len(samples) == 0 {
+		return 0
+	}
+
+	sorted := make([]LatencySample, len(samples))
+	copy(sorted, samples)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Latency < sorted[j].Latency })
+
+	var total float64
+	for _, s := range sorted {
+		total += s.Weight
+	}
+
+	target := total * p / 100
+
+	var seen float64
+	for _, s := range sorted {
+		seen += s.Weight
+		if seen >= target {
+			return s.Latency
+		}
+	}
+
+	return sorted[len(sorted)-1].Latency
+}
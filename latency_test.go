@@ -0,0 +1,56 @@
+package circuitbreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatencySampling(t *testing.T) {
+	c := &testClock{
+		now: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	b, err := New(
+		WithClock(c),
+		WithWindow(time.Minute),
+		WithLatencySampling(4),
+		WithReadyToTrip(func(Counts) bool { return false }),
+	)
+	require.NoError(t, err)
+
+	call := func(latency time.Duration, success bool) {
+		done, err := b.Allow()
+		require.NoError(t, err)
+		c.advance(latency)
+		done(success)
+	}
+
+	for i := 1; i <= 8; i++ {
+		call(time.Duration(i)*time.Millisecond, true)
+	}
+
+	call(100*time.Millisecond, false)
+
+	require.Equal(t, []LatencySample{
+		{Latency: 4 * time.Millisecond, Weight: 4},
+		{Latency: 8 * time.Millisecond, Weight: 4},
+		{Latency: 100 * time.Millisecond, Failure: true, Weight: 1},
+	}, b.Latencies())
+
+	require.Equal(t, 8*time.Millisecond, LatencyPercentile(b.Latencies(), 50))
+	require.Equal(t, 100*time.Millisecond, LatencyPercentile(b.Latencies(), 99))
+	require.Zero(t, LatencyPercentile(nil, 50))
+
+	// samples leave with the window
+	c.advance(2 * time.Minute)
+	require.Empty(t, b.Latencies())
+
+	b, err = New()
+	require.NoError(t, err)
+	require.Nil(t, b.Latencies())
+
+	_, err = New(WithLatencySampling(-1))
+	require.Error(t, err)
+}
@@ -0,0 +1,54 @@
+package mongobreaker
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+// Classifier returns the outcome of a command that returned err.
+type Classifier func(err error) circuitbreaker.Outcome
+
+// serverStateError is implemented by driver errors that describe the state of the server.
+type serverStateError interface {
+	NodeIsRecovering() bool
+	NodeIsShuttingDown() bool
+	NotPrimary() bool
+}
+
+// DefaultClassifier classifies errors that indicate an unhealthy server as failures: network errors,
+// timeouts, and "not primary", "node is recovering", and "node is shutting down" errors. Commands canceled
+// by the caller are ignored. Other errors returned by the server, such as duplicate key errors, are successes
+// since the server responded.
+func DefaultClassifier(err error) circuitbreaker.Outcome {
+	if err == nil {
+		return circuitbreaker.OutcomeSuccess
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return circuitbreaker.OutcomeIgnored
+	}
+
+	if mongo.IsNetworkError(err) || mongo.IsTimeout(err) {
+		return circuitbreaker.OutcomeFailure
+	}
+
+	var stateErr serverStateError
+	if errors.As(err, &stateErr) {
+		if stateErr.NotPrimary() || stateErr.NodeIsRecovering() || stateErr.NodeIsShuttingDown() {
+			return circuitbreaker.OutcomeFailure
+		}
+
+		return circuitbreaker.OutcomeSuccess
+	}
+
+	var serverErr mongo.ServerError
+	if errors.As(err, &serverErr) {
+		return circuitbreaker.OutcomeSuccess
+	}
+
+	return circuitbreaker.OutcomeFailure
+}
@@ -0,0 +1,37 @@
+package mongobreaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/x/mongo/driver"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+func TestDefaultClassifier(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want circuitbreaker.Outcome
+	}{
+		{"nil", nil, circuitbreaker.OutcomeSuccess},
+		{"canceled", context.Canceled, circuitbreaker.OutcomeIgnored},
+		{"deadline", context.DeadlineExceeded, circuitbreaker.OutcomeFailure},
+		{"network", driver.Error{Labels: []string{driver.NetworkError}}, circuitbreaker.OutcomeFailure},
+		{"not primary", driver.Error{Code: 10107}, circuitbreaker.OutcomeFailure},
+		{"shutting down", driver.Error{Code: 91}, circuitbreaker.OutcomeFailure},
+		{"duplicate key", driver.Error{Code: 11000}, circuitbreaker.OutcomeSuccess},
+		{"command error", mongo.CommandError{Code: 11000}, circuitbreaker.OutcomeSuccess},
+		{"unknown", errors.New("unknown"), circuitbreaker.OutcomeFailure},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, DefaultClassifier(tt.err))
+		})
+	}
+}
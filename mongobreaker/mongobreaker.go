@@ -0,0 +1,167 @@
+// Package mongobreaker provides MongoDB driver monitors that run commands through a circuit breaker per server.
+//
+// The driver's server selection keeps sending operations to a degraded primary, retrying them as it goes.
+// A Monitor records the outcome of each command with the breaker for the server it was sent to, and
+// Guard and Execute fail operations fast while the primary's breaker is open:
+//
+//	m := mongobreaker.NewMonitor(group)
+//	client, err := mongo.Connect(options.Client().
+//		ApplyURI(uri).
+//		SetMonitor(m.CommandMonitor()).
+//		SetServerMonitor(m.ServerMonitor()))
+package mongobreaker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"go.mongodb.org/mongo-driver/v2/event"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+type options struct {
+	classifier Classifier
+}
+
+// Option sets mongobreaker options.
+type Option func(*options)
+
+// WithClassifier sets the classifier used to record the outcome of commands.
+// Default is DefaultClassifier.
+func WithClassifier(c Classifier) Option {
+	return func(o *options) {
+		o.classifier = c
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		classifier: DefaultClassifier,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// Monitor records the outcome of commands using a breaker per server from a Group,
+// keyed by the server's address, such as "localhost:27017".
+//
+// Monitors cannot stop the driver from sending a command, so commands sent while a server's breaker
+// is open are not recorded. Use Guard or Execute to fail operations fast.
+type Monitor struct {
+	group    *circuitbreaker.Group[string]
+	options  options
+	inflight sync.Map // commandKey to func(circuitbreaker.Outcome)
+	primary  atomic.Pointer[string]
+}
+
+type commandKey struct {
+	connectionID string
+	requestID    int64
+}
+
+// NewMonitor creates a Monitor that uses breakers from g.
+func NewMonitor(g *circuitbreaker.Group[string], opts ...Option) *Monitor {
+	return &Monitor{
+		group:   g,
+		options: newOptions(opts),
+	}
+}
+
+// CommandMonitor returns an event.CommandMonitor that records the outcome of commands.
+func (m *Monitor) CommandMonitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Started: func(_ context.Context, e *event.CommandStartedEvent) {
+			done, err := m.group.Get(serverAddress(e.ConnectionID)).AllowOutcome()
+			if err != nil {
+				return
+			}
+
+			m.inflight.Store(commandKey{e.ConnectionID, e.RequestID}, done)
+		},
+		Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+			m.finish(e.CommandFinishedEvent, nil)
+		},
+		Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+			m.finish(e.CommandFinishedEvent, e.Failure)
+		},
+	}
+}
+
+// ServerMonitor returns an event.ServerMonitor that tracks the primary used by Guard.
+func (m *Monitor) ServerMonitor() *event.ServerMonitor {
+	return &event.ServerMonitor{
+		TopologyDescriptionChanged: func(e *event.TopologyDescriptionChangedEvent) {
+			for _, s := range e.NewDescription.Servers {
+				if s.Kind == "RSPrimary" || s.Kind == "Standalone" {
+					addr := s.Addr.String()
+					m.primary.Store(&addr)
+
+					return
+				}
+			}
+
+			m.primary.Store(nil)
+		},
+	}
+}
+
+// Primary returns the address of the primary, if known.
+func (m *Monitor) Primary() (string, bool) {
+	addr := m.primary.Load()
+	if addr == nil {
+		return "", false
+	}
+
+	return *addr, true
+}
+
+// Guard returns an error that matches circuitbreaker.ErrOpenState if the primary's breaker is open,
+// so an operation can fail fast rather than wait for server selection and retries. It returns nil
+// if the primary is not known.
+func (m *Monitor) Guard() error {
+	addr, ok := m.Primary()
+	if !ok {
+		return nil
+	}
+
+	if m.group.Get(addr).State() == circuitbreaker.StateOpen {
+		return fmt.Errorf("mongobreaker: primary %s: %w", addr, circuitbreaker.ErrOpenState)
+	}
+
+	return nil
+}
+
+// Execute calls fn unless Guard returns an error. The outcome of fn's commands is recorded by the CommandMonitor.
+func (m *Monitor) Execute(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := m.Guard(); err != nil {
+		return err
+	}
+
+	return fn(ctx)
+}
+
+func (m *Monitor) finish(e event.CommandFinishedEvent, err error) {
+	done, ok := m.inflight.LoadAndDelete(commandKey{e.ConnectionID, e.RequestID})
+	if !ok {
+		return
+	}
+
+	done.(func(circuitbreaker.Outcome))(m.options.classifier(err))
+}
+
+// serverAddress returns the address of the server from a connection ID, such as "localhost:27017[-3]".
+func serverAddress(connectionID string) string {
+	if i := strings.LastIndexByte(connectionID, '['); i >= 0 {
+		return connectionID[:i]
+	}
+
+	return connectionID
+}
@@ -0,0 +1,74 @@
+package mongobreaker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/event"
+	"go.mongodb.org/mongo-driver/v2/x/mongo/driver"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+func TestMonitor(t *testing.T) {
+	g, err := circuitbreaker.NewGroup[string](
+		circuitbreaker.WithWindow(time.Minute),
+		circuitbreaker.WithReadyToTrip(circuitbreaker.TripOnConsecutiveFailures(2)),
+	)
+	require.NoError(t, err)
+
+	m := NewMonitor(g)
+	commands := m.CommandMonitor()
+	servers := m.ServerMonitor()
+
+	ctx := context.Background()
+
+	// without a known primary, operations are not guarded
+	require.NoError(t, m.Guard())
+
+	servers.TopologyDescriptionChanged(&event.TopologyDescriptionChangedEvent{
+		NewDescription: event.TopologyDescription{
+			Servers: []event.ServerDescription{
+				{Addr: "a:27017", Kind: "RSSecondary"},
+				{Addr: "b:27017", Kind: "RSPrimary"},
+			},
+		},
+	})
+
+	primary, ok := m.Primary()
+	require.True(t, ok)
+	require.Equal(t, "b:27017", primary)
+
+	run := func(connectionID string, requestID int64, failure error) {
+		commands.Started(ctx, &event.CommandStartedEvent{ConnectionID: connectionID, RequestID: requestID})
+
+		finished := event.CommandFinishedEvent{ConnectionID: connectionID, RequestID: requestID}
+
+		if failure == nil {
+			commands.Succeeded(ctx, &event.CommandSucceededEvent{CommandFinishedEvent: finished})
+		} else {
+			commands.Failed(ctx, &event.CommandFailedEvent{CommandFinishedEvent: finished, Failure: failure})
+		}
+	}
+
+	notPrimary := driver.Error{Code: 10107}
+
+	run("a:27017[-1]", 1, nil)
+	run("b:27017[-2]", 2, notPrimary)
+	require.NoError(t, m.Execute(ctx, func(context.Context) error { return nil }))
+
+	run("b:27017[-2]", 3, notPrimary)
+	require.Equal(t, circuitbreaker.StateOpen, g.Get("b:27017").State())
+	require.Equal(t, circuitbreaker.StateClosed, g.Get("a:27017").State())
+
+	var called bool
+
+	err = m.Execute(ctx, func(context.Context) error {
+		called = true
+		return nil
+	})
+	require.ErrorIs(t, err, circuitbreaker.ErrOpenState)
+	require.False(t, called)
+}
@@ -0,0 +1,32 @@
+package natsbreaker
+
+import (
+	"context"
+	"errors"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+// Classifier returns the outcome of a request or publish that returned err.
+type Classifier func(err error) circuitbreaker.Outcome
+
+// DefaultClassifier classifies errors that indicate an unavailable service as failures: timeouts,
+// including context deadlines, no responders, and connection errors. Requests canceled by the caller,
+// requests on a closed or draining connection, and invalid requests, such as a bad subject or a payload
+// over the maximum, are ignored.
+func DefaultClassifier(err error) circuitbreaker.Outcome {
+	switch {
+	case err == nil:
+		return circuitbreaker.OutcomeSuccess
+	case errors.Is(err, context.Canceled),
+		errors.Is(err, nats.ErrConnectionClosed),
+		errors.Is(err, nats.ErrConnectionDraining),
+		errors.Is(err, nats.ErrBadSubject),
+		errors.Is(err, nats.ErrMaxPayload):
+		return circuitbreaker.OutcomeIgnored
+	default:
+		return circuitbreaker.OutcomeFailure
+	}
+}
@@ -0,0 +1,33 @@
+package natsbreaker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+func TestDefaultClassifier(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want circuitbreaker.Outcome
+	}{
+		{"nil", nil, circuitbreaker.OutcomeSuccess},
+		{"timeout", nats.ErrTimeout, circuitbreaker.OutcomeFailure},
+		{"deadline", context.DeadlineExceeded, circuitbreaker.OutcomeFailure},
+		{"no responders", nats.ErrNoResponders, circuitbreaker.OutcomeFailure},
+		{"canceled", context.Canceled, circuitbreaker.OutcomeIgnored},
+		{"closed", nats.ErrConnectionClosed, circuitbreaker.OutcomeIgnored},
+		{"bad subject", nats.ErrBadSubject, circuitbreaker.OutcomeIgnored},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, DefaultClassifier(tt.err))
+		})
+	}
+}
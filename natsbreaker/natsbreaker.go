@@ -0,0 +1,128 @@
+// Package natsbreaker runs NATS requests and publishes through a circuit breaker per subject prefix,
+// so that a failing request-reply service fails fast rather than timing out every request.
+package natsbreaker
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+type options struct {
+	classifier Classifier
+	keyFunc    func(subject string) string
+}
+
+// Option sets natsbreaker options.
+type Option func(*options)
+
+// WithClassifier sets the classifier used to record the outcome of requests and publishes.
+// Default is DefaultClassifier.
+func WithClassifier(c Classifier) Option {
+	return func(o *options) {
+		o.classifier = c
+	}
+}
+
+// WithKeyFunc sets the function used to choose the breaker for a subject.
+// Default is PrefixKey(1).
+func WithKeyFunc(fn func(subject string) string) Option {
+	return func(o *options) {
+		o.keyFunc = fn
+	}
+}
+
+// PrefixKey returns a function that returns the first n tokens of a subject,
+// such as "orders" for "orders.create" when n is 1.
+func PrefixKey(n int) func(subject string) string {
+	return func(subject string) string {
+		tokens := strings.SplitN(subject, ".", n+1)
+		if len(tokens) <= n {
+			return subject
+		}
+
+		return strings.Join(tokens[:n], ".")
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		classifier: DefaultClassifier,
+		keyFunc:    PrefixKey(1),
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// Conn is the subset of *nats.Conn used by a Client.
+type Conn interface {
+	Publish(subject string, data []byte) error
+	RequestWithContext(ctx context.Context, subject string, data []byte) (*nats.Msg, error)
+}
+
+var _ Conn = &nats.Conn{}
+
+// Client wraps a Conn, running each request and publish through the breaker for its subject.
+// When the breaker does not allow a request, the error from the breaker, such as a
+// *circuitbreaker.OpenStateError, is returned.
+type Client struct {
+	conn    Conn
+	group   *circuitbreaker.Group[string]
+	options options
+}
+
+// NewClient creates a Client that uses breakers from g, keyed by subject prefix. See WithKeyFunc.
+func NewClient(conn Conn, g *circuitbreaker.Group[string], opts ...Option) *Client {
+	return &Client{
+		conn:    conn,
+		group:   g,
+		options: newOptions(opts),
+	}
+}
+
+// Breaker returns the breaker used for subject.
+func (c *Client) Breaker(subject string) *circuitbreaker.Breaker {
+	return c.group.Get(c.options.keyFunc(subject))
+}
+
+// Request sends a request and waits for the reply until ctx is done.
+func (c *Client) Request(ctx context.Context, subject string, data []byte) (*nats.Msg, error) {
+	done, err := c.Breaker(subject).AllowOutcome()
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := c.conn.RequestWithContext(ctx, subject, data)
+	done(c.options.classifier(err))
+
+	return msg, err
+}
+
+// RequestTimeout is like Request, but waits for the reply for at most timeout.
+func (c *Client) RequestTimeout(subject string, data []byte, timeout time.Duration) (*nats.Msg, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	return c.Request(ctx, subject, data)
+}
+
+// Publish publishes data to subject.
+func (c *Client) Publish(subject string, data []byte) error {
+	done, err := c.Breaker(subject).AllowOutcome()
+	if err != nil {
+		return err
+	}
+
+	err = c.conn.Publish(subject, data)
+	done(c.options.classifier(err))
+
+	return err
+}
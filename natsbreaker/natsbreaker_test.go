@@ -0,0 +1,67 @@
+package natsbreaker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+type testConn struct {
+	requests int
+	err      error
+}
+
+func (c *testConn) Publish(string, []byte) error {
+	c.requests++
+	return c.err
+}
+
+func (c *testConn) RequestWithContext(_ context.Context, subject string, data []byte) (*nats.Msg, error) {
+	c.requests++
+
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	return &nats.Msg{Subject: subject, Data: data}, nil
+}
+
+func TestPrefixKey(t *testing.T) {
+	require.Equal(t, "orders", PrefixKey(1)("orders.create"))
+	require.Equal(t, "orders.v1", PrefixKey(2)("orders.v1.create"))
+	require.Equal(t, "orders", PrefixKey(2)("orders"))
+}
+
+func TestClient(t *testing.T) {
+	g, err := circuitbreaker.NewGroup[string](
+		circuitbreaker.WithWindow(time.Minute),
+		circuitbreaker.WithReadyToTrip(circuitbreaker.TripOnConsecutiveFailures(1)),
+	)
+	require.NoError(t, err)
+
+	conn := &testConn{}
+	c := NewClient(conn, g)
+
+	msg, err := c.RequestTimeout("orders.create", []byte("hi"), time.Second)
+	require.NoError(t, err)
+	require.Equal(t, "hi", string(msg.Data))
+
+	conn.err = nats.ErrNoResponders
+
+	_, err = c.Request(context.Background(), "orders.create", nil)
+	require.ErrorIs(t, err, nats.ErrNoResponders)
+	require.Equal(t, circuitbreaker.StateOpen, c.Breaker("orders.cancel").State())
+
+	err = c.Publish("orders.cancel", nil)
+	require.ErrorIs(t, err, circuitbreaker.ErrOpenState)
+	require.Equal(t, 2, conn.requests)
+
+	// other prefixes use their own breaker
+	conn.err = nil
+	require.NoError(t, c.Publish("payments.capture", nil))
+}
@@ -0,0 +1,47 @@
+// Package netbreaker provides circuit breakers for network connections and DNS lookups.
+package netbreaker
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+// DialContextFunc dials a network address, like net.Dialer.DialContext.
+type DialContextFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+// WrapDialContext returns a DialContextFunc that runs each dial through a breaker from the group,
+// keyed by address, so that addresses that repeatedly refuse or time out connections fail fast.
+// Dials canceled by the caller are ignored; other errors are failures. When the breaker does not allow
+// a dial, the error from the breaker is returned.
+// If dial is nil, the DialContext method of a zero net.Dialer is used.
+//
+// The returned function may be used as http.Transport.DialContext, with grpc.WithContextDialer, and so on.
+func WrapDialContext(dial DialContextFunc, group *circuitbreaker.Group[string]) DialContextFunc {
+	if dial == nil {
+		var d net.Dialer
+		dial = d.DialContext
+	}
+
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		done, err := group.Get(address).AllowOutcome()
+		if err != nil {
+			return nil, &net.OpError{Op: "dial", Net: network, Err: err}
+		}
+
+		conn, err := dial(ctx, network, address)
+
+		switch {
+		case err == nil:
+			done(circuitbreaker.OutcomeSuccess)
+		case errors.Is(err, context.Canceled):
+			done(circuitbreaker.OutcomeIgnored)
+		default:
+			done(circuitbreaker.OutcomeFailure)
+		}
+
+		return conn, err
+	}
+}
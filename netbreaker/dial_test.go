@@ -0,0 +1,59 @@
+package netbreaker
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+func alwaysTrip(circuitbreaker.Counts) bool {
+	return true
+}
+
+func TestWrapDialContext(t *testing.T) {
+	good, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	defer good.Close()
+
+	bad, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	require.NoError(t, bad.Close())
+
+	g, err := circuitbreaker.NewGroup[string](circuitbreaker.WithReadyToTrip(alwaysTrip))
+	require.NoError(t, err)
+
+	dial := WrapDialContext(nil, g)
+
+	ctx := context.Background()
+
+	conn, err := dial(ctx, "tcp", good.Addr().String())
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	_, err = dial(ctx, "tcp", bad.Addr().String())
+	require.Error(t, err)
+
+	_, err = dial(ctx, "tcp", bad.Addr().String())
+	require.ErrorIs(t, err, circuitbreaker.ErrOpenState)
+
+	var opErr *net.OpError
+	require.ErrorAs(t, err, &opErr)
+
+	conn, err = dial(ctx, "tcp", good.Addr().String())
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	g.Delete(bad.Addr().String())
+
+	_, err = dial(canceled, "tcp", bad.Addr().String())
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, circuitbreaker.StateClosed, g.Get(bad.Addr().String()).State())
+}
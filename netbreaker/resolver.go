@@ -0,0 +1,167 @@
+package netbreaker
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+type options struct {
+	maxStale time.Duration
+}
+
+// Option sets Resolver options.
+type Option func(*options)
+
+// WithMaxStaleness sets the maximum age of a cached result that is returned when a lookup is not allowed
+// by the breaker or fails.
+// Default is 5 minutes.
+func WithMaxStaleness(d time.Duration) Option {
+	return func(o *options) {
+		o.maxStale = d
+	}
+}
+
+// Resolver runs DNS lookups through a breaker and keeps the last successful result of each lookup.
+// When the breaker does not allow a lookup, or the lookup fails, a recent cached result is returned
+// instead, so connections keep using recently resolved addresses rather than stalling while DNS times out.
+//
+// Lookups for hosts that do not exist are successes, since the DNS server responded, and are not cached.
+// Results are kept until they are replaced, so hosts should be drawn from a bounded set.
+type Resolver struct {
+	resolver lookuper
+	breaker  *circuitbreaker.Breaker
+	hosts    *lookupCache[[]string]
+	ips      *lookupCache[[]net.IPAddr]
+	options  options
+}
+
+// lookuper is implemented by net.Resolver.
+type lookuper interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// NewResolver creates a Resolver. If r is nil, net.DefaultResolver is used.
+func NewResolver(r *net.Resolver, b *circuitbreaker.Breaker, opts ...Option) *Resolver {
+	o := options{
+		maxStale: 5 * time.Minute,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var resolver lookuper = net.DefaultResolver
+	if r != nil {
+		resolver = r
+	}
+
+	return &Resolver{
+		resolver: resolver,
+		breaker:  b,
+		hosts:    newLookupCache[[]string](),
+		ips:      newLookupCache[[]net.IPAddr](),
+		options:  o,
+	}
+}
+
+// LookupHost is like net.Resolver.LookupHost.
+func (r *Resolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	return lookup(ctx, r, r.hosts, host, func(ctx context.Context) ([]string, error) {
+		return r.resolver.LookupHost(ctx, host)
+	})
+}
+
+// LookupIPAddr is like net.Resolver.LookupIPAddr.
+func (r *Resolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	return lookup(ctx, r, r.ips, host, func(ctx context.Context) ([]net.IPAddr, error) {
+		return r.resolver.LookupIPAddr(ctx, host)
+	})
+}
+
+func lookup[V any](ctx context.Context, r *Resolver, cache *lookupCache[V], key string, fn func(context.Context) (V, error)) (V, error) {
+	done, err := r.breaker.AllowOutcome()
+	if err != nil {
+		if v, ok := cache.get(key, r.options.maxStale); ok {
+			return v, nil
+		}
+
+		var zero V
+
+		return zero, &net.DNSError{Err: err.Error(), Name: key, IsTemporary: true}
+	}
+
+	v, err := fn(ctx)
+
+	outcome := classify(err)
+	done(outcome)
+
+	switch {
+	case err == nil:
+		cache.set(key, v)
+	case outcome == circuitbreaker.OutcomeFailure:
+		if v, ok := cache.get(key, r.options.maxStale); ok {
+			return v, nil
+		}
+	}
+
+	return v, err
+}
+
+func classify(err error) circuitbreaker.Outcome {
+	if err == nil {
+		return circuitbreaker.OutcomeSuccess
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return circuitbreaker.OutcomeIgnored
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return circuitbreaker.OutcomeSuccess
+	}
+
+	return circuitbreaker.OutcomeFailure
+}
+
+type cachedLookup[V any] struct {
+	time  time.Time
+	value V
+}
+
+type lookupCache[V any] struct {
+	values map[string]cachedLookup[V]
+	lock   sync.Mutex
+}
+
+func newLookupCache[V any]() *lookupCache[V] {
+	return &lookupCache[V]{
+		values: make(map[string]cachedLookup[V]),
+	}
+}
+
+func (c *lookupCache[V]) get(key string, maxStale time.Duration) (V, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	v, ok := c.values[key]
+	if !ok || time.Since(v.time) > maxStale {
+		var zero V
+		return zero, false
+	}
+
+	return v.value, true
+}
+
+func (c *lookupCache[V]) set(key string, value V) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.values[key] = cachedLookup[V]{time: time.Now(), value: value}
+}
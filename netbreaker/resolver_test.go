@@ -0,0 +1,110 @@
+package netbreaker
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+type fakeResolver struct {
+	err   error
+	hosts []string
+}
+
+func (f *fakeResolver) LookupHost(_ context.Context, host string) ([]string, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	return f.hosts, nil
+}
+
+func (f *fakeResolver) LookupIPAddr(_ context.Context, host string) ([]net.IPAddr, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	addrs := make([]net.IPAddr, 0, len(f.hosts))
+	for _, h := range f.hosts {
+		addrs = append(addrs, net.IPAddr{IP: net.ParseIP(h)})
+	}
+
+	return addrs, nil
+}
+
+func TestResolver(t *testing.T) {
+	b, err := circuitbreaker.New(circuitbreaker.WithReadyToTrip(func(c circuitbreaker.Counts) bool {
+		return c.ConsecutiveFailures >= 2
+	}))
+	require.NoError(t, err)
+
+	fake := &fakeResolver{hosts: []string{"192.0.2.1"}}
+
+	r := NewResolver(nil, b)
+	r.resolver = fake
+
+	ctx := context.Background()
+
+	hosts, err := r.LookupHost(ctx, "example.com")
+	require.NoError(t, err)
+	require.Equal(t, []string{"192.0.2.1"}, hosts)
+
+	// hosts that do not exist are not failures
+	fake.err = &net.DNSError{Err: "no such host", Name: "missing.example.com", IsNotFound: true}
+
+	_, err = r.LookupHost(ctx, "missing.example.com")
+	require.Error(t, err)
+	require.Equal(t, uint64(0), b.Status().Counts.TotalFailures)
+
+	fake.err = &net.DNSError{Err: "i/o timeout", IsTimeout: true}
+
+	// failed lookups return the cached result
+	hosts, err = r.LookupHost(ctx, "example.com")
+	require.NoError(t, err)
+	require.Equal(t, []string{"192.0.2.1"}, hosts)
+
+	_, err = r.LookupIPAddr(ctx, "example.com")
+	require.Error(t, err)
+	require.Equal(t, circuitbreaker.StateOpen, b.State())
+
+	// the breaker is open, so lookups fail fast or return the cached result
+	fake.err = errors.New("not called")
+
+	hosts, err = r.LookupHost(ctx, "example.com")
+	require.NoError(t, err)
+	require.Equal(t, []string{"192.0.2.1"}, hosts)
+
+	_, err = r.LookupIPAddr(ctx, "example.com")
+
+	var dnsErr *net.DNSError
+	require.ErrorAs(t, err, &dnsErr)
+	require.True(t, dnsErr.Temporary())
+}
+
+func TestResolverMaxStaleness(t *testing.T) {
+	b, err := circuitbreaker.New()
+	require.NoError(t, err)
+
+	fake := &fakeResolver{hosts: []string{"192.0.2.1"}}
+
+	r := NewResolver(nil, b, WithMaxStaleness(time.Millisecond))
+	r.resolver = fake
+
+	ctx := context.Background()
+
+	_, err = r.LookupHost(ctx, "example.com")
+	require.NoError(t, err)
+
+	time.Sleep(2 * time.Millisecond)
+
+	fake.err = &net.DNSError{Err: "i/o timeout", IsTimeout: true}
+
+	_, err = r.LookupHost(ctx, "example.com")
+	require.Error(t, err)
+}
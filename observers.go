@@ -0,0 +1,116 @@
+package circuitbreaker
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultObserverQueueSize is the default size set by WithObserverQueueSize.
+const DefaultObserverQueueSize = 1024
+
+// WithObserverQueueSize sets the number of per-request events, such as EventAdmission and EventOutcome, that
+// may wait to be delivered to event sinks in the background. When that many are waiting, further per-request
+// events are dropped and counted in Stats.DroppedObservations, so a slow sink never delays requests.
+// Transitions, which are delivered to the functions set by WithOnStateChange and WithOnTransition, Notifiers,
+// and event sinks, are never dropped. Calls are delivered in order, one at a time.
+// Default is DefaultObserverQueueSize. Must not be negative.
+func WithObserverQueueSize(n int) Option {
+	return func(o *Options) {
+		o.observerQueue = n
+	}
+}
+
+// WithSynchronousObservers calls observers, including the functions registered with Subscribe, while the
+// state changes or events happen, rather than in the background, so they are never dropped. Observers are then called while the Breaker may be locked, so they
+// must not block or call methods of the Breaker. This is mostly useful in tests.
+func WithSynchronousObservers() Option {
+	return func(o *Options) {
+		o.syncObservers = true
+	}
+}
+
+// observerQueue delivers calls to observers in the background, in order. A goroutine is started when a call
+// is queued and exits once the queue is empty, so a Breaker does not need to be closed.
+type observerQueue struct {
+	calls []observation
+	// size is the number of droppable calls that may wait, and pending the number waiting.
+	size    int
+	pending int
+	running bool
+	dropped atomic.Uint64
+	lock    sync.Mutex
+}
+
+type observation struct {
+	fn        func()
+	droppable bool
+}
+
+func newObserverQueue(size int) *observerQueue {
+	return &observerQueue{
+		size: size,
+	}
+}
+
+// deliver queues fn to be called in the background. If droppable, fn is dropped when the queue is full.
+func (q *observerQueue) deliver(fn func(), droppable bool) {
+	q.lock.Lock()
+
+	if droppable {
+		if q.pending >= q.size {
+			q.lock.Unlock()
+			q.dropped.Add(1)
+
+			return
+		}
+
+		q.pending++
+	}
+
+	q.calls = append(q.calls, observation{fn: fn, droppable: droppable})
+
+	start := !q.running
+	q.running = true
+
+	q.lock.Unlock()
+
+	if start {
+		go q.drain()
+	}
+}
+
+func (q *observerQueue) drain() {
+	for {
+		q.lock.Lock()
+
+		if len(q.calls) == 0 {
+			q.calls = nil
+			q.running = false
+			q.lock.Unlock()
+
+			return
+		}
+
+		o := q.calls[0]
+		q.calls[0] = observation{}
+		q.calls = q.calls[1:]
+
+		if o.droppable {
+			q.pending--
+		}
+
+		q.lock.Unlock()
+
+		o.fn()
+	}
+}
+
+// observe calls fn, in the background unless WithSynchronousObservers is used. fn is never dropped.
+func (b *Breaker) observe(fn func()) {
+	if b.observers == nil {
+		fn()
+		return
+	}
+
+	b.observers.deliver(fn, false)
+}
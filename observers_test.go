@@ -0,0 +1,150 @@
+package circuitbreaker
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestObserversAsync(t *testing.T) {
+	release := make(chan struct{})
+	transitions := make(chan Transition, 10)
+
+	b, err := New(WithOnTransition(func(t Transition) {
+		<-release
+		transitions <- t
+	}))
+	require.NoError(t, err)
+
+	// a blocked observer must not block the Breaker
+	b.Trip()
+	b.Reset()
+	require.Equal(t, StateClosed, b.State())
+
+	close(release)
+
+	for _, to := range []State{StateOpen, StateClosed} {
+		select {
+		case tr := <-transitions:
+			require.Equal(t, to, tr.To)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for transition")
+		}
+	}
+}
+
+func TestObserversDropped(t *testing.T) {
+	release := make(chan struct{})
+
+	var (
+		lock        sync.Mutex
+		transitions int
+		changes     int
+	)
+
+	sink := SinkFunc(func(e Event) {
+		<-release
+
+		if e.Kind == EventTransition {
+			lock.Lock()
+			transitions++
+			lock.Unlock()
+		}
+	})
+
+	b, err := New(
+		WithWindow(time.Minute),
+		WithObserverQueueSize(1),
+		WithEventSink(sink, EventOutcome, EventTransition),
+		WithOnStateChange(func(State, State) {
+			lock.Lock()
+			changes++
+			lock.Unlock()
+		}),
+	)
+	require.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		done, err := b.Allow()
+		require.NoError(t, err)
+		done(true)
+	}
+
+	// at most one outcome is being delivered and one is waiting
+	require.GreaterOrEqual(t, b.Stats().DroppedObservations, uint64(3))
+
+	dropped := b.Stats().DroppedObservations
+
+	// transitions are never dropped, even while the queue is full
+	b.Trip()
+	b.Reset()
+
+	close(release)
+
+	require.Eventually(t, func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+
+		return transitions == 2 && changes == 2
+	}, 5*time.Second, time.Millisecond)
+
+	require.Equal(t, dropped, b.Stats().DroppedObservations)
+}
+
+func TestObserversSynchronous(t *testing.T) {
+	var changes []State
+
+	b, err := New(
+		WithSynchronousObservers(),
+		WithOnStateChange(func(_ State, to State) { changes = append(changes, to) }),
+	)
+	require.NoError(t, err)
+
+	b.Trip()
+	require.Equal(t, []State{StateOpen}, changes)
+	require.Zero(t, b.Stats().DroppedObservations)
+}
+
+func TestObserverQueueSizeValidate(t *testing.T) {
+	_, err := New(WithObserverQueueSize(-1))
+	require.ErrorIs(t, err, ErrInvalidOption)
+
+	b, err := New()
+	require.NoError(t, err)
+	require.ErrorIs(t, b.UpdateOptions(WithSynchronousObservers()), ErrNotUpdatable)
+}
+
+func TestObserversSubscribe(t *testing.T) {
+	release := make(chan struct{})
+	transitions := make(chan Transition, 10)
+
+	b, err := New()
+	require.NoError(t, err)
+
+	unsubscribe := b.Subscribe(func(t Transition) {
+		<-release
+		transitions <- t
+	})
+	defer unsubscribe()
+
+	// a blocked subscriber must not block the Breaker
+	b.Trip()
+	b.Reset()
+
+	done, err := b.Allow()
+	require.NoError(t, err)
+	done(true)
+
+	close(release)
+
+	for _, to := range []State{StateOpen, StateClosed} {
+		select {
+		case tr := <-transitions:
+			require.Equal(t, to, tr.To)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for transition")
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package circuitbreaker
+
+import (
+	"fmt"
+	"time"
+)
+
+// Outcome is the result of a request allowed by a Breaker.
+type Outcome int
+
+// Outcomes
+const (
+	// OutcomeSuccess is recorded as a success.
+	OutcomeSuccess Outcome = iota
+	// OutcomeFailure is recorded as a failure.
+	OutcomeFailure
+	// OutcomeIgnored is neither a success nor a failure, such as a request that
+	// was canceled by the caller.
+	OutcomeIgnored
+)
+
+// String returns a string representation of the outcome.
+func (o Outcome) String() string {
+	switch o {
+	case OutcomeSuccess:
+		return "success"
+	case OutcomeFailure:
+		return "failure"
+	case OutcomeIgnored:
+		return "ignored"
+	default:
+		return fmt.Sprintf("unknown outcome: %d", o)
+	}
+}
+
+// AllowOutcome is like Allow, but the returned callback records an Outcome, which
+// allows a request to be ignored rather than recorded as a success or failure.
+func (b *Breaker) AllowOutcome() (func(Outcome), error) {
+	return b.allow()
+}
+
+func (b *Breaker) recordOutcome(start time.Time, o Outcome) {
+	switch o {
+	case OutcomeSuccess:
+		b.allowResult(start, true)
+	case OutcomeFailure:
+		b.allowResult(start, false)
+	}
+}
@@ -0,0 +1,39 @@
+package circuitbreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllowOutcome(t *testing.T) {
+	clock := &testClock{
+		now: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	b, err := New(WithClock(clock), WithReadyToTrip(func(c Counts) bool {
+		return c.TotalFailures > 0
+	}))
+	require.NoError(t, err)
+
+	done, err := b.AllowOutcome()
+	require.NoError(t, err)
+	done(OutcomeSuccess)
+
+	done, err = b.AllowOutcome()
+	require.NoError(t, err)
+	done(OutcomeIgnored)
+
+	c := b.counts()
+	require.Equal(t, uint64(2), c.Requests)
+	require.Equal(t, uint64(1), c.TotalSuccesses)
+	require.Equal(t, uint64(0), c.TotalFailures)
+	require.Equal(t, StateClosed, b.State())
+
+	done, err = b.AllowOutcome()
+	require.NoError(t, err)
+	done(OutcomeFailure)
+
+	require.Equal(t, StateOpen, b.State())
+}
@@ -0,0 +1,288 @@
+package circuitbreaker
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// OutlierDetector ejects outlier hosts from a Group, in the style of Envoy's outlier detection.
+// A host is ejected when its Breaker records too many consecutive failures, or when its success rate
+// is far below the mean of the Group. An ejected host's Breaker is opened for the ejection time, which
+// grows each time the host is ejected, so load balancers that skip open Breakers route around it.
+type OutlierDetector[K comparable] struct {
+	group   *Group[K]
+	hosts   map[K]*outlierHost
+	options outlierOptions
+	lock    sync.Mutex
+}
+
+type outlierHost struct {
+	ejectedUntil time.Time
+	// ejections is the number of recent ejections, which multiplies the ejection time.
+	ejections int
+	// baseline is the number of consecutive failures when the host was last ejected.
+	baseline uint64
+}
+
+type outlierOptions struct {
+	consecutiveFailures uint64
+	stdevFactor         float64
+	minHosts            int
+	minRequests         uint64
+	maxEjectionPercent  int
+	baseEjectionTime    time.Duration
+	maxEjectionTime     time.Duration
+	interval            time.Duration
+}
+
+// OutlierOption sets OutlierDetector options.
+type OutlierOption func(*outlierOptions)
+
+// WithConsecutiveFailures sets the number of consecutive failures that ejects a host.
+// Zero disables ejection for consecutive failures.
+// Default is 5.
+func WithConsecutiveFailures(n uint64) OutlierOption {
+	return func(o *outlierOptions) {
+		o.consecutiveFailures = n
+	}
+}
+
+// WithSuccessRateDeviation ejects hosts whose success rate is more than factor standard deviations below
+// the mean success rate. Success rates are only calculated for hosts with at least minRequests in the Breaker's
+// window, and only when there are at least minHosts such hosts. A factor of zero disables ejection for success rate.
+// Default is 1.9 standard deviations, 5 hosts, and 100 requests.
+func WithSuccessRateDeviation(factor float64, minHosts int, minRequests uint64) OutlierOption {
+	return func(o *outlierOptions) {
+		o.stdevFactor = factor
+		o.minHosts = minHosts
+		o.minRequests = minRequests
+	}
+}
+
+// WithMaxEjectionPercent sets the maximum percentage of hosts that may be ejected at once.
+// One host may always be ejected.
+// Default is 10.
+func WithMaxEjectionPercent(percent int) OutlierOption {
+	return func(o *outlierOptions) {
+		o.maxEjectionPercent = percent
+	}
+}
+
+// WithEjectionTime sets the base time a host is ejected for, which is multiplied by the number of
+// times it has recently been ejected, and the maximum time a host is ejected for.
+// Default is 30 seconds and 5 minutes.
+func WithEjectionTime(base time.Duration, max time.Duration) OutlierOption {
+	return func(o *outlierOptions) {
+		o.baseEjectionTime = base
+		o.maxEjectionTime = max
+	}
+}
+
+// WithDetectionInterval sets how often Run detects outliers.
+// Default is 10 seconds.
+func WithDetectionInterval(d time.Duration) OutlierOption {
+	return func(o *outlierOptions) {
+		o.interval = d
+	}
+}
+
+// NewOutlierDetector creates an OutlierDetector for the hosts in g.
+// Call Run, or call Detect periodically.
+func NewOutlierDetector[K comparable](g *Group[K], options ...OutlierOption) *OutlierDetector[K] {
+	opts := outlierOptions{
+		consecutiveFailures: 5,
+		stdevFactor:         1.9,
+		minHosts:            5,
+		minRequests:         100,
+		maxEjectionPercent:  10,
+		baseEjectionTime:    30 * time.Second,
+		maxEjectionTime:     5 * time.Minute,
+		interval:            10 * time.Second,
+	}
+
+	for _, o := range options {
+		o(&opts)
+	}
+
+	if opts.interval <= 0 {
+		opts.interval = 10 * time.Second
+	}
+
+	return &OutlierDetector[K]{
+		group:   g,
+		hosts:   make(map[K]*outlierHost),
+		options: opts,
+	}
+}
+
+// Run detects outliers at the detection interval until ctx is done.
+func (d *OutlierDetector[K]) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.options.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.Detect()
+		}
+	}
+}
+
+type outlierCandidate[K comparable] struct {
+	breaker *Breaker
+	counts  Counts
+	key     K
+}
+
+// Detect ejects outlier hosts. Hosts that are not ejected are forgiven one recent ejection each time
+// Detect is called.
+func (d *OutlierDetector[K]) Detect() {
+	var candidates []outlierCandidate[K]
+
+	d.group.Range(func(key K, b *Breaker) bool {
+		candidates = append(candidates, outlierCandidate[K]{key: key, breaker: b, counts: b.counts()})
+		return true
+	})
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	now := d.group.clock.Now()
+
+	ejected := 0
+
+	for _, h := range d.hosts {
+		if now.Before(h.ejectedUntil) {
+			ejected++
+		}
+	}
+
+	outliers := d.successRateOutliers(candidates)
+
+	for _, c := range candidates {
+		h, ok := d.hosts[c.key]
+		if !ok {
+			h = &outlierHost{}
+		}
+
+		if now.Before(h.ejectedUntil) {
+			continue
+		}
+
+		// a success since the last ejection resets the consecutive failures
+		if c.counts.ConsecutiveFailures < h.baseline {
+			h.baseline = 0
+		}
+
+		eject := outliers[c.key] || (d.options.consecutiveFailures > 0 &&
+			c.counts.ConsecutiveFailures-h.baseline >= d.options.consecutiveFailures)
+
+		if !eject || !d.canEject(ejected, len(candidates)) {
+			if h.ejections > 0 {
+				h.ejections--
+			}
+
+			if h.ejections == 0 && h.baseline == 0 {
+				delete(d.hosts, c.key)
+			}
+
+			continue
+		}
+
+		h.ejections++
+		h.baseline = c.counts.ConsecutiveFailures
+
+		ejection := d.options.baseEjectionTime * time.Duration(h.ejections)
+		if d.options.maxEjectionTime > 0 && ejection > d.options.maxEjectionTime {
+			ejection = d.options.maxEjectionTime
+		}
+
+		h.ejectedUntil = now.Add(ejection)
+		d.hosts[c.key] = h
+		ejected++
+
+		c.breaker.TripFor(ejection)
+	}
+}
+
+// successRateOutliers returns the hosts whose success rate is below the threshold.
+func (d *OutlierDetector[K]) successRateOutliers(candidates []outlierCandidate[K]) map[K]bool {
+	if d.options.stdevFactor <= 0 {
+		return nil
+	}
+
+	rates := make(map[K]float64)
+
+	for _, c := range candidates {
+		total := c.counts.TotalSuccesses + c.counts.TotalFailures
+		if total == 0 || total < d.options.minRequests {
+			continue
+		}
+
+		rates[c.key] = float64(c.counts.TotalSuccesses) / float64(total)
+	}
+
+	if len(rates) == 0 || len(rates) < d.options.minHosts {
+		return nil
+	}
+
+	var mean float64
+	for _, r := range rates {
+		mean += r
+	}
+
+	mean /= float64(len(rates))
+
+	var variance float64
+	for _, r := range rates {
+		variance += (r - mean) * (r - mean)
+	}
+
+	threshold := mean - d.options.stdevFactor*math.Sqrt(variance/float64(len(rates)))
+
+	outliers := make(map[K]bool)
+
+	for key, r := range rates {
+		if r < threshold {
+			outliers[key] = true
+		}
+	}
+
+	return outliers
+}
+
+func (d *OutlierDetector[K]) canEject(ejected int, hosts int) bool {
+	return ejected == 0 || (ejected+1)*100 <= d.options.maxEjectionPercent*hosts
+}
+
+// Ejected returns the keys of the hosts that are currently ejected.
+func (d *OutlierDetector[K]) Ejected() []K {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	now := d.group.clock.Now()
+
+	var keys []K
+
+	for key, h := range d.hosts {
+		if now.Before(h.ejectedUntil) {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
+
+// IsEjected reports whether the host for key is currently ejected.
+func (d *OutlierDetector[K]) IsEjected(key K) bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	h, ok := d.hosts[key]
+
+	return ok && d.group.clock.Now().Before(h.ejectedUntil)
+}
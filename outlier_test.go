@@ -0,0 +1,120 @@
+package circuitbreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func record(t *testing.T, b *Breaker, success bool) {
+	t.Helper()
+
+	done, err := b.Allow()
+	require.NoError(t, err)
+
+	done(success)
+}
+
+func TestOutlierDetectorConsecutiveFailures(t *testing.T) {
+	c := &testClock{
+		now: time.Now(),
+	}
+
+	g, err := NewGroup[string](WithClock(c), WithReadyToTrip(func(Counts) bool { return false }))
+	require.NoError(t, err)
+
+	d := NewOutlierDetector(g, WithConsecutiveFailures(3), WithEjectionTime(10*time.Second, time.Minute))
+
+	for i := 0; i < 3; i++ {
+		record(t, g.Get("a"), false)
+		record(t, g.Get("b"), true)
+	}
+
+	d.Detect()
+
+	require.Equal(t, []string{"a"}, d.Ejected())
+	require.True(t, d.IsEjected("a"))
+	require.False(t, d.IsEjected("b"))
+	require.Equal(t, StateOpen, g.Get("a").State())
+
+	c.advance(11 * time.Second)
+
+	require.False(t, d.IsEjected("a"))
+	require.Equal(t, StateHalfOpen, g.Get("a").State())
+
+	// the failures before the ejection are not counted again
+	d.Detect()
+	require.Empty(t, d.Ejected())
+}
+
+func TestOutlierDetectorEjectionTime(t *testing.T) {
+	c := &testClock{
+		now: time.Now(),
+	}
+
+	g, err := NewGroup[string](WithClock(c), WithReadyToTrip(func(Counts) bool { return false }))
+	require.NoError(t, err)
+
+	d := NewOutlierDetector(g, WithConsecutiveFailures(1), WithEjectionTime(10*time.Second, 15*time.Second))
+
+	b := g.Get("a")
+
+	record(t, b, false)
+	d.Detect()
+	require.True(t, d.IsEjected("a"))
+
+	c.advance(11 * time.Second)
+	require.False(t, d.IsEjected("a"))
+
+	record(t, b, false)
+	d.Detect()
+
+	// ejected for twice the base time, limited to the maximum
+	c.advance(14 * time.Second)
+	require.True(t, d.IsEjected("a"))
+
+	c.advance(2 * time.Second)
+	require.False(t, d.IsEjected("a"))
+}
+
+func TestOutlierDetectorSuccessRate(t *testing.T) {
+	g, err := NewGroup[string](WithReadyToTrip(func(Counts) bool { return false }), WithWindow(time.Minute))
+	require.NoError(t, err)
+
+	d := NewOutlierDetector(g,
+		WithConsecutiveFailures(0),
+		WithSuccessRateDeviation(1.9, 5, 10),
+		WithMaxEjectionPercent(20),
+	)
+
+	for _, key := range []string{"a", "b", "c", "d"} {
+		for i := 0; i < 10; i++ {
+			record(t, g.Get(key), true)
+		}
+	}
+
+	for i := 0; i < 10; i++ {
+		record(t, g.Get("e"), i%2 == 0)
+	}
+
+	d.Detect()
+
+	require.Equal(t, []string{"e"}, d.Ejected())
+}
+
+func TestOutlierDetectorMaxEjectionPercent(t *testing.T) {
+	g, err := NewGroup[string](WithReadyToTrip(func(Counts) bool { return false }))
+	require.NoError(t, err)
+
+	d := NewOutlierDetector(g, WithConsecutiveFailures(1))
+
+	for _, key := range []string{"a", "b", "c"} {
+		record(t, g.Get(key), false)
+	}
+
+	d.Detect()
+
+	// one host may always be ejected
+	require.Len(t, d.Ejected(), 1)
+}
@@ -0,0 +1,68 @@
+package circuitbreaker
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// AllowPartial is like Allow, but the returned callback records a request for many items, such as a call to
+// a bulk API, as successes successful and failures failed requests, so a batch where a few items fail does not
+// count as a single failure. The counts are updated as if the items were recorded one at a time, with the
+// failures last, and the state is evaluated once. Features that observe the request as a whole, such as
+// WithLatencySampling and WithConcurrencyLimit, see a failure if any item failed.
+// A request with no items is ignored.
+func (b *Breaker) AllowPartial() (func(successes uint64, failures uint64), error) {
+	start, inflight, err := b.admit(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(successes uint64, failures uint64) {
+		b.recordPartial(start, inflight, successes, failures)
+	}, nil
+}
+
+// recordPartial adds every item but the last to the counts, then records the last as the outcome of the request.
+func (b *Breaker) recordPartial(start time.Time, inflight int, successes uint64, failures uint64) {
+	n := successes + failures
+	if n == 0 {
+		b.record(start, inflight, OutcomeIgnored)
+		return
+	}
+
+	last := OutcomeSuccess
+	if failures > 0 {
+		last = OutcomeFailure
+		failures--
+	} else {
+		successes--
+	}
+
+	// the request itself was counted when it was allowed
+	if n > 1 {
+		b.buckets.Add(start, countRequests, n-1)
+		b.addShared(Counts{Requests: n - 1})
+	}
+
+	if b.options.errorBudget != nil {
+		b.options.errorBudget.addCounts(start, successes+failures, failures)
+	}
+
+	if successes > 0 {
+		b.flushSuccesses()
+		b.buckets.Add(start, countSuccesses, successes)
+		b.addShared(Counts{TotalSuccesses: successes})
+		atomic.AddUint64(&b.consecutiveSuccesses, successes)
+		atomic.StoreUint64(&b.consecutiveFailures, 0)
+	}
+
+	if failures > 0 {
+		b.flushSuccesses()
+		b.buckets.Add(start, countFailures, failures)
+		b.addShared(Counts{TotalFailures: failures})
+		atomic.AddUint64(&b.consecutiveFailures, failures)
+		atomic.StoreUint64(&b.consecutiveSuccesses, 0)
+	}
+
+	b.record(start, inflight, last)
+}
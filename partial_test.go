@@ -0,0 +1,58 @@
+package circuitbreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllowPartial(t *testing.T) {
+	b, err := New(WithWindow(time.Minute), WithReadyToTrip(TripOnFailureRate(0.5, 10)))
+	require.NoError(t, err)
+
+	done, err := b.AllowPartial()
+	require.NoError(t, err)
+	done(9, 1)
+
+	require.Equal(t, Counts{Requests: 10, TotalSuccesses: 9, TotalFailures: 1, ConsecutiveFailures: 1}, b.counts())
+	require.Equal(t, StateClosed, b.State())
+
+	// a batch with no items is ignored
+	done, err = b.AllowPartial()
+	require.NoError(t, err)
+	done(0, 0)
+	require.Equal(t, uint64(11), b.counts().Requests)
+
+	token, err := b.AllowToken()
+	require.NoError(t, err)
+	token.RecordPartial(5, 0)
+	require.Equal(t, uint64(5), b.counts().ConsecutiveSuccesses)
+
+	// the state is evaluated once, with every item counted
+	done, err = b.AllowPartial()
+	require.NoError(t, err)
+	done(0, 20)
+
+	require.Equal(t, StateOpen, b.State())
+	require.Equal(t, Counts{Requests: 36, TotalSuccesses: 14, TotalFailures: 21, ConsecutiveFailures: 20}, b.counts())
+}
+
+func TestAllowPartialHalfOpen(t *testing.T) {
+	c := &testClock{
+		now: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	b, err := New(WithClock(c), WithWindow(time.Minute), WithMaxRequests(3))
+	require.NoError(t, err)
+
+	b.Trip()
+	c.advance(time.Second)
+	require.Equal(t, StateHalfOpen, b.State())
+
+	done, err := b.AllowPartial()
+	require.NoError(t, err)
+	done(3, 0)
+
+	require.Equal(t, StateClosed, b.State())
+}
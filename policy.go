@@ -0,0 +1,62 @@
+package circuitbreaker
+
+import (
+	"context"
+	"time"
+)
+
+// Policy runs an attempt, adding behavior before and after it. A Breaker is a Policy, as are RetryPolicy,
+// Fallback, and TimeoutPolicy. Policies are composed with a Pipeline.
+type Policy interface {
+	// Execute runs fn, which runs the remaining policies of a Pipeline and the attempt.
+	Execute(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// PolicyFunc is a function that implements Policy.
+type PolicyFunc func(ctx context.Context, fn func(ctx context.Context) error) error
+
+// Execute calls f.
+func (f PolicyFunc) Execute(ctx context.Context, fn func(ctx context.Context) error) error {
+	return f(ctx, fn)
+}
+
+// Pipeline runs attempts through policies in a declared order: the first Policy is outermost, and the
+// last runs closest to the attempt. For example, Pipeline{fallback, retry, breaker, TimeoutPolicy(d)}
+// falls back once retries are exhausted, records each retry with the breaker, and times out each attempt.
+type Pipeline []Policy
+
+// Run runs fn through the policies.
+func (p Pipeline) Run(ctx context.Context, fn Job) error {
+	if len(p) == 0 {
+		return fn(ctx)
+	}
+
+	return p[0].Execute(ctx, func(ctx context.Context) error {
+		return p[1:].Run(ctx, fn)
+	})
+}
+
+// Execute implements Policy, so a Pipeline can be nested in another.
+func (p Pipeline) Execute(ctx context.Context, fn func(ctx context.Context) error) error {
+	return p.Run(ctx, fn)
+}
+
+// Execute implements Policy. It runs fn and calls the fallback if fn returns an error.
+func (f Fallback) Execute(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := fn(ctx); err != nil {
+		return f(ctx, err)
+	}
+
+	return nil
+}
+
+// TimeoutPolicy returns a Policy that runs attempts with a context that times out after d.
+// Unlike WithCallTimeout, attempts that ignore their context are not abandoned.
+func TimeoutPolicy(d time.Duration) Policy {
+	return PolicyFunc(func(ctx context.Context, fn func(ctx context.Context) error) error {
+		ctx, cancel := context.WithTimeout(ctx, d)
+		defer cancel()
+
+		return fn(ctx)
+	})
+}
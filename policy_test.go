@@ -0,0 +1,69 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPipelineOrder(t *testing.T) {
+	var calls []string
+
+	policy := func(name string) Policy {
+		return PolicyFunc(func(ctx context.Context, fn func(ctx context.Context) error) error {
+			calls = append(calls, "before "+name)
+			err := fn(ctx)
+			calls = append(calls, "after "+name)
+
+			return err
+		})
+	}
+
+	err := Pipeline{policy("a"), Pipeline{policy("b"), policy("c")}}.Run(context.Background(), func(context.Context) error {
+		calls = append(calls, "attempt")
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"before a", "before b", "before c", "attempt", "after c", "after b", "after a"}, calls)
+}
+
+func TestPipeline(t *testing.T) {
+	b, err := New(WithWindow(time.Minute), WithReadyToTrip(func(c Counts) bool { return c.TotalFailures >= 3 }))
+	require.NoError(t, err)
+
+	fail := errors.New("fail")
+	fallback := errors.New("fallback")
+	attempts := 0
+
+	p := Pipeline{
+		Fallback(func(_ context.Context, err error) error {
+			return fmt.Errorf("%w: %w", fallback, err)
+		}),
+		RetryPolicy{MaxAttempts: 5},
+		b,
+		TimeoutPolicy(time.Minute),
+	}
+
+	err = p.Run(context.Background(), func(ctx context.Context) error {
+		_, ok := ctx.Deadline()
+		require.True(t, ok)
+
+		attempts++
+
+		return fail
+	})
+
+	// retries stop once the breaker rejects an attempt, and the fallback sees its error
+	require.ErrorIs(t, err, fallback)
+	require.ErrorIs(t, err, ErrOpenState)
+	require.Equal(t, 3, attempts)
+	require.Equal(t, StateOpen, b.State())
+
+	err = p.Run(context.Background(), func(context.Context) error { return nil })
+	require.ErrorIs(t, err, fallback)
+	require.Equal(t, 3, attempts)
+}
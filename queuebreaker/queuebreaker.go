@@ -0,0 +1,216 @@
+// Package queuebreaker pauses message consumption while a circuit breaker is open.
+//
+// Consumers of Kafka, SQS, NATS, and similar queues keep pulling messages while the
+// downstream processor is failing, sending them straight to a dead letter queue.
+// A Gate watches the breaker for the processor and pauses consumption while it is open.
+package queuebreaker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+type options struct {
+	onPause  func()
+	onResume func()
+}
+
+// Option sets Gate options.
+type Option func(*options)
+
+// WithPause sets a function that is called when the breaker opens, such as a function that
+// pauses a Kafka consumer's partitions.
+func WithPause(fn func()) Option {
+	return func(o *options) {
+		o.onPause = fn
+	}
+}
+
+// WithResume sets a function that is called when the breaker leaves the open state.
+func WithResume(fn func()) Option {
+	return func(o *options) {
+		o.onResume = fn
+	}
+}
+
+// Gate pauses consumption while a breaker is open. Consumers either use the Pause and Resume
+// callbacks, wait on Ready, or call Acquire before processing each message.
+//
+// A Gate watches the breaker's state in a goroutine and moves an open breaker to half-open
+// once its timeout expires, so consumption resumes without a request to the breaker.
+// Call Close to stop it.
+type Gate struct {
+	breaker     *circuitbreaker.Breaker
+	options     options
+	notify      chan struct{}
+	done        chan struct{}
+	stopped     chan struct{}
+	unsubscribe func()
+
+	lock    sync.Mutex
+	paused  bool
+	ready   chan struct{} // closed while not paused
+	changed chan struct{} // closed and replaced whenever the breaker's state is checked
+}
+
+// NewGate creates a Gate for a breaker.
+func NewGate(b *circuitbreaker.Breaker, opts ...Option) *Gate {
+	o := options{
+		onPause:  func() {},
+		onResume: func() {},
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	ready := make(chan struct{})
+	close(ready)
+
+	g := &Gate{
+		breaker: b,
+		options: o,
+		notify:  make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+		ready:   ready,
+		changed: make(chan struct{}),
+	}
+
+	g.unsubscribe = b.Subscribe(func(circuitbreaker.Transition) {
+		select {
+		case g.notify <- struct{}{}:
+		default:
+		}
+	})
+
+	go g.run()
+
+	return g
+}
+
+// Close stops watching the breaker. It does not call the resume function.
+func (g *Gate) Close() {
+	g.unsubscribe()
+	close(g.done)
+	<-g.stopped
+}
+
+// Paused reports whether consumption is paused.
+func (g *Gate) Paused() bool {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	return g.paused
+}
+
+// Ready returns a channel that is closed when consumption may proceed.
+// While consumption is not paused, the channel is already closed.
+func (g *Gate) Ready() <-chan struct{} {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	return g.ready
+}
+
+// Wait blocks until consumption may proceed or the context is done.
+func (g *Gate) Wait(ctx context.Context) error {
+	select {
+	case <-g.Ready():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Acquire waits until the breaker allows processing a message and returns a function to record
+// its outcome, which must be called. In the half-open state, the breaker's maximum number of requests
+// limits how many messages are processed concurrently; Acquire waits for the state to change rather
+// than returning ErrTooManyRequests.
+func (g *Gate) Acquire(ctx context.Context) (func(circuitbreaker.Outcome), error) {
+	for {
+		if err := g.Wait(ctx); err != nil {
+			return nil, err
+		}
+
+		g.lock.Lock()
+		changed := g.changed
+		g.lock.Unlock()
+
+		done, err := g.breaker.AllowOutcome()
+		if err == nil {
+			return done, nil
+		}
+
+		if !errors.Is(err, circuitbreaker.ErrTooManyRequests) && !errors.Is(err, circuitbreaker.ErrOpenState) {
+			return nil, err
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (g *Gate) run() {
+	defer close(g.stopped)
+
+	timer := time.NewTimer(time.Hour)
+	timer.Stop()
+
+	for {
+		// Status moves an open breaker to half-open once its timeout expires.
+		status := g.breaker.Status()
+		g.update(status.State == circuitbreaker.StateOpen)
+
+		var wake <-chan time.Time
+		if status.State == circuitbreaker.StateOpen && status.RetryAfter > 0 {
+			timer.Reset(status.RetryAfter)
+			wake = timer.C
+		}
+
+		select {
+		case <-g.notify:
+		case <-wake:
+		case <-g.done:
+			timer.Stop()
+			return
+		}
+
+		timer.Stop()
+	}
+}
+
+func (g *Gate) update(paused bool) {
+	g.lock.Lock()
+
+	close(g.changed)
+	g.changed = make(chan struct{})
+
+	if paused == g.paused {
+		g.lock.Unlock()
+		return
+	}
+
+	g.paused = paused
+
+	if paused {
+		g.ready = make(chan struct{})
+	} else {
+		close(g.ready)
+	}
+
+	g.lock.Unlock()
+
+	if paused {
+		g.options.onPause()
+	} else {
+		g.options.onResume()
+	}
+}
@@ -0,0 +1,111 @@
+package queuebreaker
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+func TestGate(t *testing.T) {
+	b, err := circuitbreaker.New(circuitbreaker.WithTimeout(time.Second))
+	require.NoError(t, err)
+
+	var pauses, resumes atomic.Int32
+
+	g := NewGate(b,
+		WithPause(func() { pauses.Add(1) }),
+		WithResume(func() { resumes.Add(1) }),
+	)
+	defer g.Close()
+
+	require.False(t, g.Paused())
+	require.NoError(t, g.Wait(context.Background()))
+
+	b.Trip()
+
+	require.Eventually(t, g.Paused, time.Second, time.Millisecond)
+	require.Equal(t, int32(1), pauses.Load())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	require.ErrorIs(t, g.Wait(ctx), context.DeadlineExceeded)
+
+	// the gate moves the breaker to half-open once the timeout expires
+	select {
+	case <-g.Ready():
+	case <-time.After(5 * time.Second):
+		t.Fatal("gate did not resume")
+	}
+
+	require.False(t, g.Paused())
+	require.Equal(t, int32(1), resumes.Load())
+	require.Equal(t, circuitbreaker.StateHalfOpen, b.State())
+}
+
+func TestGateAcquire(t *testing.T) {
+	b, err := circuitbreaker.New(
+		circuitbreaker.WithTimeout(time.Second),
+		circuitbreaker.WithMaxRequests(1),
+	)
+	require.NoError(t, err)
+
+	g := NewGate(b)
+	defer g.Close()
+
+	b.Trip()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	done, err := g.Acquire(ctx)
+	require.NoError(t, err)
+	require.Equal(t, circuitbreaker.StateHalfOpen, b.State())
+
+	acquired := make(chan func(circuitbreaker.Outcome))
+
+	go func() {
+		done, err := g.Acquire(ctx)
+		if err == nil {
+			acquired <- done
+		}
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("acquired more than the maximum requests in half-open")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	done(circuitbreaker.OutcomeSuccess)
+
+	select {
+	case done := <-acquired:
+		done(circuitbreaker.OutcomeSuccess)
+	case <-ctx.Done():
+		t.Fatal("did not acquire after the breaker closed")
+	}
+
+	require.Equal(t, circuitbreaker.StateClosed, b.State())
+}
+
+func TestGateAcquireCanceled(t *testing.T) {
+	b, err := circuitbreaker.New()
+	require.NoError(t, err)
+
+	g := NewGate(b)
+	defer g.Close()
+
+	b.ForceOpen()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = g.Acquire(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
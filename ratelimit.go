@@ -0,0 +1,28 @@
+package circuitbreaker
+
+import (
+	"errors"
+
+	"golang.org/x/time/rate"
+)
+
+// ErrRateLimited is returned when the Breaker does not allow a request because it would
+// exceed the rate limit.
+var ErrRateLimited = errors.New("circuit breaker rate limit exceeded")
+
+// WithRateLimit limits the rate of requests allowed by the Breaker to r per second, with bursts of up to burst requests,
+// using a token bucket. Requests over the limit are rejected with ErrRateLimited and are not counted as requests.
+// The limit applies in the closed and half-open states.
+// There is no default.
+func WithRateLimit(r rate.Limit, burst int) Option {
+	return func(o *Options) {
+		o.rateLimiter = rate.NewLimiter(r, burst)
+	}
+}
+
+// IsRejected reports whether err is, or wraps, an error returned when the Breaker does not allow a request,
+// rather than an error from the request itself.
+func IsRejected(err error) bool {
+	return errors.Is(err, ErrOpenState) || errors.Is(err, ErrTooManyRequests) || errors.Is(err, ErrRateLimited) ||
+		errors.Is(err, ErrLimitExceeded) || errors.Is(err, ErrInsufficientDeadline)
+}
@@ -0,0 +1,66 @@
+package circuitbreaker
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+func TestRateLimit(t *testing.T) {
+	b, err := New(WithRateLimit(rate.Limit(0.001), 2))
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		done, err := b.Allow()
+		require.NoError(t, err)
+		done(true)
+	}
+
+	_, err = b.Allow()
+	require.ErrorIs(t, err, ErrRateLimited)
+
+	counts := b.counts()
+	require.Equal(t, uint64(2), counts.Requests)
+	require.Equal(t, uint64(2), counts.TotalSuccesses)
+	require.Equal(t, StateClosed, b.State())
+
+	b.Trip()
+
+	// the open state is checked first
+	_, err = b.Allow()
+	require.ErrorIs(t, err, ErrOpenState)
+}
+
+func TestRateLimitClock(t *testing.T) {
+	c := &testClock{now: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)}
+
+	b, err := New(WithClock(c), WithRateLimit(rate.Limit(1), 1))
+	require.NoError(t, err)
+
+	done, err := b.Allow()
+	require.NoError(t, err)
+	done(true)
+
+	_, err = b.Allow()
+	require.ErrorIs(t, err, ErrRateLimited)
+
+	// tokens are added as the Clock advances
+	c.advance(time.Second)
+
+	done, err = b.Allow()
+	require.NoError(t, err)
+	done(true)
+}
+
+func TestIsRejected(t *testing.T) {
+	for _, err := range []error{ErrOpenState, ErrTooManyRequests, ErrRateLimited, ErrLimitExceeded, ErrInsufficientDeadline} {
+		require.True(t, IsRejected(fmt.Errorf("request: %w", err)), err)
+	}
+
+	require.False(t, IsRejected(errors.New("connection refused")))
+	require.False(t, IsRejected(nil))
+}
@@ -0,0 +1,44 @@
+package circuitbreaker
+
+import "net/http"
+
+// Readiness is the body of a ReadinessHandler response.
+type Readiness struct {
+	Ready bool `json:"ready"`
+	// Open are the names of the critical Breakers that are open.
+	Open []string `json:"open,omitempty"`
+}
+
+// ReadinessHandler returns a handler for a readiness probe, such as a Kubernetes readinessProbe.
+// It responds with 503 Service Unavailable while any Breaker in the Registry created with WithCritical is open,
+// so the orchestrator stops routing to an instance whose core dependencies are failing, and 200 OK otherwise.
+// Other Breakers are ignored. Do not use it for a liveness probe, since restarting the instance
+// does not fix its dependencies.
+func ReadinessHandler(reg *Registry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		readiness := Readiness{
+			Ready: true,
+		}
+
+		for _, b := range reg.Breakers() {
+			if b.critical() && b.State() == StateOpen {
+				readiness.Ready = false
+				readiness.Open = append(readiness.Open, b.Name())
+			}
+		}
+
+		code := http.StatusOK
+		if !readiness.Ready {
+			code = http.StatusServiceUnavailable
+		}
+
+		writeJSON(w, code, readiness)
+	})
+}
+
+func (b *Breaker) critical() bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.options.critical
+}
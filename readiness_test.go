@@ -0,0 +1,51 @@
+package circuitbreaker
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadinessHandler(t *testing.T) {
+	reg := NewRegistry()
+
+	db, err := New(WithName("db"), WithCritical())
+	require.NoError(t, err)
+	require.NoError(t, reg.Register(db))
+
+	recommendations, err := New(WithName("recommendations"))
+	require.NoError(t, err)
+	require.NoError(t, reg.Register(recommendations))
+
+	h := ReadinessHandler(reg)
+
+	probe := func() (int, Readiness) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+		var readiness Readiness
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&readiness))
+
+		return w.Code, readiness
+	}
+
+	code, readiness := probe()
+	require.Equal(t, http.StatusOK, code)
+	require.True(t, readiness.Ready)
+
+	// non-critical breakers are ignored
+	recommendations.Trip()
+
+	code, _ = probe()
+	require.Equal(t, http.StatusOK, code)
+
+	db.Trip()
+
+	code, readiness = probe()
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.Equal(t, Readiness{Open: []string{"db"}}, readiness)
+	require.True(t, db.Options().Critical)
+}
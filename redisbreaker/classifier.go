@@ -0,0 +1,57 @@
+package redisbreaker
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+// Classifier returns the outcome of a Redis command that returned err.
+type Classifier func(err error) circuitbreaker.Outcome
+
+// DefaultClassifier classifies errors that indicate an unhealthy Redis as failures:
+// network errors, timeouts, pool timeouts, and LOADING, READONLY, MASTERDOWN,
+// CLUSTERDOWN, and max clients errors. redis.Nil is a success. Other errors
+// returned by Redis, such as WRONGTYPE, are successes since Redis responded.
+// Commands canceled by the caller and commands on a closed client are ignored.
+func DefaultClassifier(err error) circuitbreaker.Outcome {
+	if err == nil || errors.Is(err, redis.Nil) {
+		return circuitbreaker.OutcomeSuccess
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, redis.ErrClosed) {
+		return circuitbreaker.OutcomeIgnored
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, redis.ErrPoolTimeout) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) {
+		return circuitbreaker.OutcomeFailure
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return circuitbreaker.OutcomeFailure
+	}
+
+	if redis.IsLoadingError(err) ||
+		redis.IsReadOnlyError(err) ||
+		redis.IsMasterDownError(err) ||
+		redis.IsClusterDownError(err) ||
+		redis.IsMaxClientsError(err) {
+		return circuitbreaker.OutcomeFailure
+	}
+
+	var redisErr redis.Error
+	if errors.As(err, &redisErr) {
+		return circuitbreaker.OutcomeSuccess
+	}
+
+	return circuitbreaker.OutcomeFailure
+}
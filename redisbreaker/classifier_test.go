@@ -0,0 +1,35 @@
+package redisbreaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+func TestDefaultClassifier(t *testing.T) {
+	tests := []struct {
+		err     error
+		outcome circuitbreaker.Outcome
+	}{
+		{nil, circuitbreaker.OutcomeSuccess},
+		{redis.Nil, circuitbreaker.OutcomeSuccess},
+		{fmt.Errorf("get: %w", redis.Nil), circuitbreaker.OutcomeSuccess},
+		{context.Canceled, circuitbreaker.OutcomeIgnored},
+		{redis.ErrClosed, circuitbreaker.OutcomeIgnored},
+		{context.DeadlineExceeded, circuitbreaker.OutcomeFailure},
+		{redis.ErrPoolTimeout, circuitbreaker.OutcomeFailure},
+		{io.EOF, circuitbreaker.OutcomeFailure},
+		{errors.New("boom"), circuitbreaker.OutcomeFailure},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.outcome, DefaultClassifier(tt.err), "%v", tt.err)
+	}
+}
@@ -0,0 +1,22 @@
+module github.com/bakins/circuitbreaker/redisbreaker
+
+go 1.22
+
+require (
+	github.com/alicebob/miniredis/v2 v2.37.0
+	github.com/bakins/circuitbreaker v0.0.0
+	github.com/redis/go-redis/v9 v9.17.2
+	github.com/stretchr/testify v1.12.1
+)
+
+require (
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
+	github.com/kr/text v0.2.0 // indirect
+	github.com/yuin/gopher-lua v1.1.1 // indirect
+	go.yaml.in/yaml/v3 v3.0.5 // indirect
+	golang.org/x/time v0.10.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/bakins/circuitbreaker => ../
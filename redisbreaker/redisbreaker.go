@@ -0,0 +1,126 @@
+// Package redisbreaker provides go-redis hooks that run commands through a circuit breaker,
+// a Store that persists circuit breakers in Redis, and SharedCounts that aggregates their counts
+// across processes.
+package redisbreaker
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+type options struct {
+	classifier Classifier
+}
+
+// Option sets redisbreaker options.
+type Option func(*options)
+
+// WithClassifier sets the classifier used to record the outcome of commands.
+// Default is DefaultClassifier.
+func WithClassifier(c Classifier) Option {
+	return func(o *options) {
+		o.classifier = c
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		classifier: DefaultClassifier,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// Hook is a redis.Hook that runs commands and pipelines through a circuit breaker.
+// When the breaker does not allow a command, the error from the breaker is returned
+// and set on the command. A pipeline is a failure if any of its commands is.
+type Hook struct {
+	breaker *circuitbreaker.Breaker
+	options options
+}
+
+var _ redis.Hook = &Hook{}
+
+// NewHook creates a hook. Add it to a client using AddHook.
+func NewHook(b *circuitbreaker.Breaker, opts ...Option) *Hook {
+	return &Hook{
+		breaker: b,
+		options: newOptions(opts),
+	}
+}
+
+// AddClusterHooks adds a hook to each node of a cluster client, using a breaker from the
+// group keyed by the node's address, so that one failing node does not open the breaker for the others.
+func AddClusterHooks(c *redis.ClusterClient, g *circuitbreaker.Group[string], opts ...Option) {
+	c.OnNewNode(func(node *redis.Client) {
+		node.AddHook(NewHook(g.Get(node.Options().Addr), opts...))
+	})
+}
+
+// DialHook implements redis.Hook. Dials are not run through the breaker since their
+// errors are returned by the commands that caused them.
+func (h *Hook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+// ProcessHook implements redis.Hook.
+func (h *Hook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		done, err := h.breaker.AllowOutcome()
+		if err != nil {
+			cmd.SetErr(err)
+			return err
+		}
+
+		err = next(ctx, cmd)
+
+		done(h.options.classifier(err))
+
+		return err
+	}
+}
+
+// ProcessPipelineHook implements redis.Hook.
+func (h *Hook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		done, err := h.breaker.AllowOutcome()
+		if err != nil {
+			for _, cmd := range cmds {
+				cmd.SetErr(err)
+			}
+
+			return err
+		}
+
+		err = next(ctx, cmds)
+
+		done(h.pipelineOutcome(err, cmds))
+
+		return err
+	}
+}
+
+func (h *Hook) pipelineOutcome(err error, cmds []redis.Cmder) circuitbreaker.Outcome {
+	outcome := h.options.classifier(err)
+	if outcome == circuitbreaker.OutcomeFailure {
+		return outcome
+	}
+
+	for _, cmd := range cmds {
+		switch h.options.classifier(cmd.Err()) {
+		case circuitbreaker.OutcomeFailure:
+			return circuitbreaker.OutcomeFailure
+		case circuitbreaker.OutcomeSuccess:
+			outcome = circuitbreaker.OutcomeSuccess
+		}
+	}
+
+	return outcome
+}
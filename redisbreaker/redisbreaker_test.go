@@ -0,0 +1,138 @@
+package redisbreaker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+func alwaysTrip(circuitbreaker.Counts) bool {
+	return true
+}
+
+func newTestClient(t *testing.T, b *circuitbreaker.Breaker, opts ...Option) (*miniredis.Miniredis, *redis.Client) {
+	s := miniredis.RunT(t)
+
+	c := redis.NewClient(&redis.Options{
+		Addr:       s.Addr(),
+		MaxRetries: -1,
+	})
+
+	t.Cleanup(func() {
+		_ = c.Close()
+	})
+
+	c.AddHook(NewHook(b, opts...))
+
+	return s, c
+}
+
+func TestHook(t *testing.T) {
+	b, err := circuitbreaker.New(circuitbreaker.WithReadyToTrip(alwaysTrip))
+	require.NoError(t, err)
+
+	s, c := newTestClient(t, b)
+
+	ctx := context.Background()
+
+	require.NoError(t, c.Set(ctx, "key", "value", 0).Err())
+
+	err = c.Get(ctx, "missing").Err()
+	require.ErrorIs(t, err, redis.Nil)
+	require.Equal(t, circuitbreaker.StateClosed, b.State())
+
+	s.SetError("LOADING Redis is loading the dataset in memory")
+
+	err = c.Get(ctx, "key").Err()
+	require.Error(t, err)
+	require.Equal(t, circuitbreaker.StateOpen, b.State())
+
+	s.SetError("")
+
+	cmd := c.Get(ctx, "key")
+	require.ErrorIs(t, cmd.Err(), circuitbreaker.ErrOpenState)
+}
+
+func TestHookWrongType(t *testing.T) {
+	b, err := circuitbreaker.New(circuitbreaker.WithReadyToTrip(alwaysTrip))
+	require.NoError(t, err)
+
+	_, c := newTestClient(t, b)
+
+	ctx := context.Background()
+
+	require.NoError(t, c.Set(ctx, "key", "value", 0).Err())
+
+	err = c.LPush(ctx, "key", "value").Err()
+	require.Error(t, err)
+	require.Equal(t, circuitbreaker.StateClosed, b.State())
+}
+
+func TestHookPipeline(t *testing.T) {
+	b, err := circuitbreaker.New(circuitbreaker.WithReadyToTrip(alwaysTrip))
+	require.NoError(t, err)
+
+	s, c := newTestClient(t, b)
+
+	ctx := context.Background()
+
+	_, err = c.Pipelined(ctx, func(p redis.Pipeliner) error {
+		p.Set(ctx, "key", "value", 0)
+		p.Get(ctx, "missing")
+		return nil
+	})
+	require.ErrorIs(t, err, redis.Nil)
+	require.Equal(t, circuitbreaker.StateClosed, b.State())
+
+	s.Close()
+
+	_, err = c.Pipelined(ctx, func(p redis.Pipeliner) error {
+		p.Get(ctx, "key")
+		return nil
+	})
+	require.Error(t, err)
+	require.Equal(t, circuitbreaker.StateOpen, b.State())
+
+	cmds, err := c.Pipelined(ctx, func(p redis.Pipeliner) error {
+		p.Get(ctx, "key")
+		p.Get(ctx, "other")
+		return nil
+	})
+	require.ErrorIs(t, err, circuitbreaker.ErrOpenState)
+
+	for _, cmd := range cmds {
+		require.ErrorIs(t, cmd.Err(), circuitbreaker.ErrOpenState)
+	}
+}
+
+func TestAddClusterHooks(t *testing.T) {
+	s := miniredis.RunT(t)
+
+	g, err := circuitbreaker.NewGroup[string](circuitbreaker.WithReadyToTrip(alwaysTrip))
+	require.NoError(t, err)
+
+	c := redis.NewClusterClient(&redis.ClusterOptions{
+		Addrs:      []string{s.Addr()},
+		MaxRetries: -1,
+	})
+
+	t.Cleanup(func() {
+		_ = c.Close()
+	})
+
+	AddClusterHooks(c, g)
+
+	ctx := context.Background()
+
+	require.NoError(t, c.Set(ctx, "key", "value", 0).Err())
+
+	s.SetError("CLUSTERDOWN The cluster is down")
+
+	require.Error(t, c.Get(ctx, "key").Err())
+	require.Equal(t, circuitbreaker.StateOpen, g.Get(s.Addr()).State())
+}
@@ -0,0 +1,262 @@
+package redisbreaker
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+type sharedCountsOptions struct {
+	prefix       string
+	syncInterval time.Duration
+}
+
+// SharedCountsOption sets SharedCounts options.
+type SharedCountsOption func(*sharedCountsOptions)
+
+// WithCountsKeyPrefix sets the prefix of the keys used by SharedCounts.
+// Default is "circuitbreaker:counts:".
+func WithCountsKeyPrefix(prefix string) SharedCountsOption {
+	return func(o *sharedCountsOptions) {
+		o.prefix = prefix
+	}
+}
+
+// WithSyncInterval sets how often counts are sent to and read from Redis.
+// Default is 1 second.
+func WithSyncInterval(d time.Duration) SharedCountsOption {
+	return func(o *sharedCountsOptions) {
+		o.syncInterval = d
+	}
+}
+
+// SharedCounts is a circuitbreaker.SharedCounts that aggregates counts in Redis, using a hash per breaker per second
+// that each process increments. Counts are buffered and sent to Redis at the sync interval, when the totals
+// across processes are also read, so requests never wait on Redis.
+type SharedCounts struct {
+	client  redis.UniversalClient
+	pending map[bucket]circuitbreaker.Counts
+	syncing map[bucket]circuitbreaker.Counts
+	remote  map[string]circuitbreaker.Counts
+	windows map[string]time.Duration
+	done    chan struct{}
+	stopped chan struct{}
+	options sharedCountsOptions
+	lock    sync.Mutex
+	once    sync.Once
+}
+
+var _ circuitbreaker.SharedCounts = &SharedCounts{}
+
+type bucket struct {
+	name   string
+	second int64
+}
+
+// NewSharedCounts creates a SharedCounts and starts syncing with Redis.
+// Call Close to stop.
+func NewSharedCounts(client redis.UniversalClient, opts ...SharedCountsOption) *SharedCounts {
+	o := sharedCountsOptions{
+		prefix:       "circuitbreaker:counts:",
+		syncInterval: time.Second,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	s := &SharedCounts{
+		client:  client,
+		pending: make(map[bucket]circuitbreaker.Counts),
+		syncing: make(map[bucket]circuitbreaker.Counts),
+		remote:  make(map[string]circuitbreaker.Counts),
+		windows: make(map[string]time.Duration),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+		options: o,
+	}
+
+	go s.run()
+
+	return s
+}
+
+// Add implements circuitbreaker.SharedCounts.
+func (s *SharedCounts) Add(name string, delta circuitbreaker.Counts) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	k := bucket{name: name, second: time.Now().Unix()}
+	s.pending[k] = addCounts(s.pending[k], delta)
+}
+
+// Counts implements circuitbreaker.SharedCounts. It returns the totals last read from Redis
+// plus the counts of this process that have not been read back yet.
+func (s *SharedCounts) Counts(name string, window time.Duration) circuitbreaker.Counts {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.windows[name] = window
+
+	oldest := time.Now().Add(-window).Unix()
+
+	c := s.remote[name]
+
+	for _, local := range []map[bucket]circuitbreaker.Counts{s.pending, s.syncing} {
+		for k, delta := range local {
+			if k.name == name && k.second > oldest {
+				c = addCounts(c, delta)
+			}
+		}
+	}
+
+	return c
+}
+
+// Close sends pending counts to Redis and stops syncing.
+func (s *SharedCounts) Close() {
+	s.once.Do(func() {
+		close(s.done)
+		<-s.stopped
+	})
+}
+
+func (s *SharedCounts) run() {
+	defer close(s.stopped)
+
+	ticker := time.NewTicker(s.options.syncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.sync(context.Background())
+		case <-s.done:
+			s.sync(context.Background())
+			return
+		}
+	}
+}
+
+func (s *SharedCounts) key(k bucket) string {
+	return s.options.prefix + k.name + ":" + strconv.FormatInt(k.second, 10)
+}
+
+func (s *SharedCounts) sync(ctx context.Context) {
+	s.lock.Lock()
+
+	s.syncing, s.pending = s.pending, make(map[bucket]circuitbreaker.Counts)
+
+	windows := make(map[string]time.Duration, len(s.windows))
+	for name, w := range s.windows {
+		windows[name] = w
+	}
+
+	syncing := s.syncing
+
+	s.lock.Unlock()
+
+	_, err := s.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for k, c := range syncing {
+			key := s.key(k)
+
+			p.HIncrBy(ctx, key, "requests", int64(c.Requests))
+			p.HIncrBy(ctx, key, "successes", int64(c.TotalSuccesses))
+			p.HIncrBy(ctx, key, "failures", int64(c.TotalFailures))
+			p.Expire(ctx, key, max(windows[k.name], time.Minute)*2)
+		}
+
+		return nil
+	})
+	if err != nil {
+		// keep the counts so they are sent with the next sync
+		s.lock.Lock()
+
+		for k, c := range s.syncing {
+			s.pending[k] = addCounts(s.pending[k], c)
+		}
+
+		s.syncing = make(map[bucket]circuitbreaker.Counts)
+
+		s.lock.Unlock()
+
+		return
+	}
+
+	remote := s.read(ctx, windows)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if remote != nil {
+		s.remote = remote
+		s.syncing = make(map[bucket]circuitbreaker.Counts)
+	}
+}
+
+// read returns the totals of each breaker within its window, or nil on error.
+func (s *SharedCounts) read(ctx context.Context, windows map[string]time.Duration) map[string]circuitbreaker.Counts {
+	now := time.Now().Unix()
+
+	cmds := make(map[string][]*redis.SliceCmd, len(windows))
+
+	_, err := s.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for name, window := range windows {
+			for second := now - int64(window/time.Second) + 1; second <= now; second++ {
+				cmds[name] = append(cmds[name], p.HMGet(ctx, s.key(bucket{name: name, second: second}), "requests", "successes", "failures"))
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil
+	}
+
+	remote := make(map[string]circuitbreaker.Counts, len(cmds))
+
+	for name, buckets := range cmds {
+		var c circuitbreaker.Counts
+
+		for _, cmd := range buckets {
+			values := cmd.Val()
+			c = addCounts(c, circuitbreaker.Counts{
+				Requests:       parseCount(values, 0),
+				TotalSuccesses: parseCount(values, 1),
+				TotalFailures:  parseCount(values, 2),
+			})
+		}
+
+		remote[name] = c
+	}
+
+	return remote
+}
+
+func parseCount(values []interface{}, i int) uint64 {
+	if i >= len(values) {
+		return 0
+	}
+
+	v, ok := values[i].(string)
+	if !ok {
+		return 0
+	}
+
+	n, _ := strconv.ParseUint(v, 10, 64)
+
+	return n
+}
+
+func addCounts(a, b circuitbreaker.Counts) circuitbreaker.Counts {
+	return circuitbreaker.Counts{
+		Requests:       a.Requests + b.Requests,
+		TotalSuccesses: a.TotalSuccesses + b.TotalSuccesses,
+		TotalFailures:  a.TotalFailures + b.TotalFailures,
+	}
+}
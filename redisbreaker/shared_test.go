@@ -0,0 +1,64 @@
+package redisbreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+func TestSharedCounts(t *testing.T) {
+	s := miniredis.RunT(t)
+
+	newBreaker := func() (*circuitbreaker.Breaker, *SharedCounts) {
+		c := redis.NewClient(&redis.Options{Addr: s.Addr()})
+		shared := NewSharedCounts(c, WithSyncInterval(10*time.Millisecond))
+
+		t.Cleanup(func() {
+			shared.Close()
+			_ = c.Close()
+		})
+
+		b, err := circuitbreaker.New(
+			circuitbreaker.WithName("shared"),
+			circuitbreaker.WithWindow(time.Minute),
+			circuitbreaker.WithSharedCounts(shared),
+			circuitbreaker.WithReadyToTrip(func(c circuitbreaker.Counts) bool {
+				return c.TotalFailures >= 5
+			}),
+		)
+		require.NoError(t, err)
+
+		return b, shared
+	}
+
+	first, _ := newBreaker()
+	second, secondCounts := newBreaker()
+
+	for _, b := range []*circuitbreaker.Breaker{first, second} {
+		for i := 0; i < 2; i++ {
+			done, err := b.Allow()
+			require.NoError(t, err)
+			done(false)
+		}
+	}
+
+	require.Eventually(t, func() bool {
+		return secondCounts.Counts("shared", time.Minute).TotalFailures == 4
+	}, 5*time.Second, time.Millisecond)
+
+	require.Equal(t, uint64(4), second.Status().Counts.Requests)
+	require.Equal(t, uint64(2), second.Status().Counts.ConsecutiveFailures)
+
+	// the fifth failure across both processes trips the breaker
+	done, err := second.Allow()
+	require.NoError(t, err)
+	done(false)
+
+	require.Equal(t, circuitbreaker.StateOpen, second.State())
+	require.Equal(t, circuitbreaker.StateClosed, first.State())
+}
@@ -0,0 +1,130 @@
+package redisbreaker
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+type storeOptions struct {
+	prefix string
+	ttl    time.Duration
+}
+
+// StoreOption sets Store options.
+type StoreOption func(*storeOptions)
+
+// WithKeyPrefix sets the prefix of the keys and channels used by the Store.
+// Default is "circuitbreaker:".
+func WithKeyPrefix(prefix string) StoreOption {
+	return func(o *storeOptions) {
+		o.prefix = prefix
+	}
+}
+
+// WithTTL sets how long snapshots are kept after they are saved. Zero means snapshots do not expire.
+// Default is 24 hours.
+func WithTTL(ttl time.Duration) StoreOption {
+	return func(o *storeOptions) {
+		o.ttl = ttl
+	}
+}
+
+// Store is a circuitbreaker.Store that saves snapshots in Redis and publishes them
+// so that other processes that share the store are notified.
+type Store struct {
+	client  redis.UniversalClient
+	options storeOptions
+}
+
+var _ circuitbreaker.Store = &Store{}
+
+// NewStore creates a Store.
+func NewStore(client redis.UniversalClient, opts ...StoreOption) *Store {
+	o := storeOptions{
+		prefix: "circuitbreaker:",
+		ttl:    24 * time.Hour,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return &Store{
+		client:  client,
+		options: o,
+	}
+}
+
+func (s *Store) key(name string) string {
+	return s.options.prefix + name
+}
+
+func (s *Store) channel(name string) string {
+	return s.options.prefix + "updates:" + name
+}
+
+// Load implements circuitbreaker.Store.
+func (s *Store) Load(ctx context.Context, name string) ([]byte, error) {
+	data, err := s.client.Get(ctx, s.key(name)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+
+	return data, err
+}
+
+// Save implements circuitbreaker.Store.
+func (s *Store) Save(ctx context.Context, name string, data []byte) error {
+	_, err := s.client.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		p.Set(ctx, s.key(name), data, s.options.ttl)
+		p.Publish(ctx, s.channel(name), data)
+
+		return nil
+	})
+
+	return err
+}
+
+// Watch implements circuitbreaker.Store.
+func (s *Store) Watch(ctx context.Context, name string) (<-chan []byte, error) {
+	sub := s.client.Subscribe(ctx, s.channel(name))
+
+	// wait for the subscription to be confirmed so that no updates are missed
+	if _, err := sub.Receive(ctx); err != nil {
+		_ = sub.Close()
+		return nil, err
+	}
+
+	updates := make(chan []byte)
+
+	go func() {
+		defer close(updates)
+		defer sub.Close()
+
+		messages := sub.Channel()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+
+				select {
+				case updates <- []byte(msg.Payload):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return updates, nil
+}
@@ -0,0 +1,85 @@
+package redisbreaker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+func TestStore(t *testing.T) {
+	s := miniredis.RunT(t)
+
+	c := redis.NewClient(&redis.Options{Addr: s.Addr()})
+
+	t.Cleanup(func() {
+		_ = c.Close()
+	})
+
+	store := NewStore(c, WithTTL(time.Minute))
+
+	ctx := context.Background()
+
+	data, err := store.Load(ctx, "test")
+	require.NoError(t, err)
+	require.Nil(t, data)
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	updates, err := store.Watch(watchCtx, "test")
+	require.NoError(t, err)
+
+	require.NoError(t, store.Save(ctx, "test", []byte("snapshot")))
+
+	data, err = store.Load(ctx, "test")
+	require.NoError(t, err)
+	require.Equal(t, []byte("snapshot"), data)
+	require.Equal(t, time.Minute, s.TTL("circuitbreaker:test"))
+
+	select {
+	case data := <-updates:
+		require.Equal(t, []byte("snapshot"), data)
+	case <-time.After(time.Second):
+		t.Fatal("no update")
+	}
+}
+
+func TestStoreShared(t *testing.T) {
+	s := miniredis.RunT(t)
+
+	newBreaker := func() *circuitbreaker.Breaker {
+		c := redis.NewClient(&redis.Options{Addr: s.Addr()})
+
+		b, err := circuitbreaker.New(
+			circuitbreaker.WithName("shared"),
+			circuitbreaker.WithStore(NewStore(c), time.Hour),
+			circuitbreaker.WithTimeout(time.Minute),
+		)
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			_ = b.Close()
+			_ = c.Close()
+		})
+
+		return b
+	}
+
+	first := newBreaker()
+	second := newBreaker()
+
+	first.Trip()
+
+	require.Eventually(t, func() bool {
+		return second.State() == circuitbreaker.StateOpen
+	}, 5*time.Second, time.Millisecond)
+
+	third := newBreaker()
+	require.Equal(t, circuitbreaker.StateOpen, third.State())
+}
@@ -0,0 +1,45 @@
+package circuitbreaker
+
+// ReduceLatency calls f with the latencies sampled in the window, in seconds and oldest first, and returns its result,
+// so custom aggregations such as a standard deviation can be computed for trip conditions.
+// Only 1 in n successes are sampled, so use Latencies to weight them. f is called with no values unless
+// WithLatencySampling is used. f must not retain values or call Breaker methods.
+func (b *Breaker) ReduceLatency(f func(values []float64) float64) float64 {
+	samples := b.Latencies()
+	values := make([]float64, len(samples))
+
+	for i, s := range samples {
+		values[i] = s.Latency.Seconds()
+	}
+
+	return f(values)
+}
+
+// ReduceRequests calls f with the number of requests in each second of the window, oldest first, and returns its result.
+// Counts shared using WithSharedCounts are not included. f must not retain values or call Breaker methods.
+func (b *Breaker) ReduceRequests(f func(values []float64) float64) float64 {
+	return b.reduceCounts(countRequests, f)
+}
+
+// ReduceSuccesses is like ReduceRequests for successes.
+func (b *Breaker) ReduceSuccesses(f func(values []float64) float64) float64 {
+	return b.reduceCounts(countSuccesses, f)
+}
+
+// ReduceFailures is like ReduceRequests for failures.
+func (b *Breaker) ReduceFailures(f func(values []float64) float64) float64 {
+	return b.reduceCounts(countFailures, f)
+}
+
+func (b *Breaker) reduceCounts(counter int, f func(values []float64) float64) float64 {
+	b.flushSuccesses()
+
+	counts := b.buckets.Values(b.options.clock.Now(), counter, nil)
+	values := make([]float64, len(counts))
+
+	for i, c := range counts {
+		values[i] = float64(c)
+	}
+
+	return f(values)
+}
@@ -0,0 +1,53 @@
+package circuitbreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReduce(t *testing.T) {
+	c := &testClock{
+		now: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	b, err := New(
+		WithClock(c),
+		WithWindow(3*time.Second),
+		WithLatencySampling(1),
+		WithReadyToTrip(func(Counts) bool { return false }),
+	)
+	require.NoError(t, err)
+
+	call := func(latency time.Duration, success bool) {
+		done, err := b.Allow()
+		require.NoError(t, err)
+		c.advance(latency)
+		done(success)
+	}
+
+	call(time.Second/2, true)
+	call(0, false)
+	c.advance(time.Second)
+	call(time.Second, true)
+
+	sum := func(values []float64) float64 {
+		var s float64
+		for _, v := range values {
+			s += v
+		}
+		return s
+	}
+
+	var got []float64
+	b.ReduceFailures(func(values []float64) float64 {
+		got = append(got, values...)
+		return 0
+	})
+	require.Equal(t, []float64{1, 0, 0}, got)
+
+	require.Equal(t, 3.0, b.ReduceRequests(sum))
+	require.Equal(t, 2.0, b.ReduceSuccesses(sum))
+	require.Equal(t, 1.5, b.ReduceLatency(sum))
+}
@@ -0,0 +1,81 @@
+package circuitbreaker
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+var (
+	// ErrNoName is returned when registering a Breaker without a name.
+	ErrNoName = errors.New("circuit breaker has no name")
+	// ErrDuplicateName is returned when registering a Breaker with a name that is already registered.
+	ErrDuplicateName = errors.New("circuit breaker name is already registered")
+)
+
+// Registry holds Breakers by name.
+type Registry struct {
+	breakers map[string]*Breaker
+	lock     sync.RWMutex
+}
+
+// NewRegistry creates a Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		breakers: make(map[string]*Breaker),
+	}
+}
+
+// Register adds a Breaker to the Registry. The Breaker must have a unique name.
+func (r *Registry) Register(b *Breaker) error {
+	name := b.Name()
+	if name == "" {
+		return ErrNoName
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if _, ok := r.breakers[name]; ok {
+		return ErrDuplicateName
+	}
+
+	r.breakers[name] = b
+
+	return nil
+}
+
+// Unregister removes the named Breaker from the Registry.
+func (r *Registry) Unregister(name string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	delete(r.breakers, name)
+}
+
+// Get returns the named Breaker.
+func (r *Registry) Get(name string) (*Breaker, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	b, ok := r.breakers[name]
+
+	return b, ok
+}
+
+// Breakers returns all registered Breakers sorted by name.
+func (r *Registry) Breakers() []*Breaker {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	out := make([]*Breaker, 0, len(r.breakers))
+	for _, b := range r.breakers {
+		out = append(out, b)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Name() < out[j].Name()
+	})
+
+	return out
+}
@@ -0,0 +1,36 @@
+package circuitbreaker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+
+	noName, err := New()
+	require.NoError(t, err)
+	require.ErrorIs(t, r.Register(noName), ErrNoName)
+
+	a, err := New(WithName("a"))
+	require.NoError(t, err)
+
+	b, err := New(WithName("b"))
+	require.NoError(t, err)
+
+	require.NoError(t, r.Register(b))
+	require.NoError(t, r.Register(a))
+	require.ErrorIs(t, r.Register(a), ErrDuplicateName)
+
+	got, ok := r.Get("a")
+	require.True(t, ok)
+	require.Equal(t, a, got)
+
+	require.Equal(t, []*Breaker{a, b}, r.Breakers())
+
+	r.Unregister("a")
+
+	_, ok = r.Get("a")
+	require.False(t, ok)
+}
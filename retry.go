@@ -0,0 +1,148 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/bakins/circuitbreaker/internal/ring"
+)
+
+// RetryPolicy configures ExecuteWithRetry.
+type RetryPolicy struct {
+	// Budget limits retries across calls. Nil means retries are only limited by MaxAttempts.
+	Budget *RetryBudget
+	// Retryable reports whether a failed attempt should be retried.
+	// Nil means all errors are retried, other than requests canceled by the caller.
+	Retryable func(err error) bool
+	// Clock is used for backoff and the Budget. Nil means the Breaker's Clock with ExecuteWithRetry,
+	// and the time package otherwise.
+	Clock Clock
+	// MaxAttempts is the maximum number of attempts, including the first.
+	// Values less than one mean one attempt.
+	MaxAttempts int
+	// Backoff is the delay before the first retry. The delay doubles after each retry.
+	Backoff time.Duration
+	// MaxBackoff is the maximum delay between retries. Zero means no maximum.
+	MaxBackoff time.Duration
+}
+
+// RetryBudget limits retries to a fraction of the calls made in the last ten seconds, so that
+// retries do not multiply the load on a struggling dependency. A RetryBudget may be shared
+// by many policies and breakers.
+type RetryBudget struct {
+	counts     *ring.Ring
+	ratio      float64
+	minRetries float64
+}
+
+const (
+	retryBudgetCalls = iota
+	retryBudgetRetries
+	retryBudgetCounters
+)
+
+// NewRetryBudget creates a RetryBudget that allows retries up to ratio of the calls in the last
+// ten seconds, plus minRetries, which allows retries when there are few calls.
+func NewRetryBudget(ratio float64, minRetries int) *RetryBudget {
+	return &RetryBudget{
+		counts:     ring.New(10, retryBudgetCounters, 1, time.Second),
+		ratio:      ratio,
+		minRetries: float64(minRetries),
+	}
+}
+
+func (r *RetryBudget) call(now time.Time) {
+	r.counts.Add(now, retryBudgetCalls, 1)
+}
+
+// withdraw reports whether a retry at now is within the budget, and if so records it.
+func (r *RetryBudget) withdraw(now time.Time) bool {
+	var sums [retryBudgetCounters]uint64
+	r.counts.Sums(now, sums[:])
+
+	if float64(sums[retryBudgetRetries])+1 > float64(sums[retryBudgetCalls])*r.ratio+r.minRetries {
+		return false
+	}
+
+	r.counts.Add(now, retryBudgetRetries, 1)
+
+	return true
+}
+
+// ExecuteWithRetry runs fn using Execute, retrying failed attempts according to the policy.
+// The outcome of each attempt is recorded. Retries stop as soon as the Breaker opens or does not
+// allow an attempt, or the context is done. The error from the last attempt is returned.
+func (b *Breaker) ExecuteWithRetry(ctx context.Context, fn func(ctx context.Context) error, policy RetryPolicy) error {
+	attempt := func(ctx context.Context) error {
+		return b.Execute(ctx, fn)
+	}
+
+	clock := policy.Clock
+	if clock == nil {
+		clock = b.options.clock
+	}
+
+	return policy.retry(ctx, clock, attempt, func(err error) bool {
+		return IsRejected(err) || b.State() == StateOpen
+	})
+}
+
+// Execute implements Policy. It runs fn, retrying failed attempts according to the policy.
+// Retries stop when a Breaker run by fn does not allow an attempt, or the context is done.
+func (policy RetryPolicy) Execute(ctx context.Context, fn func(ctx context.Context) error) error {
+	clock := policy.Clock
+	if clock == nil {
+		clock = realClock{}
+	}
+
+	return policy.retry(ctx, clock, fn, IsRejected)
+}
+
+// retry runs attempt until it succeeds, the policy allows no more retries, or stop returns true.
+func (policy RetryPolicy) retry(ctx context.Context, clock Clock, attempt Job, stop func(err error) bool) error {
+	retryable := policy.Retryable
+	if retryable == nil {
+		retryable = func(err error) bool {
+			return !errors.Is(err, context.Canceled)
+		}
+	}
+
+	if policy.Budget != nil {
+		policy.Budget.call(clock.Now())
+	}
+
+	backoff := policy.Backoff
+
+	for n := 1; ; n++ {
+		err := attempt(ctx)
+		if err == nil {
+			return nil
+		}
+
+		if n >= policy.MaxAttempts || !retryable(err) || ctx.Err() != nil {
+			return err
+		}
+
+		if stop(err) {
+			return err
+		}
+
+		if policy.Budget != nil && !policy.Budget.withdraw(clock.Now()) {
+			return err
+		}
+
+		if backoff > 0 {
+			select {
+			case <-clock.After(backoff):
+			case <-ctx.Done():
+				return err
+			}
+
+			backoff *= 2
+			if policy.MaxBackoff > 0 && backoff > policy.MaxBackoff {
+				backoff = policy.MaxBackoff
+			}
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecuteWithRetry(t *testing.T) {
+	c := &testClock{
+		now: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	b, err := New(WithClock(c))
+	require.NoError(t, err)
+
+	fail := errors.New("fail")
+	attempts := 0
+
+	err = b.ExecuteWithRetry(context.Background(), func(context.Context) error {
+		attempts++
+		if attempts < 3 {
+			return fail
+		}
+
+		return nil
+	}, RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond})
+	require.NoError(t, err)
+	require.Equal(t, 3, attempts)
+
+	counts := b.counts()
+	require.Equal(t, uint64(1), counts.TotalSuccesses)
+	require.Equal(t, uint64(2), counts.TotalFailures)
+
+	attempts = 0
+
+	err = b.ExecuteWithRetry(context.Background(), func(context.Context) error {
+		attempts++
+		return fail
+	}, RetryPolicy{MaxAttempts: 2})
+	require.ErrorIs(t, err, fail)
+	require.Equal(t, 2, attempts)
+
+	attempts = 0
+
+	err = b.ExecuteWithRetry(context.Background(), func(context.Context) error {
+		attempts++
+		return fail
+	}, RetryPolicy{MaxAttempts: 5, Retryable: func(error) bool { return false }})
+	require.ErrorIs(t, err, fail)
+	require.Equal(t, 1, attempts)
+}
+
+func TestExecuteWithRetryOpen(t *testing.T) {
+	b, err := New(WithReadyToTrip(func(c Counts) bool { return c.ConsecutiveFailures >= 2 }))
+	require.NoError(t, err)
+
+	fail := errors.New("fail")
+	attempts := 0
+
+	err = b.ExecuteWithRetry(context.Background(), func(context.Context) error {
+		attempts++
+		return fail
+	}, RetryPolicy{MaxAttempts: 5})
+	require.ErrorIs(t, err, fail)
+	require.Equal(t, 2, attempts)
+	require.Equal(t, StateOpen, b.State())
+
+	err = b.ExecuteWithRetry(context.Background(), func(context.Context) error {
+		attempts++
+		return nil
+	}, RetryPolicy{MaxAttempts: 5})
+	require.ErrorIs(t, err, ErrOpenState)
+	require.Equal(t, 2, attempts)
+}
+
+func TestRetryBudget(t *testing.T) {
+	b, err := New(WithReadyToTrip(func(Counts) bool { return false }))
+	require.NoError(t, err)
+
+	budget := NewRetryBudget(0.5, 0)
+	policy := RetryPolicy{MaxAttempts: 3, Budget: budget}
+
+	fail := errors.New("fail")
+	attempts := 0
+
+	for i := 0; i < 4; i++ {
+		err = b.ExecuteWithRetry(context.Background(), func(context.Context) error {
+			attempts++
+			return fail
+		}, policy)
+		require.ErrorIs(t, err, fail)
+	}
+
+	// 4 calls allow 2 retries
+	require.Equal(t, 6, attempts)
+}
+
+func TestRetryBudgetClock(t *testing.T) {
+	c := &testClock{
+		now: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	b, err := New(WithClock(c), WithReadyToTrip(func(Counts) bool { return false }))
+	require.NoError(t, err)
+
+	policy := RetryPolicy{MaxAttempts: 3, Budget: NewRetryBudget(0, 1)}
+
+	fail := errors.New("fail")
+	attempts := 0
+
+	execute := func() {
+		err := b.ExecuteWithRetry(context.Background(), func(context.Context) error {
+			attempts++
+			return fail
+		}, policy)
+		require.ErrorIs(t, err, fail)
+	}
+
+	// the only retry is used by the first call
+	execute()
+	execute()
+	require.Equal(t, 3, attempts)
+
+	// the budget is measured with the Breaker's clock
+	c.advance(10 * time.Second)
+	execute()
+	require.Equal(t, 5, attempts)
+}
@@ -0,0 +1,179 @@
+package circuitbreaker
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Profile is a named set of thresholds.
+type Profile struct {
+	Name        string
+	ReadyToTrip ReadyToTrip
+}
+
+// ScheduleEntry selects a Profile during the minutes that match Spec, a cron expression with five fields:
+// minute, hour, day of month, month, and day of week (0 is Sunday). Each field is "*", a number, a range
+// such as "1-5", or a list of these separated by commas, optionally followed by a step such as "*/15".
+// A day matches if either day field matches, unless one of them is "*", as in cron.
+// For example, "* 0-5 * * *" matches every minute from midnight to six in the morning.
+type ScheduleEntry struct {
+	Spec    string
+	Profile Profile
+}
+
+// WithSchedule replaces the ReadyToTrip of the Breaker with the Profile of the first entry that matches
+// the time a request fails, in the location of the time from the Clock. When no entry matches,
+// the ReadyToTrip set by WithReadyToTrip is used. Conditions added with WithTripCondition always apply.
+// There is no default.
+func WithSchedule(entries ...ScheduleEntry) Option {
+	return func(o *Options) {
+		o.schedule = newSchedule(entries)
+	}
+}
+
+type schedule struct {
+	entries []scheduleEntry
+	err     error
+}
+
+type scheduleEntry struct {
+	// fields are bit sets of the values that match: minute, hour, day of month, month, day of week
+	fields  [5]uint64
+	anyDay  bool
+	profile Profile
+}
+
+var scheduleFields = [5]struct {
+	name     string
+	min, max int
+}{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 6},
+}
+
+func newSchedule(entries []ScheduleEntry) *schedule {
+	s := &schedule{}
+
+	for _, e := range entries {
+		parsed, err := parseScheduleEntry(e)
+		if err != nil {
+			s.err = err
+			return s
+		}
+
+		s.entries = append(s.entries, parsed)
+	}
+
+	return s
+}
+
+func parseScheduleEntry(e ScheduleEntry) (scheduleEntry, error) {
+	entry := scheduleEntry{profile: e.Profile}
+
+	if e.Profile.ReadyToTrip == nil {
+		return entry, fmt.Errorf("schedule profile %q has no ReadyToTrip", e.Profile.Name)
+	}
+
+	fields := strings.Fields(e.Spec)
+	if len(fields) != len(scheduleFields) {
+		return entry, fmt.Errorf("schedule %q must have %d fields", e.Spec, len(scheduleFields))
+	}
+
+	for i, field := range fields {
+		bits, err := parseScheduleField(field, scheduleFields[i].min, scheduleFields[i].max)
+		if err != nil {
+			return entry, fmt.Errorf("schedule %q: %s: %w", e.Spec, scheduleFields[i].name, err)
+		}
+
+		entry.fields[i] = bits
+	}
+
+	entry.anyDay = fields[2] == "*" || fields[4] == "*"
+
+	return entry, nil
+}
+
+func parseScheduleField(field string, min int, max int) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+
+		r, s, stepped := strings.Cut(part, "/")
+		if stepped {
+			n, err := strconv.Atoi(s)
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("invalid step %q", s)
+			}
+
+			part, step = r, n
+		}
+
+		lo, hi := min, max
+
+		if part != "*" {
+			l, h, isRange := strings.Cut(part, "-")
+
+			var err error
+			if lo, err = strconv.Atoi(l); err != nil {
+				return 0, fmt.Errorf("invalid value %q", l)
+			}
+
+			// as in cron, a single value with a step, such as 1/5, starts a range that runs to the maximum
+			hi = lo
+			if stepped && !isRange {
+				hi = max
+			}
+
+			if isRange {
+				if hi, err = strconv.Atoi(h); err != nil {
+					return 0, fmt.Errorf("invalid value %q", h)
+				}
+			}
+		}
+
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("%q is outside %d-%d", part, min, max)
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << v
+		}
+	}
+
+	return bits, nil
+}
+
+func (e *scheduleEntry) matches(t time.Time) bool {
+	has := func(field int, v int) bool {
+		return e.fields[field]&(1<<v) != 0
+	}
+
+	if !has(0, t.Minute()) || !has(1, t.Hour()) || !has(3, int(t.Month())) {
+		return false
+	}
+
+	dom, dow := has(2, t.Day()), has(4, int(t.Weekday()))
+	if e.anyDay {
+		return dom && dow
+	}
+
+	return dom || dow
+}
+
+// conditions returns the trip conditions at t, with the ReadyToTrip of the matching Profile first.
+func (s *schedule) conditions(t time.Time, conditions []tripCondition) []tripCondition {
+	for i := range s.entries {
+		if e := &s.entries[i]; e.matches(t) {
+			c := tripCondition{name: e.profile.Name, readyToTrip: e.profile.ReadyToTrip}
+			return append([]tripCondition{c}, conditions[1:]...)
+		}
+	}
+
+	return conditions
+}
@@ -0,0 +1,98 @@
+package circuitbreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduleMatches(t *testing.T) {
+	// Saturday, January 2nd
+	at := time.Date(2021, 1, 2, 3, 15, 0, 0, time.UTC)
+
+	tests := map[string]bool{
+		"* * * * *":          true,
+		"15 3 * * *":         true,
+		"*/15 0-5 * * *":     true,
+		"*/20 * * * *":       false,
+		"* 4,5 * * *":        false,
+		"* * 2 1 *":          true,
+		"* * * 2 *":          false,
+		"* * * * 6":          true,
+		"* * * * 1-5":        false,
+		"* * 1 * 6":          true,
+		"* * 1 * 1":          false,
+		"0-30/5 1-3 * * 0,6": true,
+		"5/10 * * * *":       true,
+		"1/5 * * * *":        false,
+	}
+
+	for spec, want := range tests {
+		t.Run(spec, func(t *testing.T) {
+			e, err := parseScheduleEntry(ScheduleEntry{Spec: spec, Profile: Profile{ReadyToTrip: DefaultReadyToTrip}})
+			require.NoError(t, err)
+			require.Equal(t, want, e.matches(at))
+		})
+	}
+}
+
+func TestScheduleFieldStep(t *testing.T) {
+	bits, err := parseScheduleField("1/5", 0, 59)
+	require.NoError(t, err)
+
+	var want uint64
+	for v := 1; v <= 59; v += 5 {
+		want |= 1 << v
+	}
+
+	require.Equal(t, want, bits)
+}
+
+func TestScheduleInvalid(t *testing.T) {
+	for _, spec := range []string{"* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		_, err := New(WithSchedule(ScheduleEntry{Spec: spec, Profile: Profile{ReadyToTrip: DefaultReadyToTrip}}))
+		require.ErrorIs(t, err, ErrInvalidOption, spec)
+	}
+
+	_, err := New(WithSchedule(ScheduleEntry{Spec: "* * * * *"}))
+	require.ErrorIs(t, err, ErrInvalidOption)
+}
+
+func TestSchedule(t *testing.T) {
+	c := &testClock{
+		now: time.Date(2021, 1, 2, 12, 0, 0, 0, time.UTC),
+	}
+
+	b, err := New(
+		WithClock(c),
+		WithWindow(time.Minute),
+		WithReadyToTrip(func(c Counts) bool { return c.ConsecutiveFailures >= 1 }),
+		WithSchedule(ScheduleEntry{
+			Spec:    "* 0-5 * * *",
+			Profile: Profile{Name: "nightly", ReadyToTrip: func(c Counts) bool { return c.ConsecutiveFailures >= 3 }},
+		}),
+	)
+	require.NoError(t, err)
+
+	fail := func() {
+		done, err := b.Allow()
+		require.NoError(t, err)
+		done(false)
+	}
+
+	fail()
+	require.Equal(t, StateOpen, b.State())
+	require.Equal(t, "readyToTrip", b.History()[0].Condition)
+
+	b.Reset()
+	c.now = time.Date(2021, 1, 3, 2, 0, 0, 0, time.UTC)
+
+	fail()
+	fail()
+	require.Equal(t, StateClosed, b.State())
+
+	fail()
+	require.Equal(t, StateOpen, b.State())
+	require.Equal(t, "nightly", b.History()[len(b.History())-1].Condition)
+}
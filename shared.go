@@ -0,0 +1,32 @@
+package circuitbreaker
+
+import "time"
+
+// SharedCounts aggregates the counts of Breakers with the same name in different processes,
+// so that replicas of a service trip together rather than each spending its own failure budget.
+// Combine it with a Store that publishes transitions, see WithStore, so that replicas also open together.
+//
+// The methods of SharedCounts are called for every request, Counts while the Breaker is locked,
+// so they must not block on the network. Counts should return totals synced in the background.
+type SharedCounts interface {
+	// Add adds delta to the counts of the named Breaker.
+	Add(name string, delta Counts)
+	// Counts returns the requests, successes, and failures of the named Breaker across all processes
+	// within the window. Consecutive counts are not shared.
+	Counts(name string, window time.Duration) Counts
+}
+
+// WithSharedCounts uses the total counts from s, rather than the counts of this process, to decide
+// whether to trip. The Breaker must have a name, see WithName.
+// There is no default.
+func WithSharedCounts(s SharedCounts) Option {
+	return func(o *Options) {
+		o.sharedCounts = s
+	}
+}
+
+func (b *Breaker) addShared(delta Counts) {
+	if b.options.sharedCounts != nil {
+		b.options.sharedCounts.Add(b.options.name, delta)
+	}
+}
@@ -0,0 +1,59 @@
+package circuitbreaker
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fixedCounts adds counts from other processes to the local counts.
+type fixedCounts struct {
+	remote Counts
+	local  Counts
+	lock   sync.Mutex
+}
+
+func (f *fixedCounts) Add(_ string, delta Counts) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.local.Requests += delta.Requests
+	f.local.TotalSuccesses += delta.TotalSuccesses
+	f.local.TotalFailures += delta.TotalFailures
+}
+
+func (f *fixedCounts) Counts(string, time.Duration) Counts {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	return Counts{
+		Requests:       f.remote.Requests + f.local.Requests,
+		TotalSuccesses: f.remote.TotalSuccesses + f.local.TotalSuccesses,
+		TotalFailures:  f.remote.TotalFailures + f.local.TotalFailures,
+	}
+}
+
+func TestSharedCounts(t *testing.T) {
+	shared := &fixedCounts{remote: Counts{Requests: 9, TotalFailures: 9}}
+
+	_, err := New(WithSharedCounts(shared))
+	require.ErrorIs(t, err, ErrNoName)
+
+	b, err := New(
+		WithName("test"),
+		WithSharedCounts(shared),
+		WithReadyToTrip(func(c Counts) bool { return c.TotalFailures >= 10 }),
+	)
+	require.NoError(t, err)
+
+	record(t, b, false)
+
+	require.Equal(t, StateOpen, b.State())
+
+	counts := b.Status().Counts
+	require.Equal(t, uint64(10), counts.Requests)
+	require.Equal(t, uint64(10), counts.TotalFailures)
+	require.Equal(t, uint64(1), counts.ConsecutiveFailures)
+}
@@ -0,0 +1,111 @@
+package circuitbreaker
+
+import (
+	"time"
+)
+
+// SignalAction is the action an external Signal commands.
+type SignalAction int
+
+// Signal actions
+const (
+	// SignalNone only adjusts the thresholds of the Breaker.
+	SignalNone SignalAction = iota
+	// SignalTrip calls Trip.
+	SignalTrip
+	// SignalForceOpen calls ForceOpen.
+	SignalForceOpen
+	// SignalForceClose calls ForceClose.
+	SignalForceClose
+	// SignalReset calls Reset.
+	SignalReset
+)
+
+// String returns a string representation of the action.
+func (a SignalAction) String() string {
+	switch a {
+	case SignalNone:
+		return "none"
+	case SignalTrip:
+		return "trip"
+	case SignalForceOpen:
+		return "forceOpen"
+	case SignalForceClose:
+		return "forceClose"
+	case SignalReset:
+		return "reset"
+	default:
+		return "unknown"
+	}
+}
+
+// Signal is a command from an outside system, such as a health checker or control plane.
+// Thresholds are adjusted before the action is applied. Zero values leave a threshold unchanged.
+type Signal struct {
+	// ReadyToTrip replaces the function set by WithReadyToTrip.
+	ReadyToTrip ReadyToTrip
+	// Timeout replaces the timeout, starting with the next transition. Must be at least one second.
+	Timeout time.Duration
+	// MaxRequests replaces the maximum number of requests allowed when half-open.
+	MaxRequests uint64
+	Action      SignalAction
+}
+
+// WithExternalSignal applies the signals received on ch to the Breaker.
+// The Breaker stops receiving when ch is closed.
+// There is no default.
+func WithExternalSignal(ch <-chan Signal) Option {
+	return func(o *Options) {
+		o.signals = ch
+	}
+}
+
+func (b *Breaker) receiveSignals(ch <-chan Signal) {
+	for s := range ch {
+		b.applySignal(s)
+	}
+}
+
+func (b *Breaker) applySignal(s Signal) {
+	b.adjust(s)
+
+	switch s.Action {
+	case SignalTrip:
+		b.Trip()
+	case SignalForceOpen:
+		b.ForceOpen()
+	case SignalForceClose:
+		b.ForceClose()
+	case SignalReset:
+		b.Reset()
+	}
+}
+
+func (b *Breaker) adjust(s Signal) {
+	var options []Option
+
+	if s.ReadyToTrip != nil {
+		options = append(options, WithReadyToTrip(s.ReadyToTrip))
+	}
+
+	if s.Timeout > 0 {
+		options = append(options, WithTimeout(s.Timeout))
+	}
+
+	if s.MaxRequests > 0 {
+		options = append(options, WithMaxRequests(s.MaxRequests))
+	}
+
+	if len(options) > 0 {
+		// only updatable options are used
+		_ = b.UpdateOptions(options...)
+	}
+}
+
+// thresholds returns the trip conditions and the maximum number of half-open requests, which may be adjusted by signals.
+func (b *Breaker) thresholds() ([]tripCondition, uint64) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.options.conditions, b.options.maxRequests
+}
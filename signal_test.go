@@ -0,0 +1,74 @@
+package circuitbreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExternalSignal(t *testing.T) {
+	signals := make(chan Signal)
+	defer close(signals)
+
+	b, err := New(WithExternalSignal(signals))
+	require.NoError(t, err)
+
+	signals <- Signal{Action: SignalForceOpen}
+
+	require.Eventually(t, func() bool {
+		return b.State() == StateOpen
+	}, time.Second, time.Millisecond)
+	require.True(t, b.Status().Forced)
+
+	signals <- Signal{Action: SignalReset}
+
+	require.Eventually(t, func() bool {
+		return b.State() == StateClosed
+	}, time.Second, time.Millisecond)
+	require.False(t, b.Status().Forced)
+}
+
+func TestExternalSignalThresholds(t *testing.T) {
+	b, err := New(WithMaxRequests(3))
+	require.NoError(t, err)
+
+	record(t, b, false)
+	require.Equal(t, StateClosed, b.State())
+
+	b.applySignal(Signal{
+		ReadyToTrip: func(Counts) bool { return true },
+		Timeout:     time.Minute,
+		MaxRequests: 5,
+	})
+
+	status := b.Status()
+	require.Equal(t, time.Minute, status.Options.Timeout)
+	require.Equal(t, uint64(5), status.Options.MaxRequests)
+
+	record(t, b, false)
+	require.Equal(t, StateOpen, b.State())
+	require.Equal(t, time.Minute, b.Status().RetryAfter.Round(time.Minute))
+}
+
+func TestExternalSignalMaxRequests(t *testing.T) {
+	c := &testClock{
+		now: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	b, err := New(WithClock(c), WithTimeout(time.Minute), WithMaxRequests(1))
+	require.NoError(t, err)
+
+	b.Trip()
+	c.advance(2 * time.Minute)
+	require.Equal(t, StateHalfOpen, b.State())
+
+	b.applySignal(Signal{MaxRequests: 2})
+
+	// both admission and closing use the adjusted threshold
+	record(t, b, true)
+	require.Equal(t, StateHalfOpen, b.State())
+
+	record(t, b, true)
+	require.Equal(t, StateClosed, b.State())
+}
@@ -0,0 +1,231 @@
+// Package simulate replays a sequence of requests through a circuit breaker, to tune its options
+// against synthetic or recorded traffic before deploying them.
+package simulate
+
+import (
+	"container/heap"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"sort"
+	"time"
+
+	"github.com/bakins/circuitbreaker"
+	"github.com/bakins/circuitbreaker/breakertest"
+)
+
+// ErrInvalidTrace is returned when a trace cannot be parsed.
+var ErrInvalidTrace = errors.New("invalid trace")
+
+// Event is a request in a trace.
+type Event struct {
+	// Time is when the request started.
+	Time time.Time
+	// Latency is the duration of the request. Its outcome is recorded at Time plus Latency.
+	Latency time.Duration
+	Outcome circuitbreaker.Outcome
+}
+
+// Result describes what the Breaker would have done.
+type Result struct {
+	// TimeInState is the total time spent in each state, from the first event to the last outcome.
+	TimeInState map[circuitbreaker.State]time.Duration
+	// Transitions are the state changes of the Breaker.
+	Transitions []circuitbreaker.Transition
+	// Allowed is the number of requests the Breaker allowed.
+	Allowed int
+	// Rejected is the number of requests the Breaker rejected.
+	Rejected int
+	// RejectedFailures is the number of rejected requests that would have failed.
+	RejectedFailures int
+	// RejectedSuccesses is the number of rejected requests that would have succeeded.
+	RejectedSuccesses int
+}
+
+// Run replays the events through a Breaker created with options, using a simulated clock,
+// and returns what the Breaker would have done. The events do not need to be sorted.
+func Run(events []Event, options ...circuitbreaker.Option) (Result, error) {
+	events = append([]Event(nil), events...)
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time.Before(events[j].Time)
+	})
+
+	var start time.Time
+	if len(events) > 0 {
+		start = events[0].Time
+	}
+
+	clock := breakertest.NewClock(start)
+
+	// transitions are recorded as they happen, rather than in the background
+	b, err := circuitbreaker.New(append(options, circuitbreaker.WithClock(clock), circuitbreaker.WithSynchronousObservers())...)
+	if err != nil {
+		return Result{}, err
+	}
+
+	result := Result{}
+
+	unsubscribe := b.Subscribe(func(t circuitbreaker.Transition) {
+		result.Transitions = append(result.Transitions, t)
+	})
+	defer unsubscribe()
+
+	var pending completions
+
+	complete := func(until time.Time) {
+		for len(pending) > 0 && !pending[0].time.After(until) {
+			c := heap.Pop(&pending).(completion)
+			clock.Set(c.time)
+			c.done(c.outcome)
+		}
+	}
+
+	for _, e := range events {
+		complete(e.Time)
+		clock.Set(e.Time)
+
+		done, err := b.AllowOutcome()
+		if err != nil {
+			result.Rejected++
+
+			switch e.Outcome {
+			case circuitbreaker.OutcomeFailure:
+				result.RejectedFailures++
+			case circuitbreaker.OutcomeSuccess:
+				result.RejectedSuccesses++
+			}
+
+			continue
+		}
+
+		result.Allowed++
+
+		heap.Push(&pending, completion{time: e.Time.Add(e.Latency), outcome: e.Outcome, done: done})
+	}
+
+	for len(pending) > 0 {
+		complete(pending[0].time)
+	}
+
+	result.TimeInState = b.Stats().TimeInState
+
+	return result, nil
+}
+
+type completion struct {
+	time    time.Time
+	done    func(circuitbreaker.Outcome)
+	outcome circuitbreaker.Outcome
+}
+
+// completions is a min-heap of completions by time.
+type completions []completion
+
+func (c completions) Len() int           { return len(c) }
+func (c completions) Less(i, j int) bool { return c[i].time.Before(c[j].time) }
+func (c completions) Swap(i, j int)      { c[i], c[j] = c[j], c[i] }
+func (c *completions) Push(x any)        { *c = append(*c, x.(completion)) }
+
+func (c *completions) Pop() any {
+	old := *c
+	n := len(old)
+	x := old[n-1]
+	*c = old[:n-1]
+
+	return x
+}
+
+// ReadCSV reads a recorded trace. Each record has the start time in RFC 3339 format,
+// the outcome ("success", "failure", or "ignored"), and optionally the latency as a duration, such as "150ms".
+// Lines starting with # are ignored.
+func ReadCSV(r io.Reader) ([]Event, error) {
+	cr := csv.NewReader(r)
+	cr.Comment = '#'
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	var events []Event
+
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return events, nil
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidTrace, err)
+		}
+
+		line, _ := cr.FieldPos(0)
+
+		e, err := parseRecord(record)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrInvalidTrace, line, err)
+		}
+
+		events = append(events, e)
+	}
+}
+
+func parseRecord(record []string) (Event, error) {
+	if len(record) < 2 || len(record) > 3 {
+		return Event{}, fmt.Errorf("expected 2 or 3 fields, got %d", len(record))
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, record[0])
+	if err != nil {
+		return Event{}, err
+	}
+
+	e := Event{Time: t}
+
+	switch record[1] {
+	case "success":
+		e.Outcome = circuitbreaker.OutcomeSuccess
+	case "failure":
+		e.Outcome = circuitbreaker.OutcomeFailure
+	case "ignored":
+		e.Outcome = circuitbreaker.OutcomeIgnored
+	default:
+		return Event{}, fmt.Errorf("unknown outcome %q", record[1])
+	}
+
+	if len(record) == 3 && record[2] != "" {
+		e.Latency, err = time.ParseDuration(record[2])
+		if err != nil {
+			return Event{}, err
+		}
+	}
+
+	return e, nil
+}
+
+// Generate returns a synthetic trace with a request every interval from start for duration d.
+// Each request fails with the probability returned by failureRate for its start time, and takes latency.
+// The seed makes the trace reproducible.
+func Generate(start time.Time, d time.Duration, interval time.Duration, latency time.Duration,
+	failureRate func(time.Time) float64, seed uint64,
+) []Event {
+	var events []Event
+
+	if interval <= 0 {
+		return events
+	}
+
+	r := rand.New(rand.NewPCG(seed, seed))
+
+	for t := start; t.Before(start.Add(d)); t = t.Add(interval) {
+		e := Event{Time: t, Latency: latency, Outcome: circuitbreaker.OutcomeSuccess}
+
+		if r.Float64() < failureRate(t) {
+			e.Outcome = circuitbreaker.OutcomeFailure
+		}
+
+		events = append(events, e)
+	}
+
+	return events
+}
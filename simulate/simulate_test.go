@@ -0,0 +1,91 @@
+package simulate
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+func TestRun(t *testing.T) {
+	start := time.Date(2021, 1, 2, 3, 0, 0, 0, time.UTC)
+
+	// the dependency is down from the third to the fifth minute
+	events := Generate(start, 10*time.Minute, time.Second, 50*time.Millisecond, func(t time.Time) float64 {
+		if t.Sub(start) >= 3*time.Minute && t.Sub(start) < 5*time.Minute {
+			return 1
+		}
+
+		return 0
+	}, 1)
+
+	result, err := Run(events,
+		circuitbreaker.WithReadyToTrip(circuitbreaker.TripOnConsecutiveFailures(5)),
+		circuitbreaker.WithWindow(10*time.Second),
+		circuitbreaker.WithTimeout(30*time.Second),
+	)
+	require.NoError(t, err)
+
+	require.NotEmpty(t, result.Transitions)
+
+	first := result.Transitions[0]
+	require.Equal(t, circuitbreaker.StateOpen, first.To)
+	require.Equal(t, start.Add(3*time.Minute+4*time.Second+50*time.Millisecond), first.Time)
+
+	last := result.Transitions[len(result.Transitions)-1]
+	require.Equal(t, circuitbreaker.StateClosed, last.To)
+	require.True(t, last.Time.After(start.Add(5*time.Minute)))
+	require.True(t, last.Time.Before(start.Add(6*time.Minute)))
+
+	require.Equal(t, len(events), result.Allowed+result.Rejected)
+	require.Greater(t, result.RejectedFailures, 100)
+	require.Less(t, result.RejectedSuccesses, 30)
+	require.Greater(t, result.TimeInState[circuitbreaker.StateOpen], time.Minute)
+}
+
+func TestRunLatency(t *testing.T) {
+	start := time.Date(2021, 1, 2, 3, 0, 0, 0, time.UTC)
+
+	// the failure is recorded after the later requests start
+	events := []Event{
+		{Time: start, Latency: 10 * time.Second, Outcome: circuitbreaker.OutcomeFailure},
+		{Time: start.Add(time.Second), Outcome: circuitbreaker.OutcomeSuccess},
+		{Time: start.Add(10*time.Second + 500*time.Millisecond), Outcome: circuitbreaker.OutcomeSuccess},
+	}
+
+	result, err := Run(events, circuitbreaker.WithReadyToTrip(circuitbreaker.TripOnConsecutiveFailures(1)))
+	require.NoError(t, err)
+
+	require.Len(t, result.Transitions, 1)
+	require.Equal(t, start.Add(10*time.Second), result.Transitions[0].Time)
+	require.Equal(t, 2, result.Allowed)
+	require.Equal(t, 1, result.Rejected)
+	require.Equal(t, 1, result.RejectedSuccesses)
+}
+
+func TestReadCSV(t *testing.T) {
+	events, err := ReadCSV(strings.NewReader(`# time,outcome,latency
+2021-01-02T03:04:05Z,success,150ms
+2021-01-02T03:04:06.5Z,failure
+`))
+	require.NoError(t, err)
+
+	require.Equal(t, []Event{
+		{
+			Time:    time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+			Latency: 150 * time.Millisecond,
+			Outcome: circuitbreaker.OutcomeSuccess,
+		},
+		{
+			Time:    time.Date(2021, 1, 2, 3, 4, 6, 500000000, time.UTC),
+			Outcome: circuitbreaker.OutcomeFailure,
+		},
+	}, events)
+
+	_, err = ReadCSV(strings.NewReader("2021-01-02T03:04:05Z,maybe\n"))
+	require.ErrorIs(t, err, ErrInvalidTrace)
+	require.ErrorContains(t, err, "line 1")
+}
@@ -0,0 +1,99 @@
+package circuitbreaker
+
+import (
+	"math"
+	"time"
+
+	"github.com/bakins/circuitbreaker/internal/ring"
+)
+
+// errorBudgetBuckets is the number of buckets an ErrorBudget's period is divided into.
+const errorBudgetBuckets = 120
+
+const (
+	budgetRequests = iota
+	budgetFailures
+	budgetCounters
+)
+
+// ErrorBudget tracks the failures allowed by a service level objective, such as 99.9% of requests succeeding
+// over 30 days. Requests are counted in coarse buckets, one for each 1/120th of the period.
+// An ErrorBudget may be shared by many breakers, see WithErrorBudget. It measures time with the Clock
+// of the first of them, see WithClock, or with the time package until it is added to a Breaker.
+type ErrorBudget struct {
+	clock     detectorClock
+	counts    *ring.Ring
+	objective float64
+}
+
+// NewErrorBudget creates an ErrorBudget for objective, the fraction of requests that should succeed over period.
+func NewErrorBudget(objective float64, period time.Duration) *ErrorBudget {
+	return &ErrorBudget{
+		counts:    ring.New(errorBudgetBuckets, budgetCounters, 1, max(period/errorBudgetBuckets, 1)),
+		objective: objective,
+	}
+}
+
+// WithErrorBudget records the outcome of each request in the ErrorBudget, so trip conditions created by
+// ErrorBudget.ReadyToTrip tighten as the budget is consumed.
+// There is no default.
+func WithErrorBudget(budget *ErrorBudget) Option {
+	return func(o *Options) {
+		o.errorBudget = budget
+	}
+}
+
+func (e *ErrorBudget) add(start time.Time, success bool) {
+	e.counts.Add(start, budgetRequests, 1)
+
+	if !success {
+		e.counts.Add(start, budgetFailures, 1)
+	}
+}
+
+func (e *ErrorBudget) addCounts(start time.Time, requests uint64, failures uint64) {
+	e.counts.Add(start, budgetRequests, requests)
+
+	if failures > 0 {
+		e.counts.Add(start, budgetFailures, failures)
+	}
+}
+
+// Remaining returns the fraction of the error budget that remains in the period, from 1 when there have been
+// no failures to 0 when the failures allowed by the objective have been used. It is 1 when there are no requests.
+func (e *ErrorBudget) Remaining() float64 {
+	var sums [budgetCounters]uint64
+	e.counts.Sums(e.clock.Now(), sums[:])
+
+	if sums[budgetRequests] == 0 {
+		return 1
+	}
+
+	allowed := (1 - e.objective) * float64(sums[budgetRequests])
+	if allowed <= 0 {
+		if sums[budgetFailures] > 0 {
+			return 0
+		}
+
+		return 1
+	}
+
+	return math.Max(0, 1-float64(sums[budgetFailures])/allowed)
+}
+
+// ReadyToTrip returns a ReadyToTrip that returns true when there are at least minimumRequests requests and
+// the ratio of failures to requests is at least a threshold that tightens as the budget is consumed:
+// it is maxFailureRate while the whole budget remains, and falls to the failure rate allowed by the objective
+// once the budget is used.
+func (e *ErrorBudget) ReadyToTrip(maxFailureRate float64, minimumRequests uint64) ReadyToTrip {
+	return func(counts Counts) bool {
+		if counts.Requests == 0 || counts.Requests < minimumRequests {
+			return false
+		}
+
+		allowed := 1 - e.objective
+		rate := allowed + (maxFailureRate-allowed)*e.Remaining()
+
+		return float64(counts.TotalFailures)/float64(counts.Requests) >= rate
+	}
+}
@@ -0,0 +1,65 @@
+package circuitbreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorBudget(t *testing.T) {
+	c := &testClock{
+		now: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	e := NewErrorBudget(0.99, 30*24*time.Hour)
+	e.clock.set(c)
+
+	require.Equal(t, 1.0, e.Remaining())
+
+	for i := 0; i < 1000; i++ {
+		e.add(c.now, i%200 != 0)
+	}
+
+	// 5 of the 10 failures allowed
+	require.InDelta(t, 0.5, e.Remaining(), 1e-9)
+
+	trip := e.ReadyToTrip(0.5, 10)
+	require.False(t, trip(Counts{Requests: 5, TotalFailures: 5}))
+	require.False(t, trip(Counts{Requests: 10, TotalFailures: 2}))
+	// the threshold is halfway between 50% and 1%
+	require.True(t, trip(Counts{Requests: 100, TotalFailures: 26}))
+
+	for i := 0; i < 10; i++ {
+		e.add(c.now, false)
+	}
+
+	require.Zero(t, e.Remaining())
+	require.True(t, trip(Counts{Requests: 100, TotalFailures: 2}))
+
+	// the failures leave the budget with the period
+	c.advance(31 * 24 * time.Hour)
+	require.Equal(t, 1.0, e.Remaining())
+}
+
+func TestWithErrorBudget(t *testing.T) {
+	c := &testClock{
+		now: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	e := NewErrorBudget(0.9, time.Hour)
+
+	// the ErrorBudget measures time with the Clock of the Breaker
+	b, err := New(WithClock(c), WithWindow(time.Minute), WithErrorBudget(e), WithReadyToTrip(e.ReadyToTrip(1, 1)))
+	require.NoError(t, err)
+
+	for _, success := range []bool{true, true, true, true, false} {
+		done, err := b.Allow()
+		require.NoError(t, err)
+		done(success)
+	}
+
+	// the failure uses more than the half a failure allowed for five requests
+	require.Zero(t, e.Remaining())
+	require.Equal(t, StateOpen, b.State())
+}
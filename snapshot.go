@@ -0,0 +1,295 @@
+package circuitbreaker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrSnapshotVersion is returned by Restore when a snapshot was created by an incompatible version.
+var ErrSnapshotVersion = errors.New("unsupported circuit breaker snapshot version")
+
+const snapshotVersion = 1
+
+type snapshot struct {
+	Time            time.Time           `json:"time"`
+	LastStateChange time.Time           `json:"lastStateChange"`
+	LastOpen        *snapshotTransition `json:"lastOpen,omitempty"`
+	Counts          jsonCounts          `json:"counts"`
+	Version         int                 `json:"version"`
+	OpenTimeout     time.Duration       `json:"openTimeout"`
+	State           State               `json:"state"`
+	Forced          bool                `json:"forced"`
+}
+
+type snapshotTransition struct {
+	Time      time.Time  `json:"time"`
+	Condition string     `json:"condition,omitempty"`
+	Counts    jsonCounts `json:"counts"`
+	From      State      `json:"from"`
+	To        State      `json:"to"`
+	Reason    Reason     `json:"reason"`
+}
+
+// Snapshot returns the state and counts of the Breaker, encoded as JSON, so that they
+// may be restored by Restore, such as after a restart.
+func (b *Breaker) Snapshot() ([]byte, error) {
+	state, lastOpen := b.state()
+
+	b.lock.Lock()
+
+	s := snapshot{
+		Version:         snapshotVersion,
+		Time:            b.options.clock.Now(),
+		State:           state,
+		Forced:          b.forced,
+		LastStateChange: b.lastStateChange,
+		OpenTimeout:     b.openTimeout,
+		Counts:          jsonCounts(b.counts()),
+	}
+
+	b.lock.Unlock()
+
+	if !lastOpen.Time.IsZero() {
+		s.LastOpen = &snapshotTransition{
+			Time:      lastOpen.Time,
+			From:      lastOpen.From,
+			To:        lastOpen.To,
+			Reason:    lastOpen.Reason,
+			Condition: lastOpen.Condition,
+			Counts:    jsonCounts(lastOpen.Counts),
+		}
+	}
+
+	return json.Marshal(s)
+}
+
+// Restore restores the state and counts of the Breaker from a snapshot created by Snapshot.
+// An open Breaker stays open for the remainder of its timeout. Counts are only restored if the
+// snapshot is newer than the window.
+func (b *Breaker) Restore(data []byte) error {
+	var s snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("failed to decode circuit breaker snapshot: %w", err)
+	}
+
+	if s.Version != snapshotVersion {
+		return fmt.Errorf("%w: %d", ErrSnapshotVersion, s.Version)
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.discardSuccesses()
+	b.buckets.Reset()
+
+	if now := b.options.clock.Now(); now.Sub(s.Time) < b.options.window {
+		b.buckets.Add(now, countRequests, s.Counts.Requests)
+		b.buckets.Add(now, countSuccesses, s.Counts.TotalSuccesses)
+		b.buckets.Add(now, countFailures, s.Counts.TotalFailures)
+	}
+
+	atomic.StoreUint64(&b.consecutiveSuccesses, s.Counts.ConsecutiveSuccesses)
+	atomic.StoreUint64(&b.consecutiveFailures, s.Counts.ConsecutiveFailures)
+
+	b.switchState(b.loadState(), s.State, ReasonRestore, "")
+
+	if s.LastOpen != nil {
+		b.lastOpen = Transition{
+			Time:      s.LastOpen.Time,
+			From:      s.LastOpen.From,
+			To:        s.LastOpen.To,
+			Reason:    s.LastOpen.Reason,
+			Condition: s.LastOpen.Condition,
+			Counts:    Counts(s.LastOpen.Counts),
+		}
+	}
+
+	b.forced = s.Forced
+	b.lastStateChange = s.LastStateChange
+	// the snapshot may be from another process, so only the wall clock can measure how long ago the state changed
+	b.changedAt = b.monotonic() - b.options.clock.Now().Sub(s.LastStateChange)
+	b.openTimeout = s.OpenTimeout
+	b.updateOpenUntil()
+
+	return nil
+}
+
+// Store persists Breaker snapshots, see WithStore.
+type Store interface {
+	// Load returns the snapshot saved for the named Breaker, or nil if there is none.
+	Load(ctx context.Context, name string) ([]byte, error)
+	// Save saves the snapshot for the named Breaker.
+	Save(ctx context.Context, name string, data []byte) error
+	// Watch returns a channel that receives snapshots saved for the named Breaker, including by
+	// other processes, until ctx is done.
+	Watch(ctx context.Context, name string) (<-chan []byte, error)
+}
+
+// WithStore persists the Breaker's snapshot to the store whenever its state changes and at the interval,
+// and restores it in New, so that a service that restarts during an outage does not forget that the Breaker was open.
+// Snapshots saved by other processes that share the store change the Breaker's state when their state differs.
+// The Breaker must have a name, see WithName. Errors loading or saving snapshots are logged, see WithLogger.
+// Call Close to stop persisting the Breaker.
+// There is no default store. The default interval is 10 seconds.
+func WithStore(s Store, interval time.Duration) Option {
+	return func(o *Options) {
+		o.store = s
+		o.storeInterval = interval
+	}
+}
+
+// Close stops persisting the Breaker, saving it a final time. It returns the error from saving, if any.
+// Close does nothing if the Breaker does not have a store.
+func (b *Breaker) Close() error {
+	if b.persistence == nil {
+		return nil
+	}
+
+	b.persistence.once.Do(func() {
+		close(b.persistence.done)
+		<-b.persistence.stopped
+	})
+
+	return b.persistence.err
+}
+
+type persistence struct {
+	notify  chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+	updates <-chan []byte
+	cancel  context.CancelFunc
+	err     error
+	saved   []byte
+	once    sync.Once
+}
+
+func (b *Breaker) startPersistence() error {
+	if b.options.name == "" {
+		return ErrNoName
+	}
+
+	data, err := b.options.store.Load(context.Background(), b.options.name)
+
+	switch {
+	case err != nil:
+		b.logStoreError("failed to load circuit breaker snapshot", err)
+	case data != nil:
+		if err := b.Restore(data); err != nil {
+			b.logStoreError("failed to restore circuit breaker snapshot", err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	updates, err := b.options.store.Watch(ctx, b.options.name)
+	if err != nil {
+		b.logStoreError("failed to watch circuit breaker snapshots", err)
+	}
+
+	p := &persistence{
+		notify:  make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+		updates: updates,
+		cancel:  cancel,
+	}
+
+	b.persistence = p
+
+	b.Subscribe(func(Transition) {
+		select {
+		case p.notify <- struct{}{}:
+		default:
+		}
+	})
+
+	go b.persist(p)
+
+	return nil
+}
+
+func (b *Breaker) persist(p *persistence) {
+	defer close(p.stopped)
+
+	defer p.cancel()
+
+	tick := b.options.clock.After(b.options.storeInterval)
+
+	for {
+		select {
+		case <-p.notify:
+		case <-tick:
+			tick = b.options.clock.After(b.options.storeInterval)
+		case data, ok := <-p.updates:
+			if !ok {
+				p.updates = nil
+			} else {
+				b.applyUpdate(p, data)
+			}
+
+			continue
+		case <-p.done:
+			p.err = b.save(p)
+			return
+		}
+
+		if err := b.save(p); err != nil {
+			b.logStoreError("failed to save circuit breaker snapshot", err)
+		}
+	}
+}
+
+func (b *Breaker) save(p *persistence) error {
+	data, err := b.Snapshot()
+	if err != nil {
+		return err
+	}
+
+	p.saved = data
+
+	return b.options.store.Save(context.Background(), b.options.name, data)
+}
+
+// applyUpdate restores a snapshot saved by another process if its state differs.
+func (b *Breaker) applyUpdate(p *persistence, data []byte) {
+	if bytes.Equal(data, p.saved) {
+		return
+	}
+
+	var s snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		b.logStoreError("failed to decode circuit breaker snapshot", err)
+		return
+	}
+
+	b.lock.Lock()
+	same := s.State == b.loadState() && s.Forced == b.forced
+	b.lock.Unlock()
+
+	if same {
+		return
+	}
+
+	if err := b.Restore(data); err != nil {
+		b.logStoreError("failed to restore circuit breaker snapshot", err)
+	}
+}
+
+func (b *Breaker) logStoreError(msg string, err error) {
+	if b.options.logger == nil {
+		return
+	}
+
+	b.options.logger.LogAttrs(context.Background(), slog.LevelError, msg,
+		slog.String("breaker", b.options.name),
+		slog.Any("error", err),
+	)
+}
@@ -0,0 +1,110 @@
+package circuitbreaker
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshot(t *testing.T) {
+	c := &testClock{
+		now: time.Now(),
+	}
+
+	b, err := New(WithClock(c), WithTimeout(time.Minute), WithWindow(time.Minute))
+	require.NoError(t, err)
+
+	record(t, b, true)
+	record(t, b, false)
+
+	b.Trip()
+
+	data, err := b.Snapshot()
+	require.NoError(t, err)
+
+	c.advance(30 * time.Second)
+
+	restored, err := New(WithClock(c), WithTimeout(time.Minute), WithWindow(time.Minute))
+	require.NoError(t, err)
+
+	require.NoError(t, restored.Restore(data))
+	require.Equal(t, StateOpen, restored.State())
+
+	counts := restored.counts()
+	require.Equal(t, uint64(2), counts.Requests)
+	require.Equal(t, uint64(1), counts.TotalSuccesses)
+	require.Equal(t, uint64(1), counts.TotalFailures)
+	require.Equal(t, uint64(1), counts.ConsecutiveFailures)
+
+	status := restored.Status()
+	require.Equal(t, 30*time.Second, status.RetryAfter)
+
+	_, err = restored.Allow()
+
+	var openErr *OpenStateError
+	require.ErrorAs(t, err, &openErr)
+	require.Equal(t, ReasonTrip, openErr.Transition.Reason)
+
+	// the open timeout continues from the snapshot
+	c.advance(31 * time.Second)
+	require.Equal(t, StateHalfOpen, restored.State())
+
+	require.ErrorIs(t, restored.Restore([]byte(`{"version": 2}`)), ErrSnapshotVersion)
+	require.Error(t, restored.Restore([]byte(`{`)))
+}
+
+type memoryStore struct {
+	data map[string][]byte
+	lock sync.Mutex
+}
+
+func (m *memoryStore) Load(_ context.Context, name string) ([]byte, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return m.data[name], nil
+}
+
+func (m *memoryStore) Save(_ context.Context, name string, data []byte) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.data[name] = data
+
+	return nil
+}
+
+func (m *memoryStore) Watch(context.Context, string) (<-chan []byte, error) {
+	return nil, nil
+}
+
+func TestWithStore(t *testing.T) {
+	store := &memoryStore{data: map[string][]byte{}}
+
+	_, err := New(WithStore(store, time.Hour))
+	require.ErrorIs(t, err, ErrNoName)
+
+	b, err := New(WithName("test"), WithStore(store, time.Hour), WithTimeout(time.Minute))
+	require.NoError(t, err)
+
+	b.Trip()
+
+	// saved after the transition
+	require.Eventually(t, func() bool {
+		data, _ := store.Load(context.Background(), "test")
+		return data != nil
+	}, time.Second, time.Millisecond)
+
+	require.NoError(t, b.Close())
+	require.NoError(t, b.Close())
+
+	restarted, err := New(WithName("test"), WithStore(store, time.Hour), WithTimeout(time.Minute))
+	require.NoError(t, err)
+
+	defer restarted.Close()
+
+	require.Equal(t, StateOpen, restarted.State())
+}
@@ -0,0 +1,114 @@
+package circuitbreaker
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// spikeBuckets is the number of buckets the time of a SpikeDetector is divided into.
+const spikeBuckets = 16
+
+// SpikeDetector trips a Breaker when the failure rate in its window rises by a factor within a short time,
+// such as doubling within 5 seconds, catching sudden outages before the rate reaches an absolute threshold
+// while ignoring rates that change slowly. The rate is compared with the lowest rate seen by ReadyToTrip within
+// that time, or the last rate seen before it, and a rate is never taken to be below one failure in the window.
+// Rates are kept in buckets of 1/16th of the time, so memory and the cost of ReadyToTrip are fixed.
+//
+// Add it to a Breaker with WithDetector. A SpikeDetector must only be used by one Breaker.
+// ReadyToTrip may also be used with WithReadyToTrip or WithTripCondition, but then measures time with the time package.
+type SpikeDetector struct {
+	clock       detectorClock
+	buckets     [spikeBuckets]spikeBucket
+	factor      float64
+	width       time.Duration
+	minRequests uint64
+	// before is the last rate seen before the buckets, from the bucket with index beforeIndex.
+	before      float64
+	beforeIndex int64
+	hasBefore   bool
+	lock        sync.Mutex
+}
+
+// spikeBucket holds the lowest and the last rate seen in one bucket of time.
+type spikeBucket struct {
+	index int64
+	min   float64
+	last  float64
+	set   bool
+}
+
+// NewSpikeDetector creates a SpikeDetector that trips when the failure rate is at least factor times a rate seen
+// within the last within and the window holds at least minRequests outcomes.
+func NewSpikeDetector(factor float64, within time.Duration, minRequests uint64) *SpikeDetector {
+	return &SpikeDetector{
+		factor:      factor,
+		width:       max(within/spikeBuckets, 1),
+		minRequests: minRequests,
+	}
+}
+
+func (d *SpikeDetector) setClock(clock Clock) {
+	d.clock.set(clock)
+}
+
+// ReadyToTrip is a ReadyToTrip that reports whether the failure rate in counts has spiked,
+// and otherwise remembers it.
+func (d *SpikeDetector) ReadyToTrip(counts Counts) bool {
+	n := counts.TotalSuccesses + counts.TotalFailures
+	if n == 0 {
+		return false
+	}
+
+	rate := float64(counts.TotalFailures) / float64(n)
+	now := d.clock.Now()
+	index := now.UnixNano() / int64(d.width)
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	// expire the buckets older than the time, keeping the last rate of the newest of them
+	oldest := index - spikeBuckets + 1
+
+	for i := range d.buckets {
+		bucket := &d.buckets[i]
+		if !bucket.set || bucket.index >= oldest {
+			continue
+		}
+
+		if !d.hasBefore || bucket.index > d.beforeIndex {
+			d.before, d.beforeIndex, d.hasBefore = bucket.last, bucket.index, true
+		}
+
+		bucket.set = false
+	}
+
+	if n >= d.minRequests {
+		baseline := math.Inf(1)
+		if d.hasBefore {
+			baseline = d.before
+		}
+
+		for _, bucket := range d.buckets {
+			if bucket.set {
+				baseline = math.Min(baseline, bucket.min)
+			}
+		}
+
+		if !math.IsInf(baseline, 1) && rate >= d.factor*math.Max(baseline, 1/float64(n)) {
+			// spikes are left out of the samples
+			return true
+		}
+	}
+
+	bucket := &d.buckets[index%spikeBuckets]
+	if !bucket.set || bucket.index != index {
+		*bucket = spikeBucket{index: index, min: rate, last: rate, set: true}
+		return false
+	}
+
+	bucket.min = math.Min(bucket.min, rate)
+	bucket.last = rate
+
+	return false
+}
@@ -0,0 +1,71 @@
+package circuitbreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpikeDetector(t *testing.T) {
+	c := &testClock{
+		now: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	d := NewSpikeDetector(2, 5*time.Second, 20)
+	d.setClock(c)
+
+	counts := func(successes uint64, failures uint64) Counts {
+		return Counts{Requests: successes + failures, TotalSuccesses: successes, TotalFailures: failures}
+	}
+
+	// there is nothing to compare the first rate with
+	require.False(t, d.ReadyToTrip(counts(90, 10)))
+
+	// a slowly rising rate
+	for i := uint64(1); i <= 10; i++ {
+		c.advance(5 * time.Second)
+		require.False(t, d.ReadyToTrip(counts(90-i*2, 10+i*2)))
+	}
+
+	// too few requests
+	c.advance(time.Second)
+	require.False(t, d.ReadyToTrip(counts(5, 10)))
+
+	// the rate doubles within 5 seconds
+	c.advance(time.Second)
+	require.True(t, d.ReadyToTrip(counts(40, 60)))
+}
+
+func TestSpikeDetectorFloor(t *testing.T) {
+	c := &testClock{
+		now: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	d := NewSpikeDetector(2, 5*time.Second, 1)
+	d.setClock(c)
+
+	require.False(t, d.ReadyToTrip(Counts{Requests: 100, TotalSuccesses: 100}))
+
+	// a single failure does not double a rate of no failures
+	c.advance(time.Second)
+	require.False(t, d.ReadyToTrip(Counts{Requests: 100, TotalSuccesses: 99, TotalFailures: 1}))
+
+	c.advance(time.Second)
+	require.True(t, d.ReadyToTrip(Counts{Requests: 100, TotalSuccesses: 98, TotalFailures: 2}))
+}
+
+func TestSpikeDetectorUpdateOptions(t *testing.T) {
+	c := &testClock{
+		now: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	b, err := New(WithClock(c))
+	require.NoError(t, err)
+
+	d := NewSpikeDetector(2, 5*time.Second, 1)
+
+	// a detector added later also uses the Clock of the Breaker
+	require.NoError(t, b.UpdateOptions(WithDetector("spike", d)))
+	require.Equal(t, c.Now(), d.clock.Now())
+}
@@ -0,0 +1,258 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrSpoolFull is returned by Spooler.Send when the spool is full and the payload is dropped.
+var ErrSpoolFull = errors.New("circuit breaker spool full")
+
+// SpoolBuffer holds payloads spooled by a Spooler, oldest first. A Spooler serializes calls to its SpoolBuffer.
+type SpoolBuffer[T any] interface {
+	// Push adds a payload.
+	Push(item T)
+	// Peek returns the oldest payload without removing it.
+	Peek() (T, bool)
+	// Pop removes the oldest payload.
+	Pop()
+	// Len returns the number of payloads.
+	Len() int
+}
+
+// DropPolicy selects the payload a Spooler drops when its spool is full.
+type DropPolicy int
+
+const (
+	// DropNewest drops the payload being sent.
+	DropNewest DropPolicy = iota
+	// DropOldest drops the oldest spooled payload to make room for the payload being sent.
+	DropOldest
+)
+
+type spoolOptions struct {
+	capacity   int
+	dropPolicy DropPolicy
+}
+
+// SpoolOption sets Spooler options.
+type SpoolOption func(*spoolOptions)
+
+// WithSpoolCapacity sets the maximum number of spooled payloads. The default is 1000.
+func WithSpoolCapacity(n int) SpoolOption {
+	return func(o *spoolOptions) {
+		o.capacity = n
+	}
+}
+
+// WithDropPolicy sets the payload dropped when the spool is full. The default is DropNewest.
+func WithDropPolicy(p DropPolicy) SpoolOption {
+	return func(o *spoolOptions) {
+		o.dropPolicy = p
+	}
+}
+
+// Spooler delivers payloads, such as emails or webhooks, through a Breaker. Rather than failing fast,
+// payloads that are rejected or fail are spooled and delivered in order once the Breaker closes.
+// Call Close to stop watching the Breaker.
+type Spooler[T any] struct {
+	breaker     *Breaker
+	deliver     func(ctx context.Context, item T) error
+	options     spoolOptions
+	unsubscribe func()
+
+	lock     sync.Mutex
+	buffer   SpoolBuffer[T]
+	draining bool
+	popped   uint64
+	dropped  uint64
+}
+
+// NewSpooler creates a Spooler that delivers payloads with deliver. Payloads are spooled in buffer,
+// or in memory if buffer is nil.
+func NewSpooler[T any](b *Breaker, buffer SpoolBuffer[T], deliver func(ctx context.Context, item T) error, options ...SpoolOption) *Spooler[T] {
+	opts := spoolOptions{
+		capacity: 1000,
+	}
+
+	for _, o := range options {
+		o(&opts)
+	}
+
+	if buffer == nil {
+		buffer = &memorySpool[T]{}
+	}
+
+	s := &Spooler[T]{
+		breaker: b,
+		deliver: deliver,
+		options: opts,
+		buffer:  buffer,
+	}
+
+	s.unsubscribe = b.Subscribe(func(t Transition) {
+		if t.To == StateClosed {
+			s.drain()
+		}
+	})
+
+	return s
+}
+
+// Send delivers item, or spools it if the Breaker rejects it, its delivery fails, or earlier payloads are spooled.
+// Send returns ErrSpoolFull if item is dropped, and nil otherwise.
+func (s *Spooler[T]) Send(ctx context.Context, item T) error {
+	s.lock.Lock()
+	spooled := s.draining || s.buffer.Len() > 0
+	s.lock.Unlock()
+
+	if !spooled {
+		done, err := s.breaker.AllowOutcome()
+		if err == nil {
+			err = s.deliver(ctx, item)
+			done(outcomeOf(ctx, err))
+
+			if err == nil {
+				return nil
+			}
+		}
+	}
+
+	if err := s.spool(item); err != nil {
+		return err
+	}
+
+	// a delivery that just failed is not retried until the next payload or transition
+	if spooled && closed(s.breaker.State()) {
+		s.drain()
+	}
+
+	return nil
+}
+
+// Flush delivers spooled payloads until the spool is empty, the Breaker rejects a payload, or a delivery fails.
+func (s *Spooler[T]) Flush(ctx context.Context) error {
+	s.lock.Lock()
+	if s.draining {
+		s.lock.Unlock()
+		return nil
+	}
+
+	s.draining = true
+	s.lock.Unlock()
+
+	for {
+		s.lock.Lock()
+		item, ok := s.buffer.Peek()
+		popped := s.popped
+		// stop while holding the lock, so a payload spooled after this sees that the spool is not draining
+		s.draining = ok
+		s.lock.Unlock()
+
+		if !ok {
+			return nil
+		}
+
+		done, err := s.breaker.AllowOutcome()
+		if err == nil {
+			err = s.deliver(ctx, item)
+			done(outcomeOf(ctx, err))
+		}
+
+		if err != nil {
+			s.lock.Lock()
+			s.draining = false
+			s.lock.Unlock()
+
+			return err
+		}
+
+		s.lock.Lock()
+		// the payload may have been dropped while it was being delivered
+		if s.popped == popped {
+			s.pop()
+		}
+		s.lock.Unlock()
+	}
+}
+
+// Len returns the number of spooled payloads.
+func (s *Spooler[T]) Len() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.buffer.Len()
+}
+
+// Dropped returns the number of payloads dropped because the spool was full.
+func (s *Spooler[T]) Dropped() uint64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.dropped
+}
+
+// Close stops draining the spool when the Breaker closes. Spooled payloads are kept in the buffer.
+func (s *Spooler[T]) Close() {
+	s.unsubscribe()
+}
+
+func (s *Spooler[T]) spool(item T) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.buffer.Len() >= s.options.capacity {
+		s.dropped++
+
+		if s.options.dropPolicy == DropNewest {
+			return ErrSpoolFull
+		}
+
+		s.pop()
+	}
+
+	s.buffer.Push(item)
+
+	return nil
+}
+
+func (s *Spooler[T]) pop() {
+	s.buffer.Pop()
+	s.popped++
+}
+
+func (s *Spooler[T]) drain() {
+	go func() {
+		_ = s.Flush(context.Background())
+	}()
+}
+
+// memorySpool is the default SpoolBuffer.
+type memorySpool[T any] struct {
+	items []T
+}
+
+func (m *memorySpool[T]) Push(item T) {
+	m.items = append(m.items, item)
+}
+
+func (m *memorySpool[T]) Peek() (T, bool) {
+	if len(m.items) == 0 {
+		var zero T
+		return zero, false
+	}
+
+	return m.items[0], true
+}
+
+func (m *memorySpool[T]) Pop() {
+	var zero T
+
+	m.items[0] = zero
+	m.items = m.items[1:]
+}
+
+func (m *memorySpool[T]) Len() int {
+	return len(m.items)
+}
@@ -0,0 +1,89 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpooler(t *testing.T) {
+	b, err := New(WithWindow(time.Minute))
+	require.NoError(t, err)
+
+	var (
+		lock      sync.Mutex
+		delivered []int
+	)
+
+	s := NewSpooler[int](b, nil, func(_ context.Context, i int) error {
+		lock.Lock()
+		defer lock.Unlock()
+
+		delivered = append(delivered, i)
+
+		return nil
+	}, WithSpoolCapacity(2))
+	defer s.Close()
+
+	require.NoError(t, s.Send(context.Background(), 1))
+
+	b.Trip()
+
+	require.NoError(t, s.Send(context.Background(), 2))
+	require.NoError(t, s.Send(context.Background(), 3))
+	require.ErrorIs(t, s.Send(context.Background(), 4), ErrSpoolFull)
+	require.Equal(t, 2, s.Len())
+	require.Equal(t, uint64(1), s.Dropped())
+
+	b.Reset()
+
+	require.Eventually(t, func() bool { return s.Len() == 0 }, time.Second, time.Millisecond)
+
+	lock.Lock()
+	require.Equal(t, []int{1, 2, 3}, delivered)
+	lock.Unlock()
+}
+
+func TestSpoolerDropOldest(t *testing.T) {
+	b, err := New(WithWindow(time.Minute))
+	require.NoError(t, err)
+
+	fail := errors.New("fail")
+
+	var delivered []int
+
+	s := NewSpooler[int](b, nil, func(_ context.Context, i int) error {
+		if i == 1 {
+			return fail
+		}
+
+		delivered = append(delivered, i)
+
+		return nil
+	}, WithSpoolCapacity(2), WithDropPolicy(DropOldest))
+	defer s.Close()
+
+	b.Trip()
+
+	for i := 1; i <= 3; i++ {
+		require.NoError(t, s.Send(context.Background(), i))
+	}
+
+	require.Equal(t, 2, s.Len())
+	require.Equal(t, uint64(1), s.Dropped())
+
+	b.Reset()
+
+	require.Eventually(t, func() bool { return s.Len() == 0 }, time.Second, time.Millisecond)
+	require.Equal(t, []int{2, 3}, delivered)
+
+	// a failed delivery is spooled for a later flush
+	require.NoError(t, s.Send(context.Background(), 1))
+	require.Equal(t, 1, s.Len())
+	require.ErrorIs(t, s.Flush(context.Background()), fail)
+	require.Equal(t, 1, s.Len())
+}
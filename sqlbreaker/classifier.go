@@ -0,0 +1,77 @@
+package sqlbreaker
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+// Classifier returns the outcome of a database operation that returned err.
+type Classifier func(err error) circuitbreaker.Outcome
+
+// sqlStateError is implemented by the errors of many drivers, such as lib/pq and pgx.
+type sqlStateError interface {
+	SQLState() string
+}
+
+// DefaultClassifier classifies errors that indicate an unhealthy database as failures:
+// driver.ErrBadConn, timeouts, connection exceptions, and resource exhaustion such as
+// "too many connections". Errors reported by a healthy database, such as constraint
+// violations and syntax errors, are ignored, as are requests canceled by the caller.
+//
+// Errors are recognized using SQLSTATE codes when the driver's error type has a
+// SQLState() string method. Other errors are failures unless their message mentions
+// a constraint or duplicate key.
+func DefaultClassifier(err error) circuitbreaker.Outcome {
+	if err == nil || errors.Is(err, sql.ErrNoRows) {
+		return circuitbreaker.OutcomeSuccess
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, driver.ErrSkip) {
+		return circuitbreaker.OutcomeIgnored
+	}
+
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, context.DeadlineExceeded) {
+		return circuitbreaker.OutcomeFailure
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return circuitbreaker.OutcomeFailure
+	}
+
+	msg := strings.ToLower(err.Error())
+	if strings.Contains(msg, "too many connections") || strings.Contains(msg, "too many clients") {
+		return circuitbreaker.OutcomeFailure
+	}
+
+	var stateErr sqlStateError
+	if errors.As(err, &stateErr) {
+		return classifySQLState(stateErr.SQLState())
+	}
+
+	if strings.Contains(msg, "constraint") || strings.Contains(msg, "duplicate key") {
+		return circuitbreaker.OutcomeIgnored
+	}
+
+	return circuitbreaker.OutcomeFailure
+}
+
+func classifySQLState(state string) circuitbreaker.Outcome {
+	if len(state) < 2 {
+		return circuitbreaker.OutcomeFailure
+	}
+
+	switch state[:2] {
+	// connection exception, insufficient resources, operator intervention, system error
+	case "08", "53", "57", "58":
+		return circuitbreaker.OutcomeFailure
+	default:
+		return circuitbreaker.OutcomeIgnored
+	}
+}
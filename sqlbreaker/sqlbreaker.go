@@ -0,0 +1,449 @@
+// Package sqlbreaker wraps database/sql drivers so that database operations run through a circuit breaker.
+package sqlbreaker
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+type options struct {
+	classifier   Classifier
+	writeBreaker *circuitbreaker.Breaker
+	writeQuery   func(query string) bool
+}
+
+// Option sets sqlbreaker options.
+type Option func(*options)
+
+// WithClassifier sets the classifier used to record the outcome of operations.
+// Default is DefaultClassifier.
+func WithClassifier(c Classifier) Option {
+	return func(o *options) {
+		o.classifier = c
+	}
+}
+
+// WithWriteBreaker runs writes through b rather than the breaker passed to NewConnector, so writes can have
+// stricter thresholds than reads: executing statements, beginning and committing transactions that are not
+// read-only, and queries that WithWriteQuery reports as writes. Other operations, such as connecting and
+// pinging, are reads.
+func WithWriteBreaker(b *circuitbreaker.Breaker) Option {
+	return func(o *options) {
+		o.writeBreaker = b
+	}
+}
+
+// WithWriteQuery sets the function that reports whether a query, rather than an executed statement, is a write,
+// such as one with INSERT ... RETURNING. Default is that queries are reads.
+func WithWriteQuery(fn func(query string) bool) Option {
+	return func(o *options) {
+		o.writeQuery = fn
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		classifier: DefaultClassifier,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// guard runs database operations through a breaker.
+type guard struct {
+	breaker *circuitbreaker.Breaker
+	options options
+}
+
+// do runs a read.
+func (g *guard) do(fn func() error) error {
+	return g.run(g.breaker, fn)
+}
+
+// doWrite runs a write, see WithWriteBreaker.
+func (g *guard) doWrite(fn func() error) error {
+	if g.options.writeBreaker == nil {
+		return g.do(fn)
+	}
+
+	return g.run(g.options.writeBreaker, fn)
+}
+
+// doQuery runs a query, which may be a write, see WithWriteQuery.
+func (g *guard) doQuery(query string, fn func() error) error {
+	if g.options.writeQuery != nil && g.options.writeQuery(query) {
+		return g.doWrite(fn)
+	}
+
+	return g.do(fn)
+}
+
+func (g *guard) run(b *circuitbreaker.Breaker, fn func() error) error {
+	done, err := b.AllowOutcome()
+	if err != nil {
+		return err
+	}
+
+	err = fn()
+
+	done(g.options.classifier(err))
+
+	return err
+}
+
+// Connector is a driver.Connector that runs connections, queries, statements, and
+// transactions through a circuit breaker. When the breaker does not allow an operation,
+// the error from the breaker is returned.
+type Connector struct {
+	next  driver.Connector
+	guard *guard
+}
+
+// NewConnector wraps a driver.Connector.
+func NewConnector(c driver.Connector, b *circuitbreaker.Breaker, opts ...Option) *Connector {
+	return &Connector{
+		next: c,
+		guard: &guard{
+			breaker: b,
+			options: newOptions(opts),
+		},
+	}
+}
+
+// OpenDB wraps a driver.Connector and opens a database using it.
+func OpenDB(c driver.Connector, b *circuitbreaker.Breaker, opts ...Option) *sql.DB {
+	return sql.OpenDB(NewConnector(c, b, opts...))
+}
+
+// Open opens a database using a registered driver, like sql.Open, with operations running
+// through the breaker.
+func Open(driverName string, dataSourceName string, b *circuitbreaker.Breaker, opts ...Option) (*sql.DB, error) {
+	// sql.Open does not connect, it is only used to find the driver
+	db, err := sql.Open(driverName, dataSourceName)
+	if err != nil {
+		return nil, err
+	}
+
+	d := db.Driver()
+
+	if err := db.Close(); err != nil {
+		return nil, err
+	}
+
+	var c driver.Connector = dsnConnector{driver: d, dsn: dataSourceName}
+
+	if dc, ok := d.(driver.DriverContext); ok {
+		c, err = dc.OpenConnector(dataSourceName)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return OpenDB(c, b, opts...), nil
+}
+
+type dsnConnector struct {
+	driver driver.Driver
+	dsn    string
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// Connect implements driver.Connector.
+func (c *Connector) Connect(ctx context.Context) (driver.Conn, error) {
+	var conn driver.Conn
+
+	err := c.guard.do(func() error {
+		var err error
+		conn, err = c.next.Connect(ctx)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &wrappedConn{Conn: conn, guard: c.guard}, nil
+}
+
+// Driver implements driver.Connector.
+func (c *Connector) Driver() driver.Driver {
+	return c.next.Driver()
+}
+
+type wrappedConn struct {
+	driver.Conn
+	guard *guard
+}
+
+var (
+	_ driver.ConnPrepareContext = (*wrappedConn)(nil)
+	_ driver.ConnBeginTx        = (*wrappedConn)(nil)
+	_ driver.ExecerContext      = (*wrappedConn)(nil)
+	_ driver.QueryerContext     = (*wrappedConn)(nil)
+	_ driver.Pinger             = (*wrappedConn)(nil)
+	_ driver.SessionResetter    = (*wrappedConn)(nil)
+	_ driver.Validator          = (*wrappedConn)(nil)
+	_ driver.NamedValueChecker  = (*wrappedConn)(nil)
+)
+
+func (c *wrappedConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *wrappedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var stmt driver.Stmt
+
+	err := c.guard.do(func() error {
+		var err error
+
+		if p, ok := c.Conn.(driver.ConnPrepareContext); ok {
+			stmt, err = p.PrepareContext(ctx, query)
+		} else {
+			stmt, err = c.Conn.Prepare(query)
+		}
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &wrappedStmt{Stmt: stmt, guard: c.guard, query: query}, nil
+}
+
+func (c *wrappedConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *wrappedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	var tx driver.Tx
+
+	do := c.guard.doWrite
+	if opts.ReadOnly {
+		do = c.guard.do
+	}
+
+	err := do(func() error {
+		var err error
+
+		if b, ok := c.Conn.(driver.ConnBeginTx); ok {
+			tx, err = b.BeginTx(ctx, opts)
+		} else {
+			//nolint:staticcheck // fallback for drivers without BeginTx
+			tx, err = c.Conn.Begin()
+		}
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &wrappedTx{Tx: tx, guard: c.guard, readOnly: opts.ReadOnly}, nil
+}
+
+func (c *wrappedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	e, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		// database/sql falls back to a prepared statement
+		return nil, driver.ErrSkip
+	}
+
+	var result driver.Result
+
+	err := c.guard.doWrite(func() error {
+		var err error
+		result, err = e.ExecContext(ctx, query, args)
+
+		return err
+	})
+
+	return result, err
+}
+
+func (c *wrappedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	q, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		// database/sql falls back to a prepared statement
+		return nil, driver.ErrSkip
+	}
+
+	var rows driver.Rows
+
+	err := c.guard.doQuery(query, func() error {
+		var err error
+		rows, err = q.QueryContext(ctx, query, args)
+
+		return err
+	})
+
+	return rows, err
+}
+
+func (c *wrappedConn) Ping(ctx context.Context) error {
+	p, ok := c.Conn.(driver.Pinger)
+	if !ok {
+		return nil
+	}
+
+	return c.guard.do(func() error {
+		return p.Ping(ctx)
+	})
+}
+
+func (c *wrappedConn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+
+	return nil
+}
+
+func (c *wrappedConn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+
+	return true
+}
+
+func (c *wrappedConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if n, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return n.CheckNamedValue(nv)
+	}
+
+	return driver.ErrSkip
+}
+
+type wrappedStmt struct {
+	driver.Stmt
+	guard *guard
+	query string
+}
+
+var (
+	_ driver.StmtExecContext  = (*wrappedStmt)(nil)
+	_ driver.StmtQueryContext = (*wrappedStmt)(nil)
+)
+
+func (s *wrappedStmt) Exec(args []driver.Value) (driver.Result, error) {
+	var result driver.Result
+
+	err := s.guard.doWrite(func() error {
+		var err error
+		//nolint:staticcheck // required by driver.Stmt
+		result, err = s.Stmt.Exec(args)
+
+		return err
+	})
+
+	return result, err
+}
+
+func (s *wrappedStmt) Query(args []driver.Value) (driver.Rows, error) {
+	var rows driver.Rows
+
+	err := s.guard.doQuery(s.query, func() error {
+		var err error
+		//nolint:staticcheck // required by driver.Stmt
+		rows, err = s.Stmt.Query(args)
+
+		return err
+	})
+
+	return rows, err
+}
+
+func (s *wrappedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	e, ok := s.Stmt.(driver.StmtExecContext)
+	if !ok {
+		values, err := namedValues(args)
+		if err != nil {
+			return nil, err
+		}
+
+		return s.Exec(values)
+	}
+
+	var result driver.Result
+
+	err := s.guard.doWrite(func() error {
+		var err error
+		result, err = e.ExecContext(ctx, args)
+
+		return err
+	})
+
+	return result, err
+}
+
+func (s *wrappedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	q, ok := s.Stmt.(driver.StmtQueryContext)
+	if !ok {
+		values, err := namedValues(args)
+		if err != nil {
+			return nil, err
+		}
+
+		return s.Query(values)
+	}
+
+	var rows driver.Rows
+
+	err := s.guard.doQuery(s.query, func() error {
+		var err error
+		rows, err = q.QueryContext(ctx, args)
+
+		return err
+	})
+
+	return rows, err
+}
+
+func namedValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, errors.New("sqlbreaker: driver does not support named parameters")
+		}
+
+		values[i] = arg.Value
+	}
+
+	return values, nil
+}
+
+type wrappedTx struct {
+	driver.Tx
+	guard    *guard
+	readOnly bool
+}
+
+func (t *wrappedTx) Commit() error {
+	if t.readOnly {
+		return t.guard.do(t.Tx.Commit)
+	}
+
+	return t.guard.doWrite(t.Tx.Commit)
+}
+
+// Rollback is not run through the breaker so that transactions are never left open.
+func (t *wrappedTx) Rollback() error {
+	return t.Tx.Rollback()
+}
@@ -0,0 +1,203 @@
+package sqlbreaker
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+// fakeConnector returns connections that fail with err.
+type fakeConnector struct {
+	err error
+}
+
+func (c *fakeConnector) Connect(context.Context) (driver.Conn, error) {
+	return &fakeConn{connector: c}, nil
+}
+
+func (c *fakeConnector) Driver() driver.Driver {
+	return nil
+}
+
+type fakeConn struct {
+	connector *fakeConnector
+}
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return c, c.connector.err
+}
+
+func (c *fakeConn) Commit() error {
+	return c.connector.err
+}
+
+func (c *fakeConn) Rollback() error {
+	return nil
+}
+
+func (c *fakeConn) ExecContext(context.Context, string, []driver.NamedValue) (driver.Result, error) {
+	if c.connector.err != nil {
+		return nil, c.connector.err
+	}
+
+	return driver.RowsAffected(1), nil
+}
+
+func (c *fakeConn) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	if c.connector.err != nil {
+		return nil, c.connector.err
+	}
+
+	return &fakeRows{}, nil
+}
+
+type fakeRows struct {
+	done bool
+}
+
+func (r *fakeRows) Columns() []string {
+	return []string{"n"}
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+
+	r.done = true
+	dest[0] = int64(1)
+
+	return nil
+}
+
+type stateError string
+
+func (e stateError) Error() string {
+	return "sql error " + string(e)
+}
+
+func (e stateError) SQLState() string {
+	return string(e)
+}
+
+func TestOpenDB(t *testing.T) {
+	b, err := circuitbreaker.New(circuitbreaker.WithReadyToTrip(func(c circuitbreaker.Counts) bool {
+		return c.ConsecutiveFailures >= 2
+	}))
+	require.NoError(t, err)
+
+	c := &fakeConnector{}
+
+	db := OpenDB(c, b)
+	defer db.Close()
+
+	ctx := context.Background()
+
+	var n int
+	require.NoError(t, db.QueryRowContext(ctx, "SELECT 1").Scan(&n))
+	require.Equal(t, 1, n)
+
+	_, err = db.ExecContext(ctx, "INSERT")
+	require.NoError(t, err)
+
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+
+	c.err = stateError("23505")
+
+	_, err = db.ExecContext(ctx, "INSERT")
+	require.Error(t, err)
+	require.Equal(t, circuitbreaker.StateClosed, b.State())
+
+	c.err = stateError("53300")
+
+	_, err = db.ExecContext(ctx, "INSERT")
+	require.Error(t, err)
+
+	_, err = db.QueryContext(ctx, "SELECT 1")
+	require.Error(t, err)
+	require.Equal(t, circuitbreaker.StateOpen, b.State())
+
+	c.err = nil
+
+	_, err = db.ExecContext(ctx, "INSERT")
+	require.ErrorIs(t, err, circuitbreaker.ErrOpenState)
+}
+
+func TestWriteBreaker(t *testing.T) {
+	reads, err := circuitbreaker.New()
+	require.NoError(t, err)
+
+	writes, err := circuitbreaker.New()
+	require.NoError(t, err)
+
+	writes.Trip()
+
+	db := OpenDB(&fakeConnector{}, reads, WithWriteBreaker(writes), WithWriteQuery(func(query string) bool {
+		return strings.HasPrefix(query, "INSERT")
+	}))
+	defer db.Close()
+
+	ctx := context.Background()
+
+	var n int
+	require.NoError(t, db.QueryRowContext(ctx, "SELECT 1").Scan(&n))
+
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+
+	_, err = db.ExecContext(ctx, "UPDATE")
+	require.ErrorIs(t, err, circuitbreaker.ErrOpenState)
+
+	_, err = db.QueryContext(ctx, "INSERT RETURNING")
+	require.ErrorIs(t, err, circuitbreaker.ErrOpenState)
+
+	_, err = db.BeginTx(ctx, nil)
+	require.ErrorIs(t, err, circuitbreaker.ErrOpenState)
+}
+
+func TestDefaultClassifier(t *testing.T) {
+	tests := []struct {
+		err  error
+		want circuitbreaker.Outcome
+	}{
+		{nil, circuitbreaker.OutcomeSuccess},
+		{sql.ErrNoRows, circuitbreaker.OutcomeSuccess},
+		{context.Canceled, circuitbreaker.OutcomeIgnored},
+		{driver.ErrBadConn, circuitbreaker.OutcomeFailure},
+		{context.DeadlineExceeded, circuitbreaker.OutcomeFailure},
+		{errors.New("Error 1040: Too many connections"), circuitbreaker.OutcomeFailure},
+		{errors.New("pq: sorry, too many clients already"), circuitbreaker.OutcomeFailure},
+		{stateError("08006"), circuitbreaker.OutcomeFailure},
+		{stateError("23505"), circuitbreaker.OutcomeIgnored},
+		{stateError("42601"), circuitbreaker.OutcomeIgnored},
+		{errors.New("UNIQUE constraint failed: users.email"), circuitbreaker.OutcomeIgnored},
+		{errors.New("connection reset by peer"), circuitbreaker.OutcomeFailure},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.want, DefaultClassifier(tt.err), "%v", tt.err)
+	}
+}
@@ -0,0 +1,81 @@
+package circuitbreaker
+
+import (
+	"context"
+	"fmt"
+)
+
+// StageError is an item that Stage could not process.
+type StageError[T any] struct {
+	Item T
+	Err  error
+}
+
+// Error implements error.
+func (e StageError[T]) Error() string {
+	return fmt.Sprintf("circuit breaker stage: %v", e.Err)
+}
+
+// Unwrap returns Err.
+func (e StageError[T]) Unwrap() error {
+	return e.Err
+}
+
+// Stage processes each item from in with fn using Execute on b and sends the results to the returned channel.
+// Items that fail, and items rejected while b is open, are sent to errs, or dropped if errs is nil,
+// so the stage keeps draining in and resumes processing once b allows requests again.
+// The returned channel is closed once in is closed or ctx is done. Stage does not close errs.
+func Stage[In, Out any](ctx context.Context, b *Breaker, in <-chan In, errs chan<- StageError[In], fn func(ctx context.Context, item In) (Out, error)) <-chan Out {
+	out := make(chan Out)
+
+	go func() {
+		defer close(out)
+
+		for {
+			var (
+				item In
+				ok   bool
+			)
+
+			select {
+			case <-ctx.Done():
+				return
+			case item, ok = <-in:
+				if !ok {
+					return
+				}
+			}
+
+			var result Out
+
+			err := b.Execute(ctx, func(ctx context.Context) error {
+				var err error
+				result, err = fn(ctx, item)
+
+				return err
+			})
+
+			if err != nil {
+				if errs == nil {
+					continue
+				}
+
+				select {
+				case <-ctx.Done():
+					return
+				case errs <- StageError[In]{Item: item, Err: err}:
+				}
+
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case out <- result:
+			}
+		}
+	}()
+
+	return out
+}
@@ -0,0 +1,66 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStage(t *testing.T) {
+	b, err := New(WithWindow(time.Minute), WithReadyToTrip(TripOnConsecutiveFailures(1)))
+	require.NoError(t, err)
+
+	fail := errors.New("fail")
+
+	in := make(chan int)
+	errs := make(chan StageError[int], 10)
+
+	out := Stage(context.Background(), b, in, errs, func(_ context.Context, i int) (int, error) {
+		if i < 0 {
+			return 0, fail
+		}
+
+		return i * 2, nil
+	})
+
+	in <- 1
+	require.Equal(t, 2, <-out)
+
+	// the failure trips the breaker, so the next item is rejected
+	in <- -1
+	in <- 2
+
+	close(in)
+
+	_, ok := <-out
+	require.False(t, ok)
+
+	require.Len(t, errs, 2)
+
+	e := <-errs
+	require.Equal(t, -1, e.Item)
+	require.ErrorIs(t, e, fail)
+
+	e = <-errs
+	require.Equal(t, 2, e.Item)
+	require.ErrorIs(t, e, ErrOpenState)
+}
+
+func TestStageCanceled(t *testing.T) {
+	b, err := New()
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	out := Stage(ctx, b, make(chan int), nil, func(_ context.Context, i int) (int, error) {
+		return i, nil
+	})
+
+	cancel()
+
+	_, ok := <-out
+	require.False(t, ok)
+}
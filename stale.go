@@ -0,0 +1,143 @@
+package circuitbreaker
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Cached is a value returned by StaleCache.
+type Cached[V any] struct {
+	// Time is when the value was returned by the function.
+	Time time.Time
+	// Value is the value.
+	Value V
+	// Stale is true if the Breaker did not allow the request and the value is from an earlier request.
+	Stale bool
+}
+
+// StaleCache runs functions that return values through a Breaker and keeps the last good value
+// for each key. When the Breaker does not allow a request, the last good value is returned instead
+// of an error, since stale data is often better than no data.
+//
+// Values are kept until they are deleted or replaced, so keys should be drawn from a bounded set.
+type StaleCache[K comparable, V any] struct {
+	breaker  *Breaker
+	values   map[K]Cached[V]
+	maxStale time.Duration
+	lock     sync.Mutex
+}
+
+// StaleCacheOption sets StaleCache options.
+type StaleCacheOption func(*staleCacheOptions)
+
+type staleCacheOptions struct {
+	maxStale time.Duration
+}
+
+// WithMaxStaleness sets the maximum age of a value that is returned when the Breaker does not allow a request.
+// Default is no maximum.
+func WithMaxStaleness(d time.Duration) StaleCacheOption {
+	return func(o *staleCacheOptions) {
+		o.maxStale = d
+	}
+}
+
+// NewStaleCache creates a StaleCache that uses b.
+func NewStaleCache[K comparable, V any](b *Breaker, options ...StaleCacheOption) *StaleCache[K, V] {
+	var opts staleCacheOptions
+
+	for _, o := range options {
+		o(&opts)
+	}
+
+	return &StaleCache[K, V]{
+		breaker:  b,
+		values:   make(map[K]Cached[V]),
+		maxStale: opts.maxStale,
+	}
+}
+
+// Execute runs fn using the Breaker's Execute. If fn succeeds, its value is cached for key and returned.
+// If the Breaker does not allow the request and a value is cached for key, the cached value is returned,
+// marked as stale. Otherwise, the error is returned.
+func (c *StaleCache[K, V]) Execute(ctx context.Context, key K, fn func(ctx context.Context) (V, error)) (Cached[V], error) {
+	var value V
+
+	err := c.breaker.Execute(ctx, func(ctx context.Context) error {
+		v, err := fn(ctx)
+		if err != nil {
+			return err
+		}
+
+		value = v
+
+		return nil
+	})
+
+	if err == nil {
+		cached := Cached[V]{
+			Time:  c.breaker.options.clock.Now(),
+			Value: value,
+		}
+
+		c.lock.Lock()
+		c.values[key] = cached
+		c.lock.Unlock()
+
+		return cached, nil
+	}
+
+	if !IsRejected(err) {
+		return Cached[V]{}, err
+	}
+
+	c.lock.Lock()
+	cached, ok := c.values[key]
+	c.lock.Unlock()
+
+	if !ok || (c.maxStale > 0 && c.breaker.options.clock.Now().Sub(cached.Time) > c.maxStale) {
+		return Cached[V]{}, err
+	}
+
+	cached.Stale = true
+
+	return cached, nil
+}
+
+// Delete removes the cached value for key.
+func (c *StaleCache[K, V]) Delete(key K) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	delete(c.values, key)
+}
+
+// ShouldServeStale reports whether a cache in front of the Breaker's dependency should extend TTLs or serve
+// expired entries, which is while the Breaker is open or half-open.
+func (b *Breaker) ShouldServeStale() bool {
+	return serveStale(b.State())
+}
+
+// SubscribeStale registers a function that is called when ShouldServeStale changes, so a cache can adjust its
+// policy as the dependency's health changes. It is called like the functions registered with Subscribe.
+// Call the returned function to unsubscribe.
+func (b *Breaker) SubscribeStale(fn func(stale bool)) func() {
+	return b.Subscribe(func(t Transition) {
+		if stale := serveStale(t.To); stale != serveStale(t.From) {
+			fn(stale)
+		}
+	})
+}
+
+// ShouldServeStale reports whether the named Breaker's ShouldServeStale is true.
+// It is false if no Breaker is registered with the name.
+func (r *Registry) ShouldServeStale(name string) bool {
+	b, ok := r.Get(name)
+
+	return ok && b.ShouldServeStale()
+}
+
+func serveStale(s State) bool {
+	return s == StateOpen || s == StateHalfOpen
+}
@@ -0,0 +1,109 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaleCache(t *testing.T) {
+	c := &testClock{
+		now: time.Now(),
+	}
+
+	b, err := New(WithClock(c), WithTimeout(time.Minute))
+	require.NoError(t, err)
+
+	cache := NewStaleCache[string, int](b, WithMaxStaleness(10*time.Second))
+
+	ctx := context.Background()
+
+	v, err := cache.Execute(ctx, "a", func(context.Context) (int, error) { return 1, nil })
+	require.NoError(t, err)
+	require.Equal(t, 1, v.Value)
+	require.False(t, v.Stale)
+
+	fail := errors.New("fail")
+
+	_, err = cache.Execute(ctx, "a", func(context.Context) (int, error) { return 0, fail })
+	require.ErrorIs(t, err, fail)
+
+	b.Trip()
+
+	v, err = cache.Execute(ctx, "a", func(context.Context) (int, error) { return 2, nil })
+	require.NoError(t, err)
+	require.Equal(t, 1, v.Value)
+	require.True(t, v.Stale)
+	require.Equal(t, c.now, v.Time)
+
+	_, err = cache.Execute(ctx, "b", func(context.Context) (int, error) { return 2, nil })
+	require.ErrorIs(t, err, ErrOpenState)
+
+	c.advance(11 * time.Second)
+
+	_, err = cache.Execute(ctx, "a", func(context.Context) (int, error) { return 2, nil })
+	require.ErrorIs(t, err, ErrOpenState)
+}
+
+func TestShouldServeStale(t *testing.T) {
+	c := &testClock{
+		now: time.Now(),
+	}
+
+	b, err := New(WithName("db"), WithClock(c))
+	require.NoError(t, err)
+
+	reg := NewRegistry()
+	require.NoError(t, reg.Register(b))
+
+	changes := make(chan bool, 10)
+
+	unsubscribe := b.SubscribeStale(func(stale bool) { changes <- stale })
+	defer unsubscribe()
+
+	require.False(t, reg.ShouldServeStale("db"))
+	require.False(t, reg.ShouldServeStale("missing"))
+
+	b.Trip()
+	require.True(t, reg.ShouldServeStale("db"))
+
+	// still stale while half-open
+	c.advance(time.Minute)
+	require.Equal(t, StateHalfOpen, b.State())
+	require.True(t, b.ShouldServeStale())
+
+	b.Reset()
+	require.False(t, b.ShouldServeStale())
+
+	for _, want := range []bool{true, false} {
+		select {
+		case stale := <-changes:
+			require.Equal(t, want, stale)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for change")
+		}
+	}
+}
+
+func TestSubscribeStaleStatus(t *testing.T) {
+	b, err := New()
+	require.NoError(t, err)
+
+	states := make(chan State, 1)
+
+	// the function is not called while the Breaker is locked, so it may call its methods
+	unsubscribe := b.SubscribeStale(func(bool) { states <- b.Status().State })
+	defer unsubscribe()
+
+	b.Trip()
+
+	select {
+	case s := <-states:
+		require.Equal(t, StateOpen, s)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for change")
+	}
+}
@@ -0,0 +1,73 @@
+package circuitbreaker
+
+import (
+	"time"
+)
+
+// Stats holds long running statistics about a Breaker.
+type Stats struct {
+	// TimeInState is the cumulative time spent in each state since the Breaker was created.
+	TimeInState map[State]time.Duration
+	// Opens is the number of transitions to the open state since the Breaker was created.
+	Opens uint64
+	// OpensLastHour is the number of transitions to the open state in the last hour.
+	// A high value indicates a flapping Breaker.
+	OpensLastHour uint64
+	// CallerDeadlines is the number of functions run by Execute that failed because the caller's deadline
+	// passed before the call timeout, since the Breaker was created.
+	CallerDeadlines uint64
+	// DroppedObservations is the number of per-request events that were not delivered to event sinks because
+	// too many were waiting, since the Breaker was created. Transitions are never dropped.
+	DroppedObservations uint64
+}
+
+// stats is tracked by the Breaker and must be accessed with the Breaker lock held.
+type stats struct {
+	timeInState map[State]time.Duration
+	opens       uint64
+	// one bucket per minute
+	opensLastHour *window
+}
+
+func newStats(clock Clock) *stats {
+	return &stats{
+		timeInState:   make(map[State]time.Duration),
+		opensLastHour: newWindow(clock, 60, time.Minute),
+	}
+}
+
+// transition records leaving from at now.
+func (s *stats) transition(from State, to State, since time.Time, now time.Time) {
+	s.timeInState[from] += now.Sub(since)
+
+	if to == StateOpen {
+		s.opens++
+		s.opensLastHour.Add(1)
+	}
+}
+
+// Stats returns statistics about the Breaker.
+func (b *Breaker) Stats() Stats {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	timeInState := make(map[State]time.Duration, len(b.stats.timeInState)+1)
+	for k, v := range b.stats.timeInState {
+		timeInState[k] = v
+	}
+
+	timeInState[b.loadState()] += b.options.clock.Now().Sub(b.lastStateChange)
+
+	stats := Stats{
+		TimeInState:     timeInState,
+		Opens:           b.stats.opens,
+		OpensLastHour:   b.stats.opensLastHour.Sum(),
+		CallerDeadlines: b.callerDeadlines.Load(),
+	}
+
+	if b.observers != nil {
+		stats.DroppedObservations = b.observers.dropped.Load()
+	}
+
+	return stats
+}
@@ -0,0 +1,35 @@
+package circuitbreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStats(t *testing.T) {
+	c := &testClock{
+		now: time.Now(),
+	}
+
+	b, err := New(WithClock(c), WithTimeout(time.Minute))
+	require.NoError(t, err)
+
+	c.advance(time.Second)
+
+	b.Trip()
+
+	c.advance(2 * time.Second)
+
+	b.Reset()
+	b.Trip()
+
+	c.advance(3 * time.Second)
+
+	s := b.Stats()
+	require.Equal(t, uint64(2), s.Opens)
+	require.Equal(t, uint64(2), s.OpensLastHour)
+	require.Equal(t, time.Second, s.TimeInState[StateClosed])
+	require.Equal(t, 5*time.Second, s.TimeInState[StateOpen])
+	require.Equal(t, time.Duration(0), s.TimeInState[StateHalfOpen])
+}
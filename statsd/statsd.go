@@ -0,0 +1,218 @@
+// Package statsd provides a circuitbreaker.Notifier and circuitbreaker.Sink that emit metrics and
+// events using the DogStatsD protocol.
+package statsd
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+// Client emits DogStatsD metrics and events.
+// It implements circuitbreaker.Notifier. The labels of a Breaker, see circuitbreaker.WithLabels, are added as tags.
+type Client struct {
+	conn        net.Conn
+	lastChanges map[string]time.Time
+	options     options
+	lock        sync.Mutex
+}
+
+type options struct {
+	prefix string
+	tags   []string
+	events bool
+}
+
+// Option sets Client options.
+type Option func(*options)
+
+// WithPrefix sets the prefix of metric names.
+// Default is "circuitbreaker.".
+func WithPrefix(prefix string) Option {
+	return func(o *options) {
+		o.prefix = prefix
+	}
+}
+
+// WithTags adds tags, such as "env:prod", to all metrics and events.
+func WithTags(tags ...string) Option {
+	return func(o *options) {
+		o.tags = append(o.tags, tags...)
+	}
+}
+
+// WithEvents enables or disables sending a DogStatsD event for each transition.
+// Default is enabled.
+func WithEvents(enabled bool) Option {
+	return func(o *options) {
+		o.events = enabled
+	}
+}
+
+// New creates a Client that sends to the DogStatsD agent listening on the UDP address addr.
+func New(addr string, opts ...Option) (*Client, error) {
+	o := options{
+		prefix: "circuitbreaker.",
+		events: true,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial statsd %q: %w", addr, err)
+	}
+
+	return &Client{
+		conn:        conn,
+		options:     o,
+		lastChanges: make(map[string]time.Time),
+	}, nil
+}
+
+// Close closes the underlying connection.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Notify implements circuitbreaker.Notifier. It emits a transition counter, a state gauge,
+// a timing of the time spent in the previous state, and optionally an event.
+func (c *Client) Notify(e circuitbreaker.TransitionEvent) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	t := e.Transition
+	tags := c.tags(e.Labels, "breaker:"+e.Name, "from:"+t.From.String(), "to:"+t.To.String(), "reason:"+t.Reason.String())
+
+	lines := []string{
+		c.metric("transitions", "1", "c", tags),
+		c.metric("state", fmt.Sprint(int(t.To)), "g", c.tags(e.Labels, "breaker:"+e.Name)),
+	}
+
+	if last, ok := c.lastChanges[e.Name]; ok {
+		ms := float64(t.Time.Sub(last)) / float64(time.Millisecond)
+		lines = append(lines, c.metric("state_duration", fmt.Sprintf("%g", ms), "ms", c.tags(e.Labels, "breaker:"+e.Name, "state:"+t.From.String())))
+	}
+
+	c.lastChanges[e.Name] = t.Time
+
+	if c.options.events {
+		title := fmt.Sprintf("circuit breaker %s is %s", e.Name, t.To)
+		text := fmt.Sprintf("changed from %s to %s: %s", t.From, t.To, t.Reason)
+
+		if t.Condition != "" {
+			text += " (" + t.Condition + ")"
+		}
+
+		alert := "info"
+		if t.To == circuitbreaker.StateOpen {
+			alert = "warning"
+		}
+
+		lines = append(lines, fmt.Sprintf("_e{%d,%d}:%s|%s|d:%d|t:%s%s", len(title), len(text), title, text, t.Time.Unix(), alert, joinTags(tags)))
+	}
+
+	c.write(lines)
+}
+
+// Handle implements circuitbreaker.Sink, see circuitbreaker.WithEventSink. It emits a counter for each
+// admission, rejection, and configuration change, a counter and a timing for each outcome, and handles
+// transitions like Notify.
+func (c *Client) Handle(e circuitbreaker.Event) {
+	if e.Kind == circuitbreaker.EventTransition {
+		c.Notify(circuitbreaker.TransitionEvent{Name: e.Breaker, Transition: e.Transition, Labels: e.Labels})
+		return
+	}
+
+	tags := c.tags(e.Labels, "breaker:"+e.Breaker)
+
+	var lines []string
+
+	switch e.Kind {
+	case circuitbreaker.EventAdmission:
+		lines = append(lines, c.metric("admissions", "1", "c", tags))
+	case circuitbreaker.EventRejection:
+		lines = append(lines, c.metric("rejections", "1", "c", tags))
+	case circuitbreaker.EventOutcome:
+		ms := float64(e.Latency) / float64(time.Millisecond)
+		tags = append(tags, "outcome:"+e.Outcome.String())
+		lines = append(lines,
+			c.metric("outcomes", "1", "c", tags),
+			c.metric("latency", fmt.Sprintf("%g", ms), "ms", tags),
+		)
+	case circuitbreaker.EventConfigChange:
+		lines = append(lines, c.metric("config_changes", "1", "c", tags))
+	default:
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.write(lines)
+}
+
+// Report emits gauges for the current counts, health, and statistics of a Breaker.
+// It is intended to be called periodically.
+func (c *Client) Report(b *circuitbreaker.Breaker) {
+	status := b.Status()
+	stats := b.Stats()
+	tags := c.tags(status.Labels, "breaker:"+status.Name)
+
+	lines := []string{
+		c.metric("state", fmt.Sprint(int(status.State)), "g", tags),
+		c.metric("requests", fmt.Sprint(status.Counts.Requests), "g", tags),
+		c.metric("successes", fmt.Sprint(status.Counts.TotalSuccesses), "g", tags),
+		c.metric("failures", fmt.Sprint(status.Counts.TotalFailures), "g", tags),
+		c.metric("consecutive_failures", fmt.Sprint(status.Counts.ConsecutiveFailures), "g", tags),
+		c.metric("health", fmt.Sprintf("%g", b.Health()), "g", tags),
+		c.metric("opens_last_hour", fmt.Sprint(stats.OpensLastHour), "g", tags),
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.write(lines)
+}
+
+func (c *Client) metric(name string, value string, kind string, tags []string) string {
+	return c.options.prefix + name + ":" + value + "|" + kind + joinTags(tags)
+}
+
+// tags returns the tags of the Client, followed by labels as "key:value" tags sorted by key, and tags.
+func (c *Client) tags(labels map[string]string, tags ...string) []string {
+	all := append([]string{}, c.options.tags...)
+
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+
+	slices.Sort(keys)
+
+	for _, k := range keys {
+		all = append(all, k+":"+labels[k])
+	}
+
+	return append(all, tags...)
+}
+
+func (c *Client) write(lines []string) {
+	// statsd is best effort
+	_, _ = c.conn.Write([]byte(strings.Join(lines, "\n")))
+}
+
+func joinTags(tags []string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+
+	return "|#" + strings.Join(tags, ",")
+}
@@ -0,0 +1,110 @@
+package statsd
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+func TestClient(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	defer conn.Close()
+
+	c, err := New(conn.LocalAddr().String(), WithTags("env:test"))
+	require.NoError(t, err)
+
+	defer c.Close()
+
+	b, err := circuitbreaker.New(circuitbreaker.WithName("db"), circuitbreaker.WithNotifier(c))
+	require.NoError(t, err)
+
+	read := func() []string {
+		buf := make([]byte, 4096)
+
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+
+		n, _, err := conn.ReadFrom(buf)
+		require.NoError(t, err)
+
+		return strings.Split(string(buf[:n]), "\n")
+	}
+
+	b.Trip()
+
+	lines := read()
+	require.Equal(t, "circuitbreaker.transitions:1|c|#env:test,breaker:db,from:closed,to:open,reason:trip", lines[0])
+	require.Equal(t, "circuitbreaker.state:2|g|#env:test,breaker:db", lines[1])
+	require.True(t, strings.HasPrefix(lines[2], "_e{"))
+	require.Contains(t, lines[2], "|t:warning|")
+
+	b.Reset()
+
+	lines = read()
+	require.Len(t, lines, 4)
+	require.True(t, strings.HasPrefix(lines[2], "circuitbreaker.state_duration:"))
+	require.True(t, strings.HasSuffix(lines[2], "|ms|#env:test,breaker:db,state:open"))
+
+	c.Report(b)
+
+	lines = read()
+	require.Contains(t, lines, "circuitbreaker.health:1|g|#env:test,breaker:db")
+
+	b, err = circuitbreaker.New(
+		circuitbreaker.WithName("cache"),
+		circuitbreaker.WithNotifier(c),
+		circuitbreaker.WithLabels(map[string]string{"team": "storage", "region": "us"}),
+	)
+	require.NoError(t, err)
+
+	b.Trip()
+
+	lines = read()
+	require.Equal(t, "circuitbreaker.state:2|g|#env:test,region:us,team:storage,breaker:cache", lines[1])
+}
+
+func TestClientSink(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	defer conn.Close()
+
+	c, err := New(conn.LocalAddr().String())
+	require.NoError(t, err)
+
+	defer c.Close()
+
+	b, err := circuitbreaker.New(circuitbreaker.WithName("db"), circuitbreaker.WithEventSink(c))
+	require.NoError(t, err)
+
+	read := func() string {
+		buf := make([]byte, 4096)
+
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+
+		n, _, err := conn.ReadFrom(buf)
+		require.NoError(t, err)
+
+		return string(buf[:n])
+	}
+
+	done, err := b.Allow()
+	require.NoError(t, err)
+	require.Equal(t, "circuitbreaker.admissions:1|c|#breaker:db", read())
+
+	done(true)
+	require.True(t, strings.HasPrefix(read(), "circuitbreaker.outcomes:1|c|#breaker:db,outcome:success\ncircuitbreaker.latency:"))
+
+	b.Trip()
+	require.True(t, strings.HasPrefix(read(), "circuitbreaker.transitions:1|c|#breaker:db,from:closed,to:open"))
+
+	_, err = b.Allow()
+	require.Error(t, err)
+	require.Equal(t, "circuitbreaker.rejections:1|c|#breaker:db", read())
+}
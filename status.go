@@ -0,0 +1,207 @@
+package circuitbreaker
+
+import (
+	"encoding/json"
+	"maps"
+	"time"
+)
+
+// Status is a snapshot of the Breaker.
+type Status struct {
+	LastStateChange time.Time
+	Name            string
+	Options         StatusOptions
+	Counts          Counts
+	// RetryAfter is the time remaining until an open Breaker becomes half-open.
+	// It may be longer than the timeout if the Breaker was opened using TripFor.
+	// It is zero unless the Breaker is open and not forced.
+	RetryAfter time.Duration
+	State      State
+	Forced     bool
+	// Labels are set by WithLabels.
+	Labels map[string]string
+}
+
+// StatusOptions summarizes the options of a Breaker.
+type StatusOptions struct {
+	Window      time.Duration
+	Timeout     time.Duration
+	MaxRequests uint64
+}
+
+// Status returns a snapshot of the Breaker.
+func (b *Breaker) Status() Status {
+	state := b.State()
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	s := Status{
+		Name:            b.options.name,
+		Labels:          b.Labels(),
+		State:           state,
+		Forced:          b.forced,
+		Counts:          b.counts(),
+		LastStateChange: b.lastStateChange,
+		Options: StatusOptions{
+			Window:      b.options.window,
+			Timeout:     b.options.timeout,
+			MaxRequests: b.options.maxRequests,
+		},
+	}
+
+	if state == StateOpen && !b.forced {
+		if d := b.changedAt + b.openTimeout - b.monotonic(); d > 0 {
+			s.RetryAfter = d
+		}
+	}
+
+	return s
+}
+
+type jsonStatus struct {
+	LastStateChange time.Time         `json:"lastStateChange"`
+	Name            string            `json:"name"`
+	Labels          map[string]string `json:"labels,omitempty"`
+	State           string            `json:"state"`
+	RetryAfter      string            `json:"retryAfter"`
+	Options         jsonOptions       `json:"options"`
+	Counts          jsonCounts        `json:"counts"`
+	Forced          bool              `json:"forced"`
+}
+
+type jsonOptions struct {
+	Window      string `json:"window"`
+	Timeout     string `json:"timeout"`
+	MaxRequests uint64 `json:"maxRequests"`
+}
+
+type jsonCounts struct {
+	Requests             uint64 `json:"requests"`
+	TotalSuccesses       uint64 `json:"totalSuccesses"`
+	TotalFailures        uint64 `json:"totalFailures"`
+	ConsecutiveSuccesses uint64 `json:"consecutiveSuccesses"`
+	ConsecutiveFailures  uint64 `json:"consecutiveFailures"`
+}
+
+// MarshalJSON implements json.Marshaler. Durations are encoded as strings
+// such as "10s" and the state using its String representation.
+func (s Status) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonStatus{
+		LastStateChange: s.LastStateChange,
+		Name:            s.Name,
+		Labels:          s.Labels,
+		State:           s.State.String(),
+		RetryAfter:      s.RetryAfter.String(),
+		Forced:          s.Forced,
+		Counts:          jsonCounts(s.Counts),
+		Options: jsonOptions{
+			Window:      s.Options.Window.String(),
+			Timeout:     s.Options.Timeout.String(),
+			MaxRequests: s.Options.MaxRequests,
+		},
+	})
+}
+
+// OptionsSnapshot describes the options a Breaker is running with, after defaults and updates are applied.
+type OptionsSnapshot struct {
+	Name          string
+	Window        time.Duration
+	Timeout       time.Duration
+	MaxRequests   uint64
+	HistorySize   int
+	CallTimeout   time.Duration
+	HedgeDelay    time.Duration
+	StoreInterval time.Duration
+	Shards        int
+	Warmup        time.Duration
+	Critical      bool
+	// IgnoreDeadlines is set by WithIgnoreCallerDeadlines.
+	IgnoreDeadlines bool
+	// DeadlineAdmission is set by WithDeadlineAdmission.
+	DeadlineAdmission bool
+	// LatencySampling is set by WithLatencySampling.
+	LatencySampling int
+	// ApdexTarget and ApdexThreshold are set by WithApdexTrip and WithApdexThreshold.
+	ApdexTarget    float64
+	ApdexThreshold time.Duration
+	// SuccessBatchSize and SuccessBatchInterval are set by WithSuccessBatch.
+	SuccessBatchSize     int
+	SuccessBatchInterval time.Duration
+	// Labels are set by WithLabels.
+	Labels map[string]string
+	// ObserverQueueSize and SynchronousObservers are set by WithObserverQueueSize and WithSynchronousObservers.
+	ObserverQueueSize    int
+	SynchronousObservers bool
+	// TripConditions are the names of the conditions evaluated when a request fails,
+	// starting with "readyToTrip".
+	TripConditions []string
+	// Hooks are the names of the options that are set with functions or integrations,
+	// such as "onStateChange" or "store".
+	Hooks []string
+}
+
+// Options returns the options the Breaker is running with.
+func (b *Breaker) Options() OptionsSnapshot {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	o := b.options
+
+	s := OptionsSnapshot{
+		Name:                 o.name,
+		Window:               o.window,
+		Timeout:              o.timeout,
+		MaxRequests:          o.maxRequests,
+		HistorySize:          o.historySize,
+		CallTimeout:          o.callTimeout,
+		HedgeDelay:           o.hedgeDelay,
+		StoreInterval:        o.storeInterval,
+		Shards:               o.shards,
+		Warmup:               o.warmup,
+		Critical:             o.critical,
+		IgnoreDeadlines:      o.ignoreDeadlines,
+		DeadlineAdmission:    o.deadlines,
+		LatencySampling:      o.latencySampling,
+		ApdexTarget:          o.apdexTarget,
+		ApdexThreshold:       o.apdexThreshold,
+		SuccessBatchSize:     o.batchSize,
+		SuccessBatchInterval: o.batchInterval,
+		Labels:               maps.Clone(o.labels),
+		ObserverQueueSize:    o.observerQueue,
+		SynchronousObservers: o.syncObservers,
+	}
+
+	for _, c := range o.conditions {
+		s.TripConditions = append(s.TripConditions, c.name)
+	}
+
+	hooks := []struct {
+		name string
+		set  bool
+	}{
+		{"onStateChange", o.onStateChange != nil},
+		{"onTransition", o.onTransition != nil},
+		{"onAbandoned", o.onAbandoned != nil},
+		{"notifier", len(o.notifiers) > 0},
+		{"eventSink", len(o.sinks) > 0},
+		{"logger", o.logger != nil},
+		{"rateLimit", o.rateLimiter != nil},
+		{"concurrencyLimit", o.concurrencyLimit != nil},
+		{"store", o.store != nil},
+		{"sharedCounts", o.sharedCounts != nil},
+		{"externalSignal", o.signals != nil},
+		{"chaos", o.chaos != nil},
+		{"schedule", o.schedule != nil},
+		{"errorBudget", o.errorBudget != nil},
+		{"degraded", o.degraded != nil},
+	}
+
+	for _, h := range hooks {
+		if h.set {
+			s.Hooks = append(s.Hooks, h.name)
+		}
+	}
+
+	return s
+}
@@ -0,0 +1,80 @@
+package circuitbreaker
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatus(t *testing.T) {
+	c := &testClock{
+		now: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	b, err := New(WithClock(c), WithName("test"), WithTimeout(time.Minute), WithWindow(time.Minute))
+	require.NoError(t, err)
+
+	cb, err := b.Allow()
+	require.NoError(t, err)
+	cb(true)
+
+	b.Trip()
+
+	c.advance(20 * time.Second)
+
+	s := b.Status()
+	require.Equal(t, "test", s.Name)
+	require.Equal(t, StateOpen, s.State)
+	require.Equal(t, 40*time.Second, s.RetryAfter)
+	require.Equal(t, uint64(1), s.Counts.TotalSuccesses)
+
+	data, err := json.Marshal(s)
+	require.NoError(t, err)
+
+	require.JSONEq(t, `{
+		"name": "test",
+		"state": "open",
+		"forced": false,
+		"lastStateChange": "2021-01-02T03:04:05Z",
+		"retryAfter": "40s",
+		"counts": {
+			"requests": 1,
+			"totalSuccesses": 1,
+			"totalFailures": 0,
+			"consecutiveSuccesses": 1,
+			"consecutiveFailures": 0
+		},
+		"options": {
+			"window": "1m0s",
+			"timeout": "1m0s",
+			"maxRequests": 1
+		}
+	}`, string(data))
+}
+
+func TestOptions(t *testing.T) {
+	b, err := New(
+		WithName("test"),
+		WithOnTransition(func(Transition) {}),
+		WithTripCondition("failures", func(Counts) bool { return false }),
+		WithRateLimit(10, 1),
+	)
+	require.NoError(t, err)
+
+	require.NoError(t, b.UpdateOptions(WithTimeout(time.Minute)))
+
+	require.Equal(t, OptionsSnapshot{
+		Name:              "test",
+		Window:            time.Second,
+		Timeout:           time.Minute,
+		MaxRequests:       1,
+		HistorySize:       10,
+		StoreInterval:     10 * time.Second,
+		Shards:            1,
+		ObserverQueueSize: DefaultObserverQueueSize,
+		TripConditions:    []string{"readyToTrip", "failures"},
+		Hooks:             []string{"onTransition", "rateLimit"},
+	}, b.Options())
+}
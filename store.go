@@ -0,0 +1,115 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileStore is a Store that saves each snapshot to a file in a directory.
+// It watches for snapshots saved by other processes by polling the files.
+type FileStore struct {
+	dir          string
+	pollInterval time.Duration
+}
+
+var _ Store = &FileStore{}
+
+// NewFileStore creates a FileStore that saves snapshots in dir, which must exist.
+// Watch checks for changes at the poll interval, which defaults to one second.
+func NewFileStore(dir string, pollInterval time.Duration) *FileStore {
+	if pollInterval <= 0 {
+		pollInterval = time.Second
+	}
+
+	return &FileStore{
+		dir:          dir,
+		pollInterval: pollInterval,
+	}
+}
+
+func (f *FileStore) path(name string) string {
+	return filepath.Join(f.dir, url.PathEscape(name)+".json")
+}
+
+// Load implements Store.
+func (f *FileStore) Load(_ context.Context, name string) ([]byte, error) {
+	data, err := os.ReadFile(f.path(name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	return data, err
+}
+
+// Save implements Store. The file is replaced atomically.
+func (f *FileStore) Save(_ context.Context, name string, data []byte) error {
+	tmp, err := os.CreateTemp(f.dir, ".snapshot-*")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), f.path(name))
+}
+
+// Watch implements Store.
+func (f *FileStore) Watch(ctx context.Context, name string) (<-chan []byte, error) {
+	path := f.path(name)
+
+	var last time.Time
+	if info, err := os.Stat(path); err == nil {
+		last = info.ModTime()
+	}
+
+	updates := make(chan []byte)
+
+	go func() {
+		defer close(updates)
+
+		ticker := time.NewTicker(f.pollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			info, err := os.Stat(path)
+			if err != nil || !info.ModTime().After(last) {
+				continue
+			}
+
+			last = info.ModTime()
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				continue
+			}
+
+			select {
+			case updates <- data:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return updates, nil
+}
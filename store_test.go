@@ -0,0 +1,76 @@
+package circuitbreaker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStore(t *testing.T) {
+	f := NewFileStore(t.TempDir(), time.Millisecond)
+
+	ctx := context.Background()
+
+	data, err := f.Load(ctx, "group/key")
+	require.NoError(t, err)
+	require.Nil(t, data)
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	updates, err := f.Watch(watchCtx, "group/key")
+	require.NoError(t, err)
+
+	// modification times may be coarse
+	time.Sleep(10 * time.Millisecond)
+
+	require.NoError(t, f.Save(ctx, "group/key", []byte("snapshot")))
+
+	data, err = f.Load(ctx, "group/key")
+	require.NoError(t, err)
+	require.Equal(t, []byte("snapshot"), data)
+
+	select {
+	case data := <-updates:
+		require.Equal(t, []byte("snapshot"), data)
+	case <-time.After(time.Second):
+		t.Fatal("no update")
+	}
+
+	cancel()
+
+	for range updates {
+	}
+}
+
+func TestFileStoreShared(t *testing.T) {
+	dir := t.TempDir()
+
+	newBreaker := func() *Breaker {
+		b, err := New(
+			WithName("shared"),
+			WithStore(NewFileStore(dir, time.Millisecond), time.Hour),
+			WithTimeout(time.Minute),
+		)
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			_ = b.Close()
+		})
+
+		return b
+	}
+
+	first := newBreaker()
+	second := newBreaker()
+
+	time.Sleep(10 * time.Millisecond)
+
+	first.Trip()
+
+	require.Eventually(t, func() bool {
+		return second.State() == StateOpen
+	}, 5*time.Second, time.Millisecond)
+}
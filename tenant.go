@@ -0,0 +1,87 @@
+package circuitbreaker
+
+import "context"
+
+// Tenants admits requests of many tenants through a shared Breaker and a Breaker per tenant, so a tenant
+// whose requests fail trips only its own Breaker, while the shared Breaker still trips when requests of all
+// tenants fail. Outcomes are recorded by both Breakers, so the shared Breaker's trip conditions should allow
+// for one tenant failing until its own Breaker trips, such as by requiring a higher failure rate.
+type Tenants[K comparable] struct {
+	shared  *Breaker
+	tenants *Group[K]
+}
+
+// NewTenants creates Tenants with the shared Breaker. The Breaker for each tenant is created on first use
+// with options, as in NewGroup.
+func NewTenants[K comparable](shared *Breaker, options ...Option) (*Tenants[K], error) {
+	tenants, err := NewGroup[K](options...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Tenants[K]{
+		shared:  shared,
+		tenants: tenants,
+	}, nil
+}
+
+// Shared returns the shared Breaker.
+func (t *Tenants[K]) Shared() *Breaker {
+	return t.shared
+}
+
+// Tenant returns the Breaker of tenant, creating it if needed.
+func (t *Tenants[K]) Tenant(tenant K) *Breaker {
+	return t.tenants.Get(tenant)
+}
+
+// Allow is like Breaker.Allow for a request of tenant. The request must be allowed by the tenant's
+// Breaker and then by the shared Breaker, and the error from the Breaker that does not allow it is returned.
+func (t *Tenants[K]) Allow(tenant K) (func(bool), error) {
+	done, err := t.AllowOutcome(tenant)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(success bool) {
+		if success {
+			done(OutcomeSuccess)
+		} else {
+			done(OutcomeFailure)
+		}
+	}, nil
+}
+
+// AllowOutcome is like Allow, but the returned callback records an Outcome.
+func (t *Tenants[K]) AllowOutcome(tenant K) (func(Outcome), error) {
+	tenantDone, err := t.tenants.Get(tenant).AllowOutcome()
+	if err != nil {
+		return nil, err
+	}
+
+	sharedDone, err := t.shared.AllowOutcome()
+	if err != nil {
+		// the request was not made, so it is neither a success nor a failure of the tenant
+		tenantDone(OutcomeIgnored)
+		return nil, err
+	}
+
+	return func(o Outcome) {
+		tenantDone(o)
+		sharedDone(o)
+	}, nil
+}
+
+// Execute is like Breaker.Execute for a request of tenant.
+func (t *Tenants[K]) Execute(ctx context.Context, tenant K, fn func(ctx context.Context) error) error {
+	done, err := t.AllowOutcome(tenant)
+	if err != nil {
+		return err
+	}
+
+	err = fn(ctx)
+
+	done(outcomeOf(ctx, err))
+
+	return err
+}
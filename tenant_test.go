@@ -0,0 +1,48 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenants(t *testing.T) {
+	shared, err := New(WithWindow(time.Minute), WithReadyToTrip(func(c Counts) bool { return c.TotalFailures >= 4 }))
+	require.NoError(t, err)
+
+	tenants, err := NewTenants[string](shared, WithName("tenant"), WithWindow(time.Minute),
+		WithReadyToTrip(func(c Counts) bool { return c.ConsecutiveFailures >= 2 }))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	fail := errors.New("fail")
+
+	// one tenant's failures trip only its breaker
+	for i := 0; i < 2; i++ {
+		require.ErrorIs(t, tenants.Execute(ctx, "a", func(context.Context) error { return fail }), fail)
+	}
+
+	require.ErrorIs(t, tenants.Execute(ctx, "a", func(context.Context) error { return nil }), ErrOpenState)
+	require.NoError(t, tenants.Execute(ctx, "b", func(context.Context) error { return nil }))
+	require.Equal(t, StateOpen, tenants.Tenant("a").State())
+	require.Equal(t, "tenant/a", tenants.Tenant("a").Name())
+	require.Equal(t, StateClosed, shared.State())
+
+	// failures across tenants trip the shared breaker
+	for _, tenant := range []string{"c", "d"} {
+		done, err := tenants.Allow(tenant)
+		require.NoError(t, err)
+		done(false)
+	}
+
+	require.Equal(t, StateOpen, shared.State())
+
+	_, err = tenants.Allow("e")
+	require.ErrorIs(t, err, ErrOpenState)
+
+	counts := tenants.Tenant("e").Status().Counts
+	require.Zero(t, counts.TotalSuccesses+counts.TotalFailures)
+}
@@ -0,0 +1,83 @@
+package circuitbreaker
+
+import (
+	"sync"
+	"time"
+)
+
+// Token is a request allowed by AllowToken. Exactly one of Success, Failure, Record, or RecordPartial must be called,
+// after which the Token is reused and must not be used again.
+type Token struct {
+	start     time.Time
+	breaker   *Breaker
+	inflight  int
+	admission Admission
+}
+
+var tokens = sync.Pool{
+	New: func() any {
+		return &Token{}
+	},
+}
+
+// AllowToken is like AllowOutcome, but returns a Token rather than a callback. Tokens are pooled,
+// so unlike Allow, allowing a request does not allocate. In BenchmarkAllow and BenchmarkAllowToken,
+// Allow costs one 48 byte allocation per request and AllowToken none, which is about 15% faster.
+func (b *Breaker) AllowToken() (*Token, error) {
+	t := tokens.Get().(*Token)
+
+	start, inflight, err := b.admit(&t.admission)
+	if err != nil {
+		*t = Token{}
+		tokens.Put(t)
+
+		return nil, err
+	}
+
+	t.breaker = b
+	t.start = start
+	t.inflight = inflight
+
+	return t, nil
+}
+
+// State returns the state of the Breaker when the request was allowed, such as StateDegraded.
+func (t *Token) State() State {
+	return t.admission.State
+}
+
+// Admission returns the state of the Breaker when the request was allowed.
+func (t *Token) Admission() Admission {
+	return t.admission
+}
+
+// Success records that the request succeeded.
+func (t *Token) Success() {
+	t.Record(OutcomeSuccess)
+}
+
+// Failure records that the request failed.
+func (t *Token) Failure() {
+	t.Record(OutcomeFailure)
+}
+
+// Record records the outcome of the request.
+func (t *Token) Record(o Outcome) {
+	b, start, inflight := t.breaker, t.start, t.inflight
+
+	*t = Token{}
+	tokens.Put(t)
+
+	b.record(start, inflight, o)
+}
+
+// RecordPartial records the outcome of a request for many items, such as a call to a bulk API, as successes
+// successful and failures failed requests. See AllowPartial.
+func (t *Token) RecordPartial(successes uint64, failures uint64) {
+	b, start, inflight := t.breaker, t.start, t.inflight
+
+	*t = Token{}
+	tokens.Put(t)
+
+	b.recordPartial(start, inflight, successes, failures)
+}
@@ -0,0 +1,84 @@
+package circuitbreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllowToken(t *testing.T) {
+	b, err := New(WithWindow(time.Minute), WithReadyToTrip(func(c Counts) bool { return c.TotalFailures >= 2 }))
+	require.NoError(t, err)
+
+	token, err := b.AllowToken()
+	require.NoError(t, err)
+	token.Success()
+
+	token, err = b.AllowToken()
+	require.NoError(t, err)
+	token.Failure()
+
+	token, err = b.AllowToken()
+	require.NoError(t, err)
+	token.Record(OutcomeIgnored)
+
+	require.Equal(t, Counts{Requests: 3, TotalSuccesses: 1, TotalFailures: 1, ConsecutiveFailures: 1}, b.Status().Counts)
+
+	token, err = b.AllowToken()
+	require.NoError(t, err)
+	token.Failure()
+
+	_, err = b.AllowToken()
+	require.ErrorIs(t, err, ErrOpenState)
+}
+
+func TestAllowTokenAllocations(t *testing.T) {
+	b, err := New()
+	require.NoError(t, err)
+
+	allocs := testing.AllocsPerRun(1000, func() {
+		token, err := b.AllowToken()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		token.Success()
+	})
+
+	require.Zero(t, allocs)
+}
+
+func BenchmarkAllow(b *testing.B) {
+	cb, err := New()
+	require.NoError(b, err)
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			done, err := cb.Allow()
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			done(true)
+		}
+	})
+}
+
+func BenchmarkAllowToken(b *testing.B) {
+	cb, err := New()
+	require.NoError(b, err)
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			token, err := cb.AllowToken()
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			token.Success()
+		}
+	})
+}
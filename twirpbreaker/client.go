@@ -0,0 +1,109 @@
+package twirpbreaker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"path"
+
+	"github.com/twitchtv/twirp"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+// HTTPClient is the interface used by generated Twirp clients to send requests.
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// maxErrorBody is the size of an error response body read to find its Twirp error code.
+const maxErrorBody = 64 << 10
+
+// Client is an HTTPClient that runs each request through the breaker from a Group keyed by
+// "Service/Method", such as "example.Haberdasher/MakeHat". When the breaker does not allow a request,
+// the error from the breaker, such as a *circuitbreaker.OpenStateError, is returned, which the generated
+// client returns as an error wrapping it.
+type Client struct {
+	next    HTTPClient
+	group   *circuitbreaker.Group[string]
+	options options
+}
+
+var _ HTTPClient = &Client{}
+
+// NewClient creates a Client that sends requests using next, or http.DefaultClient if next is nil.
+// Pass it to a generated client constructor, such as NewHaberdasherProtobufClient.
+func NewClient(next HTTPClient, g *circuitbreaker.Group[string], opts ...Option) *Client {
+	if next == nil {
+		next = http.DefaultClient
+	}
+
+	return &Client{
+		next:    next,
+		group:   g,
+		options: newOptions(opts),
+	}
+}
+
+// Do implements HTTPClient.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	// Twirp routes are "<prefix>/<package>.<Service>/<Method>"
+	service, method := path.Split(req.URL.Path)
+
+	done, err := c.group.Get(path.Base(service) + "/" + method).AllowOutcome()
+	if err != nil {
+		if req.Body != nil {
+			_ = req.Body.Close()
+		}
+
+		return nil, err
+	}
+
+	resp, err := c.next.Do(req)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			done(circuitbreaker.OutcomeIgnored)
+		} else {
+			done(circuitbreaker.OutcomeFailure)
+		}
+
+		return nil, err
+	}
+
+	done(c.options.classifier(errorCode(resp)))
+
+	return resp, nil
+}
+
+// errorCode returns the Twirp error code of a response. The body of an error response is
+// read and replaced with the same content.
+func errorCode(resp *http.Response) twirp.ErrorCode {
+	if resp.StatusCode == http.StatusOK {
+		return twirp.NoError
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+
+	var twerr struct {
+		Code string `json:"code"`
+	}
+
+	if err == nil && json.Unmarshal(body, &twerr) == nil && twirp.IsValidErrorCode(twirp.ErrorCode(twerr.Code)) {
+		return twirp.ErrorCode(twerr.Code)
+	}
+
+	// not a Twirp error, such as a response from a proxy
+	if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+		return twirp.Unavailable
+	}
+
+	// other statuses from an intermediary are caused by the request
+	return twirp.Malformed
+}
@@ -0,0 +1,60 @@
+package twirpbreaker
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+func TestClient(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/twirp/example.Haberdasher/MakeHat":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"code":"invalid_argument","msg":"bad size"}`))
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"code":"unavailable","msg":"down"}`))
+		}
+	}))
+	defer svr.Close()
+
+	g, err := circuitbreaker.NewGroup[string](
+		circuitbreaker.WithWindow(time.Minute),
+		circuitbreaker.WithReadyToTrip(circuitbreaker.TripOnConsecutiveFailures(1)),
+	)
+	require.NoError(t, err)
+
+	c := NewClient(nil, g)
+
+	do := func(method string) (*http.Response, error) {
+		req, err := http.NewRequest(http.MethodPost, svr.URL+"/twirp/example.Haberdasher/"+method, nil)
+		require.NoError(t, err)
+
+		return c.Do(req)
+	}
+
+	resp, err := do("MakeHat")
+	require.NoError(t, err)
+
+	// the body is still readable
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Contains(t, string(body), "invalid_argument")
+	require.Equal(t, circuitbreaker.StateClosed, g.Get("example.Haberdasher/MakeHat").State())
+
+	resp, err = do("ListHats")
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, circuitbreaker.StateOpen, g.Get("example.Haberdasher/ListHats").State())
+
+	_, err = do("ListHats")
+	require.ErrorIs(t, err, circuitbreaker.ErrOpenState)
+}
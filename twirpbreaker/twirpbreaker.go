@@ -0,0 +1,105 @@
+// Package twirpbreaker provides Twirp server hooks and a client HTTPClient that run requests through
+// circuit breakers, classifying outcomes using Twirp error codes.
+package twirpbreaker
+
+import (
+	"context"
+
+	"github.com/twitchtv/twirp"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+// Classifier returns the outcome of a request that completed with code. Successful requests have twirp.NoError.
+type Classifier func(code twirp.ErrorCode) circuitbreaker.Outcome
+
+// DefaultClassifier classifies codes that indicate an unhealthy service as failures: Unavailable,
+// DeadlineExceeded, ResourceExhausted, Internal, Unknown, and DataLoss. Other codes, such as
+// InvalidArgument and NotFound, are caused by the request and are ignored, as is Canceled.
+func DefaultClassifier(code twirp.ErrorCode) circuitbreaker.Outcome {
+	switch code {
+	case twirp.NoError:
+		return circuitbreaker.OutcomeSuccess
+	case twirp.Unavailable,
+		twirp.DeadlineExceeded,
+		twirp.ResourceExhausted,
+		twirp.Internal,
+		twirp.Unknown,
+		twirp.DataLoss:
+		return circuitbreaker.OutcomeFailure
+	default:
+		return circuitbreaker.OutcomeIgnored
+	}
+}
+
+type options struct {
+	classifier Classifier
+}
+
+// Option sets twirpbreaker options.
+type Option func(*options)
+
+// WithClassifier sets the classifier used to record the outcome of requests.
+// Default is DefaultClassifier.
+func WithClassifier(c Classifier) Option {
+	return func(o *options) {
+		o.classifier = c
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		classifier: DefaultClassifier,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+type requestKey struct{}
+
+// request is a request allowed by ServerHooks.
+type request struct {
+	done func(circuitbreaker.Outcome)
+	code twirp.ErrorCode
+}
+
+// ServerHooks returns hooks that run each request through the breaker from g keyed by
+// "Service/Method", such as "example.Haberdasher/MakeHat". When the breaker does not allow a request,
+// it fails with a twirp.Unavailable error. Use twirp.ChainHooks to combine them with other hooks.
+func ServerHooks(g *circuitbreaker.Group[string], opts ...Option) *twirp.ServerHooks {
+	o := newOptions(opts)
+
+	return &twirp.ServerHooks{
+		RequestRouted: func(ctx context.Context) (context.Context, error) {
+			service, _ := twirp.ServiceName(ctx)
+			method, _ := twirp.MethodName(ctx)
+
+			if pkg, ok := twirp.PackageName(ctx); ok && pkg != "" {
+				service = pkg + "." + service
+			}
+
+			done, err := g.Get(service + "/" + method).AllowOutcome()
+			if err != nil {
+				return ctx, twirp.WrapError(twirp.NewError(twirp.Unavailable, err.Error()), err)
+			}
+
+			return context.WithValue(ctx, requestKey{}, &request{done: done}), nil
+		},
+		Error: func(ctx context.Context, err twirp.Error) context.Context {
+			if r, ok := ctx.Value(requestKey{}).(*request); ok {
+				r.code = err.Code()
+			}
+
+			return ctx
+		},
+		ResponseSent: func(ctx context.Context) {
+			if r, ok := ctx.Value(requestKey{}).(*request); ok {
+				r.done(o.classifier(r.code))
+			}
+		},
+	}
+}
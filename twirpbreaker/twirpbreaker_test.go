@@ -0,0 +1,65 @@
+package twirpbreaker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/twitchtv/twirp"
+	"github.com/twitchtv/twirp/ctxsetters"
+
+	"github.com/bakins/circuitbreaker"
+)
+
+func TestDefaultClassifier(t *testing.T) {
+	require.Equal(t, circuitbreaker.OutcomeSuccess, DefaultClassifier(twirp.NoError))
+	require.Equal(t, circuitbreaker.OutcomeFailure, DefaultClassifier(twirp.Unavailable))
+	require.Equal(t, circuitbreaker.OutcomeFailure, DefaultClassifier(twirp.DeadlineExceeded))
+	require.Equal(t, circuitbreaker.OutcomeIgnored, DefaultClassifier(twirp.InvalidArgument))
+	require.Equal(t, circuitbreaker.OutcomeIgnored, DefaultClassifier(twirp.Canceled))
+}
+
+func TestServerHooks(t *testing.T) {
+	g, err := circuitbreaker.NewGroup[string](
+		circuitbreaker.WithWindow(time.Minute),
+		circuitbreaker.WithReadyToTrip(circuitbreaker.TripOnConsecutiveFailures(1)),
+	)
+	require.NoError(t, err)
+
+	hooks := ServerHooks(g)
+
+	serve := func(code twirp.ErrorCode) error {
+		ctx := ctxsetters.WithPackageName(context.Background(), "example")
+		ctx = ctxsetters.WithServiceName(ctx, "Haberdasher")
+		ctx = ctxsetters.WithMethodName(ctx, "MakeHat")
+
+		ctx, err := hooks.RequestRouted(ctx)
+		if err != nil {
+			return err
+		}
+
+		if code != twirp.NoError {
+			ctx = hooks.Error(ctx, twirp.NewError(code, "error"))
+		}
+
+		hooks.ResponseSent(ctx)
+
+		return nil
+	}
+
+	b := g.Get("example.Haberdasher/MakeHat")
+
+	require.NoError(t, serve(twirp.InvalidArgument))
+	require.Equal(t, circuitbreaker.StateClosed, b.State())
+
+	require.NoError(t, serve(twirp.Unavailable))
+	require.Equal(t, circuitbreaker.StateOpen, b.State())
+
+	err = serve(twirp.NoError)
+
+	var twerr twirp.Error
+	require.ErrorAs(t, err, &twerr)
+	require.Equal(t, twirp.Unavailable, twerr.Code())
+	require.ErrorIs(t, err, circuitbreaker.ErrOpenState)
+}
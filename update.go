@@ -0,0 +1,101 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// ErrNotUpdatable is returned by UpdateOptions when an option cannot be changed after the Breaker is created.
+var ErrNotUpdatable = errors.New("circuit breaker option cannot be updated")
+
+// UpdateOptions changes the options of the Breaker without losing its state or counts.
+// Only WithReadyToTrip, WithTripCondition, WithDetector, WithTimeout, WithWindow, and WithMaxRequests may be used;
+// other options return ErrNotUpdatable and invalid values return an error wrapping ErrInvalidOption, and nothing is changed.
+// WithTripCondition and WithDetector add to the existing conditions. A new timeout applies from the next transition.
+// When the window shrinks, the oldest counts are dropped.
+func (b *Breaker) UpdateOptions(options ...Option) error {
+	var changes Options
+
+	for _, o := range options {
+		o(&changes)
+	}
+
+	if !changes.updatable() {
+		return ErrNotUpdatable
+	}
+
+	if err := changes.Validate(); err != nil {
+		return err
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	opts := b.options
+	// copy, as the conditions are read without the lock
+	opts.conditions = append([]tripCondition(nil), b.options.conditions...)
+
+	if changes.readyToTrip != nil {
+		opts.readyToTrip = changes.readyToTrip
+		opts.conditions[0] = tripCondition{name: "readyToTrip", readyToTrip: changes.readyToTrip}
+	}
+
+	opts.conditions = append(opts.conditions, changes.conditions...)
+	opts.setClocks(changes.conditions)
+
+	if changes.timeout > 0 {
+		opts.timeout = changes.timeout
+	}
+
+	if changes.window > 0 {
+		opts.window = changes.window
+	}
+
+	if changes.maxRequests > 0 {
+		opts.maxRequests = changes.maxRequests
+	}
+
+	if opts.window != b.options.window {
+		numBuckets := int(opts.window / time.Second)
+
+		b.buckets.Resize(b.options.clock.Now(), numBuckets)
+		b.halfOpenRequests.Resize(numBuckets)
+
+		if b.apdex != nil {
+			b.apdex.counts.Resize(b.options.clock.Now(), numBuckets)
+		}
+	}
+
+	// assign only the changed fields, as other fields are read without the lock
+	b.options.readyToTrip = opts.readyToTrip
+	b.options.conditions = opts.conditions
+	b.options.timeout = opts.timeout
+	b.options.window = opts.window
+	b.options.maxRequests = opts.maxRequests
+
+	if b.options.logger != nil {
+		b.options.logger.LogAttrs(context.Background(), slog.LevelInfo, "circuit breaker options updated",
+			slog.String("breaker", b.options.name),
+			slog.Duration("window", b.options.window),
+			slog.Duration("timeout", b.options.timeout),
+			slog.Uint64("maxRequests", b.options.maxRequests),
+		)
+	}
+
+	if b.events.wants(EventConfigChange) {
+		b.events.emit(Event{Kind: EventConfigChange, Time: b.options.clock.Now(), State: b.loadState()})
+	}
+
+	return nil
+}
+
+// updatable reports whether only options that UpdateOptions can change are set.
+func (o *Options) updatable() bool {
+	return o.onStateChange == nil && o.onTransition == nil && o.onAbandoned == nil && len(o.notifiers) == 0 && len(o.sinks) == 0 && o.observerQueue == 0 && !o.syncObservers &&
+		o.logger == nil && o.rateLimiter == nil && o.concurrencyLimit == nil && o.chaos == nil && o.schedule == nil && o.errorBudget == nil && o.degraded == nil && o.store == nil &&
+		o.signals == nil && o.clock == nil && o.sharedCounts == nil && o.name == "" && o.historySize == 0 &&
+		o.callTimeout == 0 && o.hedgeDelay == 0 && o.storeInterval == 0 && o.shards == 0 && o.warmup == 0 &&
+		o.batchSize == 0 && o.batchInterval == 0 && !o.legacyState && !o.critical && !o.ignoreDeadlines && !o.deadlines && o.labels == nil && o.latencySampling == 0 && o.apdexTarget == 0 && o.apdexThreshold == 0
+}
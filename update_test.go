@@ -0,0 +1,49 @@
+package circuitbreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateOptions(t *testing.T) {
+	b, err := New(
+		WithWindow(10*time.Second),
+		WithReadyToTrip(func(Counts) bool { return false }),
+	)
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		record(t, b, false)
+	}
+
+	require.NoError(t, b.UpdateOptions(WithWindow(30*time.Second), WithTimeout(time.Minute), WithMaxRequests(4)))
+
+	status := b.Status()
+	require.Equal(t, StateClosed, status.State)
+	require.Equal(t, uint64(3), status.Counts.TotalFailures)
+	require.Equal(t, uint64(3), status.Counts.Requests)
+	require.Equal(t, StatusOptions{Window: 30 * time.Second, Timeout: time.Minute, MaxRequests: 4}, status.Options)
+
+	require.NoError(t, b.UpdateOptions(WithWindow(5*time.Second)))
+	require.Equal(t, uint64(3), b.Status().Counts.TotalFailures)
+
+	require.NoError(t, b.UpdateOptions(WithTripCondition("failures", func(c Counts) bool {
+		return c.TotalFailures > 3
+	})))
+
+	record(t, b, false)
+	require.Equal(t, StateOpen, b.State())
+
+	history := b.History()
+	require.Equal(t, "failures", history[len(history)-1].Condition)
+}
+
+func TestUpdateOptionsNotUpdatable(t *testing.T) {
+	b, err := New()
+	require.NoError(t, err)
+
+	require.ErrorIs(t, b.UpdateOptions(WithTimeout(time.Minute), WithName("test")), ErrNotUpdatable)
+	require.Equal(t, time.Second, b.Status().Options.Timeout)
+}
@@ -0,0 +1,108 @@
+package circuitbreaker
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// ErrInvalidOption is returned by New when an option has an invalid value.
+var ErrInvalidOption = errors.New("invalid circuit breaker option")
+
+// Validate returns an error wrapping ErrInvalidOption for each invalid value or combination of options.
+// Unset values are valid, as New uses the defaults.
+func (o *Options) Validate() error {
+	var errs []error
+
+	invalid := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%w: "+format, append([]any{ErrInvalidOption}, args...)...))
+	}
+
+	if o.window != 0 && (o.window < time.Second || o.window%time.Second != 0) {
+		invalid("window must be a whole number of seconds: %s", o.window)
+	}
+
+	if o.timeout != 0 && o.timeout < time.Second {
+		invalid("timeout must be at least one second: %s", o.timeout)
+	}
+
+	if o.historySize < 0 {
+		invalid("history size must not be negative: %d", o.historySize)
+	}
+
+	if o.callTimeout < 0 {
+		invalid("call timeout must not be negative: %s", o.callTimeout)
+	}
+
+	if o.hedgeDelay < 0 {
+		invalid("hedge delay must not be negative: %s", o.hedgeDelay)
+	}
+
+	if o.callTimeout > 0 && o.hedgeDelay >= o.callTimeout {
+		invalid("hedge delay %s must be less than the call timeout %s", o.hedgeDelay, o.callTimeout)
+	}
+
+	if o.warmup < 0 {
+		invalid("warmup must not be negative: %s", o.warmup)
+	}
+
+	if o.latencySampling < 0 {
+		invalid("latency sampling must not be negative: %d", o.latencySampling)
+	}
+
+	if o.deadlines && o.latencySampling == 0 {
+		invalid("deadline admission requires latency sampling")
+	}
+
+	if o.apdexTarget < 0 || o.apdexTarget > 1 {
+		invalid("apdex target must be between 0 and 1: %v", o.apdexTarget)
+	}
+
+	if o.apdexThreshold < 0 {
+		invalid("apdex threshold must not be negative: %s", o.apdexThreshold)
+	}
+
+	if o.observerQueue < 0 {
+		invalid("observer queue size must not be negative: %d", o.observerQueue)
+	}
+
+	if o.shards < 0 {
+		invalid("shards must not be negative: %d", o.shards)
+	}
+
+	if o.batchSize < 0 || o.batchInterval < 0 || (o.batchSize > 0) != (o.batchInterval > 0) {
+		invalid("success batch size %d and interval %s must both be positive", o.batchSize, o.batchInterval)
+	}
+
+	if o.storeInterval < 0 {
+		invalid("store interval must not be negative: %s", o.storeInterval)
+	}
+
+	if o.rateLimiter != nil && o.rateLimiter.Limit() != rate.Inf &&
+		(o.rateLimiter.Burst() <= 0 || o.rateLimiter.Limit() <= 0 || math.IsNaN(float64(o.rateLimiter.Limit()))) {
+		invalid("rate limit %v with burst %d rejects every request", o.rateLimiter.Limit(), o.rateLimiter.Burst())
+	}
+
+	if o.chaos != nil {
+		if o.chaos.RejectFraction < 0 || o.chaos.RejectFraction > 1 {
+			invalid("chaos reject fraction must be between 0 and 1: %v", o.chaos.RejectFraction)
+		}
+
+		if o.chaos.OpenFor < 0 || o.chaos.OpenEvery < 0 || o.chaos.OpenFor > o.chaos.OpenEvery {
+			invalid("chaos open windows of %s every %s are invalid", o.chaos.OpenFor, o.chaos.OpenEvery)
+		}
+	}
+
+	if o.schedule != nil && o.schedule.err != nil {
+		invalid("%v", o.schedule.err)
+	}
+
+	if (o.store != nil || o.sharedCounts != nil) && o.name == "" {
+		errs = append(errs, ErrNoName)
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,53 @@
+package circuitbreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewInvalidOptions(t *testing.T) {
+	tests := map[string][]Option{
+		"window":     {WithWindow(500 * time.Millisecond)},
+		"partial":    {WithWindow(1500 * time.Millisecond)},
+		"timeout":    {WithTimeout(time.Millisecond)},
+		"history":    {WithHistorySize(-1)},
+		"hedge":      {WithCallTimeout(time.Second), WithHedgeDelay(2 * time.Second)},
+		"rate limit": {WithRateLimit(10, 0)},
+		"shared":     {WithSharedCounts(&fixedCounts{})},
+		"negative":   {WithCallTimeout(-time.Second)},
+		"shards":     {WithShards(-1)},
+		"interval":   {WithStore(&memoryStore{}, -time.Second), WithName("test")},
+	}
+
+	for name, options := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := New(options...)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestOptionsValidate(t *testing.T) {
+	var o Options
+
+	for _, option := range []Option{WithWindow(time.Millisecond), WithTimeout(time.Millisecond)} {
+		option(&o)
+	}
+
+	err := o.Validate()
+	require.ErrorIs(t, err, ErrInvalidOption)
+	require.ErrorContains(t, err, "window")
+	require.ErrorContains(t, err, "timeout")
+
+	require.NoError(t, (&Options{}).Validate())
+}
+
+func TestUpdateOptionsInvalid(t *testing.T) {
+	b, err := New(WithTimeout(time.Minute))
+	require.NoError(t, err)
+
+	require.ErrorIs(t, b.UpdateOptions(WithTimeout(time.Millisecond)), ErrInvalidOption)
+	require.Equal(t, time.Minute, b.Status().Options.Timeout)
+}
@@ -0,0 +1,250 @@
+package circuitbreaker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Notifier is notified whenever the state of a Breaker changes.
+// Notify is called while the Breaker is locked and must not block.
+type Notifier interface {
+	Notify(TransitionEvent)
+}
+
+// WithNotifier adds a Notifier to the Breaker. It may be used more than once.
+// There is no default.
+func WithNotifier(n Notifier) Option {
+	return func(o *Options) {
+		o.notifiers = append(o.notifiers, n)
+	}
+}
+
+// WebhookNotifier is a Notifier that POSTs transitions as a JSON array of
+// TransitionEvent to a URL. Transitions are batched and delivered in the background.
+type WebhookNotifier struct {
+	client   *http.Client
+	events   chan TransitionEvent
+	done     chan struct{}
+	url      string
+	options  webhookOptions
+	closeErr error
+	once     sync.Once
+}
+
+type webhookOptions struct {
+	client        *http.Client
+	header        http.Header
+	batchSize     int
+	queueSize     int
+	retries       int
+	backoff       time.Duration
+	flushInterval time.Duration
+}
+
+// WebhookOption sets WebhookNotifier options.
+type WebhookOption func(*webhookOptions)
+
+// WithWebhookClient sets the HTTP client used to deliver events.
+// Default is a client with a 10 second timeout.
+func WithWebhookClient(client *http.Client) WebhookOption {
+	return func(o *webhookOptions) {
+		o.client = client
+	}
+}
+
+// WithWebhookHeader sets a header, such as Authorization, on each delivery.
+func WithWebhookHeader(key string, value string) WebhookOption {
+	return func(o *webhookOptions) {
+		o.header.Set(key, value)
+	}
+}
+
+// WithWebhookBatch sets the maximum number of events per delivery and the maximum time
+// an event waits before delivery.
+// Default is 10 events and 1 second.
+func WithWebhookBatch(size int, interval time.Duration) WebhookOption {
+	return func(o *webhookOptions) {
+		o.batchSize = size
+		o.flushInterval = interval
+	}
+}
+
+// WithWebhookRetries sets the number of times a failed delivery is retried and the
+// initial delay between retries. The delay doubles after each retry.
+// Default is 3 retries and 500 milliseconds.
+func WithWebhookRetries(retries int, backoff time.Duration) WebhookOption {
+	return func(o *webhookOptions) {
+		o.retries = retries
+		o.backoff = backoff
+	}
+}
+
+// WithWebhookQueueSize sets the number of events that may be waiting for delivery.
+// Events are dropped when the queue is full.
+// Default is 1000.
+func WithWebhookQueueSize(size int) WebhookOption {
+	return func(o *webhookOptions) {
+		o.queueSize = size
+	}
+}
+
+// NewWebhookNotifier creates a WebhookNotifier that delivers to url.
+// Call Close to deliver any pending events and stop the notifier.
+func NewWebhookNotifier(url string, options ...WebhookOption) *WebhookNotifier {
+	opts := webhookOptions{
+		header: make(http.Header),
+	}
+
+	for _, o := range options {
+		o(&opts)
+	}
+
+	if opts.client == nil {
+		opts.client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	if opts.batchSize <= 0 {
+		opts.batchSize = 10
+	}
+
+	if opts.flushInterval <= 0 {
+		opts.flushInterval = time.Second
+	}
+
+	if opts.retries < 0 {
+		opts.retries = 0
+	}
+
+	if opts.backoff <= 0 {
+		opts.backoff = 500 * time.Millisecond
+	}
+
+	if opts.queueSize <= 0 {
+		opts.queueSize = 1000
+	}
+
+	w := &WebhookNotifier{
+		url:     url,
+		client:  opts.client,
+		options: opts,
+		events:  make(chan TransitionEvent, opts.queueSize),
+		done:    make(chan struct{}),
+	}
+
+	go w.run()
+
+	return w
+}
+
+// Notify queues an event for delivery. It never blocks; the event is dropped if the queue is full.
+func (w *WebhookNotifier) Notify(e TransitionEvent) {
+	select {
+	case w.events <- e:
+	default:
+	}
+}
+
+// Close delivers any pending events and stops the notifier. It returns the error
+// from the last delivery, if any.
+// Notify must not be called after Close.
+func (w *WebhookNotifier) Close() error {
+	w.once.Do(func() {
+		close(w.events)
+		<-w.done
+	})
+
+	return w.closeErr
+}
+
+func (w *WebhookNotifier) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.options.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]TransitionEvent, 0, w.options.batchSize)
+
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+
+		w.closeErr = w.deliver(batch)
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case e, ok := <-w.events:
+			if !ok {
+				flush()
+				return
+			}
+
+			batch = append(batch, e)
+			if len(batch) >= w.options.batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+func (w *WebhookNotifier) deliver(batch []TransitionEvent) error {
+	body, err := json.Marshal(batch)
+	if err != nil {
+		return err
+	}
+
+	backoff := w.options.backoff
+
+	for attempt := 0; ; attempt++ {
+		retry, err := w.post(body)
+		if err == nil {
+			return nil
+		}
+
+		if !retry || attempt >= w.options.retries {
+			return err
+		}
+
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// post delivers body once. It returns true if a failed delivery should be retried.
+func (w *WebhookNotifier) post(body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+
+	for k, v := range w.options.header {
+		req.Header[k] = v
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+
+	_ = resp.Body.Close()
+
+	switch {
+	case resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	default:
+		return false, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+}
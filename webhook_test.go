@@ -0,0 +1,74 @@
+package circuitbreaker
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookNotifier(t *testing.T) {
+	var (
+		lock     sync.Mutex
+		attempts int
+		received []map[string]interface{}
+	)
+
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		require.Equal(t, "secret", r.Header.Get("Authorization"))
+
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		var events []map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&events))
+
+		received = append(received, events...)
+	}))
+	defer svr.Close()
+
+	n := NewWebhookNotifier(svr.URL,
+		WithWebhookHeader("Authorization", "secret"),
+		WithWebhookBatch(10, time.Hour),
+		WithWebhookRetries(1, time.Millisecond),
+	)
+
+	b, err := New(WithName("test"), WithNotifier(n), WithSynchronousObservers())
+	require.NoError(t, err)
+
+	b.Trip()
+	b.Reset()
+
+	require.NoError(t, n.Close())
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	require.Equal(t, 2, attempts)
+	require.Len(t, received, 2)
+	require.Equal(t, "test", received[0]["name"])
+	require.Equal(t, "open", received[0]["transition"].(map[string]interface{})["to"])
+	require.Equal(t, "closed", received[1]["transition"].(map[string]interface{})["to"])
+}
+
+func TestWebhookNotifierError(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer svr.Close()
+
+	n := NewWebhookNotifier(svr.URL, WithWebhookBatch(1, time.Hour))
+	n.Notify(TransitionEvent{Name: "test"})
+
+	require.Error(t, n.Close())
+}
@@ -0,0 +1,50 @@
+package circuitbreaker
+
+import (
+	"time"
+
+	"github.com/bakins/circuitbreaker/internal/ring"
+)
+
+// window is a rolling window with a single counter, using the time from the clock.
+type window struct {
+	clock Clock
+	ring  *ring.Ring
+}
+
+func newWindow(clock Clock, numBuckets int, bucketDuration time.Duration) *window {
+	return &window{
+		clock: clock,
+		ring:  ring.New(numBuckets, 1, 1, bucketDuration),
+	}
+}
+
+// Add adds n to the current bucket.
+func (w *window) Add(n uint64) {
+	w.ring.Add(w.clock.Now(), 0, n)
+}
+
+// Sum returns the sum of the buckets in the window.
+func (w *window) Sum() uint64 {
+	return w.ring.Sum(w.clock.Now(), 0)
+}
+
+// TryAdd adds n if the sum of the window would not exceed limit, and reports whether it did.
+func (w *window) TryAdd(n uint64, limit uint64) bool {
+	return w.ring.TryAdd(w.clock.Now(), 0, n, limit)
+}
+
+// Reset clears the buckets.
+func (w *window) Reset() {
+	w.ring.Reset()
+}
+
+// Resize changes the number of buckets, keeping the most recent buckets.
+func (w *window) Resize(numBuckets int) {
+	w.ring.Resize(w.clock.Now(), numBuckets)
+}
+
+// Window returns the duration of the window.
+func (w *window) Window() time.Duration {
+	return w.ring.Window()
+}
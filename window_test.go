@@ -0,0 +1,38 @@
+package circuitbreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWindow(t *testing.T) {
+	c := &testClock{
+		now: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	w := newWindow(c, 3, time.Second)
+
+	w.Add(1)
+	w.Add(2)
+	require.Equal(t, uint64(3), w.Sum())
+
+	c.advance(time.Second)
+	w.Add(4)
+	require.Equal(t, uint64(7), w.Sum())
+
+	c.advance(2 * time.Second)
+	require.Equal(t, uint64(4), w.Sum())
+
+	// the bucket is reused
+	w.Add(8)
+	require.Equal(t, uint64(12), w.Sum())
+
+	c.advance(time.Hour)
+	require.Equal(t, uint64(0), w.Sum())
+
+	w.Add(1)
+	w.Reset()
+	require.Equal(t, uint64(0), w.Sum())
+}